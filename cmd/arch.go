@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// what the runtime reports when it runs, or tries to pull, an image built for another platform
+var archMismatchRegexp = regexp.MustCompile(`(?i)exec format error|no match for platform|no matching manifest`)
+
+// imagePlatforms is the cached result of looking up an image's platforms in its registry.
+type imagePlatforms struct {
+	platforms []imagePlatform
+	err       error
+}
+
+// getImagePlatforms looks up the platforms an image is available for, with the first of the given
+// pull secrets that has credentials for its registry, or anonymously if none has.  Lookups are cached for the lifetime of the
+// command, since a sweep usually finds the same images over and over.
+func (dp *podInspectCommand) getImagePlatforms(secrets []pullSecret, image string) ([]imagePlatform, error) {
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, credentials := newPullSecretRegistryClient(secrets, ref)
+	key := image + " " + credentials
+	if cached, ok := dp.imagePlatforms[key]; ok {
+		return cached.platforms, cached.err
+	}
+
+	platforms, err := rc.getImagePlatforms(context.Background(), ref)
+	if dp.imagePlatforms == nil {
+		dp.imagePlatforms = map[string]imagePlatforms{}
+	}
+	dp.imagePlatforms[key] = imagePlatforms{platforms: platforms, err: err}
+	return platforms, err
+}
+
+// getNodePlatform returns the node's OS and architecture, from its well-known labels or, failing
+// that, from what the kubelet reports.
+func getNodePlatform(node *v1.Node) imagePlatform {
+	platform := imagePlatform{
+		OS:           getFirstLabel(node, []string{v1.LabelOSStable, "beta.kubernetes.io/os"}),
+		Architecture: getFirstLabel(node, []string{v1.LabelArchStable, "beta.kubernetes.io/arch"}),
+	}
+	if platform.OS == "" {
+		platform.OS = node.Status.NodeInfo.OperatingSystem
+	}
+	if platform.Architecture == "" {
+		platform.Architecture = node.Status.NodeInfo.Architecture
+	}
+	return platform
+}
+
+// getContainerImage returns the image that the container actually ran: the digest from its status
+// if the runtime reported one, so that a moving tag doesn't send us to a different image.
+func getContainerImage(c v1.Container, statuses []v1.ContainerStatus) string {
+	for _, cs := range statuses {
+		if cs.Name != c.Name {
+			continue
+		}
+		image := strings.TrimPrefix(cs.ImageID, "docker-pullable://")
+		if strings.Contains(image, "@sha256:") {
+			return image
+		}
+	}
+	return c.Image
+}
+
+// getArchMismatchMessage returns the container's state message if it is the kind of message the
+// runtime gives for an image built for another platform.
+func getArchMismatchMessage(cs v1.ContainerStatus) string {
+	for _, state := range []v1.ContainerState{cs.State, cs.LastTerminationState} {
+		if state.Waiting != nil && archMismatchRegexp.MatchString(state.Waiting.Message) {
+			return state.Waiting.Message
+		}
+		if state.Terminated != nil && archMismatchRegexp.MatchString(state.Terminated.Message) {
+			return state.Terminated.Message
+		}
+	}
+	return ""
+}
+
+// getArchitectureMismatch checks the images of the containers whose runtime errors look like a
+// platform mismatch against the OS and architecture of the node they're scheduled on.  An
+// amd64-only image on an arm64 node doesn't fail at scheduling time; it fails when the container
+// starts, with an "exec format error" that doesn't point at the cause.  The image's platforms
+// come from its registry, when the registry is reachable from here; it is only asked about the
+// containers with such an error.
+func (dp *podInspectCommand) getArchitectureMismatch(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	mismatched := false
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if getArchMismatchMessage(cs) != "" {
+			mismatched = true
+		}
+	}
+	if !mismatched {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil || node == nil {
+		return "", err
+	}
+	nodePlatform := getNodePlatform(node)
+	if nodePlatform.Architecture == "" {
+		return "", nil
+	}
+
+	// the registry is asked anonymously unless the pull secrets may be used
+	var secrets []pullSecret
+	if dp.validatePullSecrets {
+		secrets, err = dp.getPullSecrets(pod)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Image").String(),
+		aurora.Yellow("Image Platforms").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	found := false
+	check := func(c v1.Container, statuses []v1.ContainerStatus) {
+		var cs *v1.ContainerStatus
+		for i := range statuses {
+			if statuses[i].Name == c.Name {
+				cs = &statuses[i]
+			}
+		}
+		if cs == nil || getArchMismatchMessage(*cs) == "" {
+			return
+		}
+
+		image := getContainerImage(c, statuses)
+
+		platforms, err := dp.getImagePlatforms(secrets, image)
+		if err != nil || len(platforms) == 0 {
+			// without the registry, all we have to go on is the runtime's error
+			found = true
+			tw.Append([]string{
+				c.Name,
+				c.Image,
+				"unknown (registry not reachable)",
+				fmt.Sprintf("%s  runtime reports a platform mismatch; check that the image is built for %s", aurora.Red("✖").String(), nodePlatform),
+			})
+			return
+		}
+
+		names := []string{}
+		matched := false
+		for _, p := range platforms {
+			names = append(names, p.String())
+			if p.OS == nodePlatform.OS && p.Architecture == nodePlatform.Architecture {
+				matched = true
+			}
+		}
+		if matched {
+			return
+		}
+
+		found = true
+		tw.Append([]string{
+			c.Name,
+			c.Image,
+			strings.Join(names, ", "),
+			fmt.Sprintf("%s  %s", aurora.Red("✖").String(), fmt.Sprintf("no %s image; the container can't run on this node", nodePlatform)),
+		})
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		check(c, pod.Status.InitContainerStatuses)
+	}
+	for _, c := range pod.Spec.Containers {
+		check(c, pod.Status.ContainerStatuses)
+	}
+
+	if !found {
+		return "", nil
+	}
+	tw.Render()
+
+	retval += aurora.Cyan(fmt.Sprintf("Architecture Mismatch (node %s is %s):\n\n", node.Name, nodePlatform)).String()
+	retval += sb.String()
+	retval += fmt.Sprintf("\n%s  build the image for %s (docker buildx build --platform %s), or keep the pod off these nodes with a %s node selector\n", aurora.Yellow("…").String(), nodePlatform, nodePlatform, v1.LabelArchStable)
+
+	return retval, nil
+}
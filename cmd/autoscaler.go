@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// where cluster-autoscaler publishes its status
+const (
+	autoscalerStatusNamespace = "kube-system"
+	autoscalerStatusConfigMap = "cluster-autoscaler-status"
+)
+
+// the cluster-wide scale-up status, in both the older plain-text status format
+// ("ScaleUp: InProgress (...)") and the newer YAML one ("scaleUp:\n  status: InProgress")
+var (
+	autoscalerScaleUpTextRegexp = regexp.MustCompile(`(?m)^\s*ScaleUp:\s+(\w+)`)
+	autoscalerScaleUpYAMLRegexp = regexp.MustCompile(`(?s)clusterWide:.*?scaleUp:\s*\n\s*status:\s*(\w+)`)
+)
+
+// isPodUnschedulable reports whether the scheduler has given up (for now) on placing the pod.
+func isPodUnschedulable(pod *v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionFalse && condition.Reason == v1.PodReasonUnschedulable {
+			return true
+		}
+	}
+	return false
+}
+
+// getAutoscalerStatus shows, for an unschedulable pod, what cluster-autoscaler decided about it:
+// whether it triggered a scale-up (and whether that is still in progress), or why no node group
+// could be scaled up to fit it.
+func (dp *podInspectCommand) getAutoscalerStatus(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if !isPodUnschedulable(pod) {
+		return "", nil
+	}
+
+	podEvents, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+
+	events := []v1.Event{}
+	for _, event := range podEvents {
+		if event.Source.Component == "cluster-autoscaler" || event.Reason == "TriggeredScaleUp" || event.Reason == "NotTriggerScaleUp" {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		a, b := getEventTimestamp(events[i]), getEventTimestamp(events[j])
+		return a.Before(&b)
+	})
+
+	scaleUpStatus, err := dp.getAutoscalerScaleUpStatus()
+	if err != nil {
+		return "", err
+	}
+
+	// no sign of cluster-autoscaler in this cluster
+	if len(events) == 0 && scaleUpStatus == "" {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Cluster Autoscaler:\n\n").String()
+
+	if len(events) == 0 {
+		retval += fmt.Sprintf("%s  cluster-autoscaler hasn't recorded a decision about this pod yet\n", aurora.Yellow("…").String())
+	} else {
+		latest := events[len(events)-1]
+		switch latest.Reason {
+		case "TriggeredScaleUp":
+			retval += fmt.Sprintf("%s  scale-up triggered: %s\n", aurora.Yellow("…").String(), latest.Message)
+		case "NotTriggerScaleUp":
+			retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("scale-up refused: %s", latest.Message)))
+		default:
+			retval += fmt.Sprintf("%s  %s: %s\n", aurora.Yellow("…").String(), latest.Reason, latest.Message)
+		}
+	}
+
+	if scaleUpStatus != "" {
+		retval += fmt.Sprintf("cluster-wide scale-up status: %s\n", scaleUpStatus)
+	}
+
+	if len(events) > 1 {
+		if dp.numEvents > 0 && len(events) > dp.numEvents {
+			events = events[len(events)-dp.numEvents:]
+		}
+
+		sb := &strings.Builder{}
+		tw := dp.newTablewriter(sb)
+
+		tw.Append([]string{
+			aurora.Yellow("Last Seen").String(),
+			aurora.Yellow("Reason").String(),
+			aurora.Yellow("Count").String(),
+			aurora.Yellow("Message").String(),
+		})
+
+		for _, event := range events {
+			timestamp := getEventTimestamp(event)
+			tw.Append([]string{
+				dp.formatTimestamp(timestamp),
+				event.Reason,
+				fmt.Sprintf("%d", event.Count),
+				event.Message,
+			})
+		}
+		tw.Render()
+
+		retval += "\n" + sb.String()
+	}
+
+	return retval, nil
+}
+
+// getAutoscalerScaleUpStatus reads the cluster-wide scale-up status (e.g. "InProgress",
+// "NoActivity") from cluster-autoscaler's status ConfigMap.  Returns "" if the ConfigMap isn't
+// there or can't be read; many users can't read kube-system, and that shouldn't fail the report.
+func (dp *podInspectCommand) getAutoscalerScaleUpStatus() (string, error) {
+	cm, err := dp.clientset.CoreV1().ConfigMaps(autoscalerStatusNamespace).Get(context.Background(), autoscalerStatusConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	status := cm.Data["status"]
+	if m := autoscalerScaleUpYAMLRegexp.FindStringSubmatch(status); m != nil {
+		return m[1], nil
+	}
+	if m := autoscalerScaleUpTextRegexp.FindStringSubmatch(status); m != nil {
+		return m[1], nil
+	}
+
+	return "", nil
+}
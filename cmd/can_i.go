@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+)
+
+func newCanICmd(dp *podInspectCommand) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "can-i",
+		Short:        "check whether you have the permissions pod-inspect needs",
+		Long:         "Checks, with SelfSubjectAccessReviews, each kind of API request that pod-inspect makes in the target namespace, and prints which of them you are allowed to make.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return errors.New("this command does not accept arguments")
+			}
+			return dp.runCanI()
+		},
+	}
+
+	// see newVersionCmd
+	oldLine := `{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+	newLine := `
+  kubectl pod-inspect can-i [flags]{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+
+	cmd.SetUsageTemplate(strings.Replace(cmd.UsageTemplate(), oldLine, newLine, 1))
+
+	return cmd
+}
+
+// getAccessChecks returns the distinct requests to check: the calls a sweep of the namespace
+// plans to make, with the reasons for requests that are needed for several things combined.
+func (dp *podInspectCommand) getAccessChecks() []apiCall {
+	checks := []apiCall{}
+	index := map[string]int{}
+
+	for _, call := range dp.plannedAPICalls(nil) {
+		key := fmt.Sprintf("%s %s %s", call.Verb, call.resourceString(), call.Namespace)
+		if i, ok := index[key]; ok {
+			checks[i].When += "; " + call.When
+			continue
+		}
+		index[key] = len(checks)
+		checks = append(checks, call)
+	}
+
+	return checks
+}
+
+// runCanI prints a capability matrix: for every kind of request pod-inspect makes, whether the
+// current user is allowed to make it.  Sections that need a denied request won't be able to show
+// anything.
+func (dp *podInspectCommand) runCanI() error {
+	if err := dp.complete(); err != nil {
+		return err
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Verb").String(),
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Namespace").String(),
+		aurora.Yellow("Allowed").String(),
+		aurora.Yellow("Needed").String(),
+	})
+
+	denied := 0
+	for _, check := range dp.getAccessChecks() {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   check.Namespace,
+					Verb:        check.Verb,
+					Group:       check.Group,
+					Resource:    check.Resource,
+					Subresource: check.Subresource,
+				},
+			},
+		}
+
+		result, err := dp.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(context.Background(), review, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+
+		allowed := aurora.Green("✔").String()
+		if !result.Status.Allowed {
+			allowed = aurora.Red("✖").String()
+			denied++
+		}
+
+		namespace := check.Namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+
+		tw.Append([]string{
+			check.Verb,
+			check.resourceString(),
+			namespace,
+			allowed,
+			check.When,
+		})
+	}
+	tw.Render()
+
+	if _, err := fmt.Fprintf(dp.out, "%s\n\n%s", aurora.Cyan(fmt.Sprintf("Permissions in namespace %s:", dp.namespace)), sb.String()); err != nil {
+		return err
+	}
+
+	if denied > 0 {
+		_, err := fmt.Fprintf(dp.out, "\n%s  %d of the requests would be denied; the sections that need them will be incomplete\n", aurora.Yellow("…").String(), denied)
+		return err
+	}
+
+	_, err := fmt.Fprintf(dp.out, "\n%s  all of the requests are allowed\n", aurora.Green("✔").String())
+	return err
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestGetAccessChecks(t *testing.T) {
+	dp := &podInspectCommand{namespace: "default"}
+	checks := dp.getAccessChecks()
+
+	seen := map[string]apiCall{}
+	for _, check := range checks {
+		key := fmt.Sprintf("%s %s %s", check.Verb, check.resourceString(), check.Namespace)
+		if _, ok := seen[key]; ok {
+			t.Errorf("getAccessChecks() checks %s twice", key)
+		}
+		seen[key] = check
+	}
+
+	for _, key := range []string{"list pods default", "get pods default", "list events default", "get pods/log default", "get nodes ", "get secrets default"} {
+		if _, ok := seen[key]; !ok {
+			t.Errorf("getAccessChecks() doesn't check %q", key)
+		}
+	}
+
+	// the cluster-wide event list is needed for node events and for NodeClaim events
+	events := seen["list events "].When
+	for _, when := range []string{"node events", "NodeClaim events"} {
+		if !strings.Contains(events, when) {
+			t.Errorf("getAccessChecks() list events reasons = %q, want them to include %q", events, when)
+		}
+	}
+}
+
+func TestPlannedSecretReads(t *testing.T) {
+	tests := []struct {
+		name string
+		dp   *podInspectCommand
+		want string
+	}{
+		{"default", &podInspectCommand{namespace: "default"}, "for pods that can't pull their images (image pull secrets)"},
+		{"validate pull secrets", &podInspectCommand{namespace: "default", validatePullSecrets: true}, "platform mismatch"},
+		{"image signatures", &podInspectCommand{namespace: "default", imageSignatures: true}, "for pods that can't pull their images (image pull secrets)"},
+		{"image signatures with pull secrets", &podInspectCommand{namespace: "default", imageSignatures: true, validatePullSecrets: true}, "for each pod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			for _, call := range tt.dp.plannedAPICalls(nil) {
+				if call.Resource == "secrets" {
+					got = call.When
+				}
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("plannedAPICalls() reads secrets %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
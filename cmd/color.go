@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	au "github.com/logrusorgru/aurora"
+)
+
+// aurora colors the human-readable output.  It is replaced with a colorless one when --color
+// turns color off, so that output written to files and pipes isn't full of escape codes.
+var aurora = au.NewAurora(true)
+
+// colorFunc is one of aurora's colors, e.g. aurora.Red.
+type colorFunc func(arg interface{}) au.Value
+
+// the settings for --color
+var colorModes = []string{"auto", "always", "never"}
+
+func validateColorMode(mode string) error {
+	for _, m := range colorModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported color mode '%s'; must be one of: %s", mode, strings.Join(colorModes, ", "))
+}
+
+// setColorMode turns color on or off for the rest of the run; "auto" colors the output only if
+// it's going to a terminal.
+func setColorMode(mode string) {
+	enabled := mode == "always" || (mode == "auto" && isTerminal())
+	aurora = au.NewAurora(enabled)
+}
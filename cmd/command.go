@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// formatCommandLine renders a command or args list the way it would be typed, quoting the items
+// that a shell would split or interpret.
+func formatCommandLine(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		if item == "" || strings.ContainsAny(item, " \t\n\"'\\$`;&|<>*?") {
+			item = strconv.Quote(item)
+		}
+		quoted = append(quoted, item)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// getContainerCommands shows each container's command, args, and working directory.  Wrong args
+// are one of the most common causes of a crashloop, and the container table only shows images.
+func (dp *podInspectCommand) getContainerCommands(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	retval += aurora.Cyan("Commands:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Name").String(),
+		aurora.Yellow("Command").String(),
+		aurora.Yellow("Args").String(),
+		aurora.Yellow("Working Dir").String(),
+	})
+
+	appendRow := func(typeCode string, c v1.Container) {
+		command := formatCommandLine(c.Command)
+		if len(c.Command) == 0 {
+			command = "(image ENTRYPOINT)"
+		}
+
+		args := formatCommandLine(c.Args)
+		if len(c.Args) == 0 {
+			// overriding the entrypoint also drops the image's CMD
+			if len(c.Command) == 0 {
+				args = "(image CMD)"
+			} else {
+				args = "(none)"
+			}
+		}
+
+		workingDir := c.WorkingDir
+		if workingDir == "" {
+			workingDir = "(image WORKDIR)"
+		}
+
+		tw.Append([]string{typeCode, c.Name, command, args, workingDir})
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		appendRow("IC", c)
+	}
+	for _, c := range pod.Spec.Containers {
+		appendRow("C", c)
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
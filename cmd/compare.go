@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// what --compare can compare the pod's env and mounts across
+var compareModes = []string{"containers", "replicas"}
+
+// replicas whose name we list in a row before summarizing the rest as a count
+const maxComparePodNames = 3
+
+func validateCompareMode(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	for _, m := range compareModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported comparison '%s'; must be one of: %s", mode, strings.Join(compareModes, ", "))
+}
+
+// describeEnvVar renders where an env var's value comes from, or the value itself.
+func describeEnvVar(e v1.EnvVar) string {
+	if e.ValueFrom == nil {
+		return formatDriftValue(fmt.Sprintf("%q", e.Value))
+	}
+	switch {
+	case e.ValueFrom.SecretKeyRef != nil:
+		return fmt.Sprintf("secret %s, key %s", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+	case e.ValueFrom.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("configmap %s, key %s", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+	case e.ValueFrom.FieldRef != nil:
+		return fmt.Sprintf("field %s", e.ValueFrom.FieldRef.FieldPath)
+	case e.ValueFrom.ResourceFieldRef != nil:
+		return fmt.Sprintf("resource %s", e.ValueFrom.ResourceFieldRef.Resource)
+	}
+	return "(unknown source)"
+}
+
+// getComparableSettings returns the container's env vars and volume mounts, keyed by an "env" or
+// "mount" kind and the variable or volume name.
+func getComparableSettings(c v1.Container) map[string]string {
+	settings := map[string]string{}
+
+	for _, e := range c.Env {
+		settings["env\x00"+e.Name] = describeEnvVar(e)
+	}
+	for _, source := range c.EnvFrom {
+		name := ""
+		if source.ConfigMapRef != nil {
+			name = fmt.Sprintf("configmap %s", source.ConfigMapRef.Name)
+		} else if source.SecretRef != nil {
+			name = fmt.Sprintf("secret %s", source.SecretRef.Name)
+		}
+		prefix := "(no prefix)"
+		if source.Prefix != "" {
+			prefix = fmt.Sprintf("prefix %s", source.Prefix)
+		}
+		settings["envFrom\x00"+name] = prefix
+	}
+	for _, m := range c.VolumeMounts {
+		value := m.MountPath
+		if m.SubPath != "" {
+			value += fmt.Sprintf(" (subPath %s)", m.SubPath)
+		}
+		if m.ReadOnly {
+			value += " (ro)"
+		}
+		key := "mount\x00" + m.Name
+		if existing, ok := settings[key]; ok {
+			value = existing + ", " + value
+		}
+		settings[key] = value
+	}
+
+	return settings
+}
+
+// getInconsistentSettings returns the sorted keys of the settings that are set in at least
+// minShared of the columns and aren't the same in all of them.
+func getInconsistentSettings(columns []map[string]string, minShared int) []string {
+	keys := map[string]bool{}
+	for _, column := range columns {
+		for key := range column {
+			keys[key] = true
+		}
+	}
+
+	inconsistent := []string{}
+	for key := range keys {
+		values := map[string]bool{}
+		count := 0
+		for _, column := range columns {
+			if value, ok := column[key]; ok {
+				values[value] = true
+				count++
+			}
+		}
+		if count >= minShared && (count < len(columns) || len(values) > 1) {
+			inconsistent = append(inconsistent, key)
+		}
+	}
+	sort.Strings(inconsistent)
+
+	return inconsistent
+}
+
+// getSettingComparison shows the env vars and volume mounts that differ between the pod's
+// containers, or between the pod and the other replicas of its workload.  When only one sidecar
+// or one replica misbehaves, the cause is often a setting that isn't what it is everywhere else.
+func (dp *podInspectCommand) getSettingComparison(pod *v1.Pod) (string, error) {
+	switch dp.compare {
+	case "containers":
+		return dp.getContainerComparison(pod), nil
+	case "replicas":
+		return dp.getReplicaComparison(pod)
+	}
+	return "", nil
+}
+
+func (dp *podInspectCommand) getContainerComparison(pod *v1.Pod) string {
+	retval := aurora.Cyan("Env and Mounts Across Containers:\n\n").String()
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	if len(containers) < 2 {
+		return retval + fmt.Sprintf("%s  the pod has only one container\n", aurora.Green("✔").String())
+	}
+
+	columns := []map[string]string{}
+	header := []string{aurora.Yellow("Kind").String(), aurora.Yellow("Name").String()}
+	for _, c := range containers {
+		columns = append(columns, getComparableSettings(c))
+		header = append(header, aurora.Yellow(c.Name).String())
+	}
+
+	// settings that a single container has to itself are expected (a sidecar's own config), so
+	// only those shared by some of the containers are compared
+	keys := getInconsistentSettings(columns, 2)
+	if len(keys) == 0 {
+		return retval + fmt.Sprintf("%s  the env and mounts the containers share are the same in all of them\n", aurora.Green("✔").String())
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+	tw.Append(header)
+
+	for _, key := range keys {
+		parts := strings.SplitN(key, "\x00", 2)
+		row := []string{parts[0], parts[1]}
+		for _, column := range columns {
+			value, ok := column[key]
+			if !ok {
+				value = aurora.Red("(not set)").String()
+			}
+			row = append(row, value)
+		}
+		tw.Append(row)
+	}
+	tw.Render()
+
+	return retval + sb.String()
+}
+
+// getWorkloadPods lists the pods that belong to the same top-level controller as the pod (for a
+// Deployment, the pods of all of its ReplicaSets), along with that controller.
+func (dp *podInspectCommand) getWorkloadPods(pod *v1.Pod) ([]v1.Pod, *ownerObject, error) {
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil || len(chain) == 0 {
+		return nil, nil, err
+	}
+	workload := chain[len(chain)-1]
+
+	pods, err := dp.listWorkloadPods(workload)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pods, workload, nil
+}
+
+// isWorkloadPod reports whether the workload is one of the pod's controllers, directly or further
+// up its chain (a Deployment, for the pods of its ReplicaSets).
+func (dp *podInspectCommand) isWorkloadPod(pod *v1.Pod, workload *ownerObject) (bool, error) {
+	if getControllerOf(pod.ObjectMeta) == nil {
+		return false, nil
+	}
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil {
+		return false, err
+	}
+	for _, owner := range chain {
+		if owner.Kind == workload.Kind && owner.Name == workload.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listWorkloadPods lists the pods that the workload controls, directly or through the
+// controllers below it.  Only the pods matching the workload's selector are listed (every pod in
+// the namespace, for a CronJob, which has none), and the result is cached per workload, since a
+// sweep compares each of its pods against the same replicas.
+func (dp *podInspectCommand) listWorkloadPods(workload *ownerObject) ([]v1.Pod, error) {
+	if pods, ok := dp.workloadPods[workload.Metadata.UID]; ok {
+		return pods, nil
+	}
+
+	selector, err := workload.getSelector()
+	if err != nil {
+		return nil, err
+	}
+
+	pods := []v1.Pod{}
+	opts := metav1.ListOptions{Limit: podListPageSize, LabelSelector: selector}
+	for {
+		podList, err := dp.clientset.CoreV1().Pods(dp.namespace).List(context.Background(), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range podList.Items {
+			ok, err := dp.isWorkloadPod(&podList.Items[i], workload)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				pods = append(pods, podList.Items[i])
+			}
+		}
+
+		if podList.Continue == "" {
+			break
+		}
+		opts.Continue = podList.Continue
+	}
+
+	if dp.workloadPods == nil {
+		dp.workloadPods = map[types.UID][]v1.Pod{}
+	}
+	dp.workloadPods[workload.Metadata.UID] = pods
+
+	return pods, nil
+}
+
+func (dp *podInspectCommand) getReplicaComparison(pod *v1.Pod) (string, error) {
+	pods, workload, err := dp.getWorkloadPods(pod)
+	if err != nil {
+		return "", err
+	}
+	if workload == nil {
+		return aurora.Cyan("Env and Mounts Across Replicas:\n\n").String() + fmt.Sprintf("%s  the pod isn't managed by a controller, so it has no replicas\n", aurora.Yellow("…").String()), nil
+	}
+
+	retval := aurora.Cyan(fmt.Sprintf("Env and Mounts Across Replicas (%s %s, %d pods):\n\n", workload.Kind, workload.Name, len(pods))).String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Kind").String(),
+		aurora.Yellow("Name").String(),
+		aurora.Yellow("Value").String(),
+		aurora.Yellow("Pods").String(),
+	})
+
+	found := false
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		columns := []map[string]string{}
+		podNames := []string{}
+		for _, p := range pods {
+			for _, pc := range append(append([]v1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+				if pc.Name == c.Name {
+					columns = append(columns, getComparableSettings(pc))
+					podNames = append(podNames, p.Name)
+				}
+			}
+		}
+
+		for _, key := range getInconsistentSettings(columns, 1) {
+			found = true
+			parts := strings.SplitN(key, "\x00", 2)
+
+			// group the replicas by the value they have
+			byValue := map[string][]string{}
+			for i, column := range columns {
+				value, ok := column[key]
+				if !ok {
+					value = aurora.Red("(not set)").String()
+				}
+				byValue[value] = append(byValue[value], podNames[i])
+			}
+			values := make([]string, 0, len(byValue))
+			for value := range byValue {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+
+			for i, value := range values {
+				names := byValue[value]
+				sort.Strings(names)
+				// the inspected pod goes first, so that it is never summarized away
+				for j, name := range names {
+					if name == pod.Name {
+						copy(names[1:j+1], names[:j])
+						names[0] = aurora.Yellow(name + " (this pod)").String()
+					}
+				}
+				shown := strings.Join(names, ", ")
+				if len(names) > maxComparePodNames {
+					shown = fmt.Sprintf("%s and %d more", strings.Join(names[:maxComparePodNames], ", "), len(names)-maxComparePodNames)
+				}
+
+				row := []string{"", "", "", value, shown}
+				if i == 0 {
+					row[0], row[1], row[2] = c.Name, parts[0], parts[1]
+				}
+				tw.Append(row)
+			}
+		}
+	}
+
+	if !found {
+		return retval + fmt.Sprintf("%s  every replica has the same env and mounts\n", aurora.Green("✔").String()), nil
+	}
+	tw.Render()
+
+	return retval + sb.String(), nil
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// a condition that has been in a bad state for longer than this is highlighted as stuck rather
+// than transient
+const conditionStuckThreshold = 5 * time.Minute
+
+// getPodConditionHistory renders every pod condition with its last transition time and how long
+// it has held its current status, so that a long-standing Ready=False stands out from a blip.  The
+// conditions that have failed are highlighted, with their reason and message.
+func (dp *podInspectCommand) getPodConditionHistory(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if len(pod.Status.Conditions) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Pod Conditions:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Condition").String(),
+		aurora.Yellow("Status").String(),
+		aurora.Yellow("Last Transition").String(),
+		aurora.Yellow("For").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, condition := range pod.Status.Conditions {
+		status := string(condition.Status)
+		since := formatAge(condition.LastTransitionTime)
+		lastTransition := "n/a"
+		if !condition.LastTransitionTime.IsZero() {
+			lastTransition = condition.LastTransitionTime.String()
+		}
+
+		failed := dp.isFailedPodCondition(pod, condition)
+		switch {
+		case condition.Status == v1.ConditionTrue:
+			status = aurora.Green(status).String()
+		case !failed:
+			// e.g. Ready=False on a pod that has completed
+		case condition.Status == v1.ConditionFalse:
+			status = aurora.Red(status).String()
+		default:
+			status = aurora.Yellow(status).String()
+		}
+
+		if failed && !condition.LastTransitionTime.IsZero() {
+			if time.Since(condition.LastTransitionTime.Time) > conditionStuckThreshold {
+				since = aurora.Red(since).String()
+			} else {
+				since = aurora.Yellow(since).String()
+			}
+		}
+
+		tw.Append([]string{
+			string(condition.Type),
+			status,
+			lastTransition,
+			since,
+			condition.Reason,
+			condition.Message,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+// the pod conditions that mark each step of pod startup, in the order they are reached
+var startupConditions = []v1.PodConditionType{
+	v1.PodScheduled,
+	v1.PodInitialized,
+	v1.ContainersReady,
+	v1.PodReady,
+}
+
+// getStartupTimeline renders how long each step of pod startup took, from creation through
+// scheduling, initialization, and readiness, to quantify where startup time goes.  For a pod that
+// has run to completion, the timeline ends when it completed.
+func (dp *podInspectCommand) getStartupTimeline(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	conditions := map[v1.PodConditionType]v1.PodCondition{}
+	for _, condition := range pod.Status.Conditions {
+		conditions[condition.Type] = condition
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Startup Timeline:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Step").String(),
+		aurora.Yellow("Reached").String(),
+		aurora.Yellow("Duration").String(),
+	})
+
+	created := pod.CreationTimestamp
+	tw.Append([]string{"Created", created.String(), ""})
+
+	previous := created
+	completed := false
+	for _, conditionType := range startupConditions {
+		condition, ok := conditions[conditionType]
+		if ok && condition.Status != v1.ConditionTrue && condition.Reason == "PodCompleted" {
+			// the pod ran to completion, which turns ContainersReady and Ready back to False;
+			// startup is over, so stop the timeline when it completed rather than counting on
+			reached := condition.LastTransitionTime
+			if reached.Before(&previous) {
+				reached = previous
+			}
+			tw.Append([]string{
+				"Completed",
+				reached.String(),
+				formatDuration(previous, reached),
+			})
+			previous = reached
+			completed = true
+			break
+		}
+		if !ok || condition.Status != v1.ConditionTrue {
+			// startup is stuck at this step; show how long we've been waiting on it
+			tw.Append([]string{
+				string(conditionType),
+				aurora.Yellow("pending").String(),
+				aurora.Yellow(formatDuration(previous, metav1.Time{})).String(),
+			})
+			break
+		}
+
+		reached := condition.LastTransitionTime
+		if reached.Before(&previous) {
+			// conditions can flip back and forth after startup; don't report negative durations
+			reached = previous
+		}
+
+		tw.Append([]string{
+			string(conditionType),
+			reached.String(),
+			formatDuration(previous, reached),
+		})
+		previous = reached
+	}
+
+	if ready, ok := conditions[v1.PodReady]; completed || (ok && ready.Status == v1.ConditionTrue) {
+		tw.Append([]string{
+			aurora.Cyan("Total").String(),
+			"",
+			formatDuration(created, previous),
+		})
+	}
+
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
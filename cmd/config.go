@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// the config file can be moved with this environment variable
+const configPathEnvVar = "KUBECTL_POD_INSPECT_CONFIG"
+
+// podInspectConfig is the config file: defaults for the command's flags, with a set for when the
+// output goes to a terminal and a set for when it's piped or redirected.  Flag names are the long
+// names, as on the command line; flags given on the command line always win.
+//
+//	interactive:
+//	  time-format: relative
+//	piped:
+//	  color: never
+//	  max-num-events: 50
+type podInspectConfig struct {
+	Interactive map[string]interface{} `json:"interactive"`
+	Piped       map[string]interface{} `json:"piped"`
+}
+
+// the settings for --time-format
+var timeFormats = []string{"absolute", "relative"}
+
+func validateTimeFormat(format string) error {
+	for _, f := range timeFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported time format '%s'; must be one of: %s", format, strings.Join(timeFormats, ", "))
+}
+
+// isTerminal reports whether stdout is a terminal, as opposed to a file or a pipe.
+func isTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// getConfigPath returns where the config file is read from: $KUBECTL_POD_INSPECT_CONFIG, or
+// ~/.kube/pod-inspect.yaml.
+func getConfigPath() string {
+	if path := os.Getenv(configPathEnvVar); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "pod-inspect.yaml")
+}
+
+// applyConfigDefaults sets the flags that weren't given on the command line to their defaults
+// from the config file, picking the interactive or the piped set by where stdout goes.  Each flag
+// is looked up in the first of the flag sets that has it, so that a subcommand's own flags come
+// before the root command's.  A missing config file is not an error.
+func applyConfigDefaults(flagSets ...*pflag.FlagSet) error {
+	path := getConfigPath()
+	if path == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	config := podInspectConfig{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("error reading %s: %v", path, err)
+	}
+
+	defaults := config.Piped
+	if isTerminal() {
+		defaults = config.Interactive
+	}
+
+	for name, value := range defaults {
+		var flags *pflag.FlagSet
+		for _, fs := range flagSets {
+			if fs.Lookup(name) != nil {
+				flags = fs
+				break
+			}
+		}
+		if flags == nil {
+			return fmt.Errorf("error reading %s: unknown flag '%s'", path, name)
+		}
+		if flags.Changed(name) {
+			continue
+		}
+
+		s := formatConfigValue(value)
+		if list, ok := value.([]interface{}); ok {
+			items := []string{}
+			for _, item := range list {
+				items = append(items, formatConfigValue(item))
+			}
+			s = strings.Join(items, ",")
+		}
+		// maps are for key=value flags, such as --condition-severities
+		if m, ok := value.(map[string]interface{}); ok {
+			items := []string{}
+			for key, item := range m {
+				items = append(items, fmt.Sprintf("%s=%s", key, formatConfigValue(item)))
+			}
+			sort.Strings(items)
+			s = strings.Join(items, ",")
+		}
+		if err := flags.Set(name, s); err != nil {
+			return fmt.Errorf("error reading %s: invalid value for %s: %v", path, name, err)
+		}
+	}
+
+	return nil
+}
+
+// formatConfigValue renders a value from the config file as it would be given on the command
+// line.  YAML numbers come in as floats, which fmt would give in exponent form (1e+06).
+func formatConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestFormatConfigValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{float64(1000000), "1000000"},
+		{float64(50), "50"},
+		{0.25, "0.25"},
+		{int64(7), "7"},
+		{true, "true"},
+		{"10m", "10m"},
+	}
+	for _, tt := range tests {
+		if got := formatConfigValue(tt.value); got != tt.want {
+			t.Errorf("formatConfigValue(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pod-inspect-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// tests don't run on a terminal, so the piped set applies
+	path := filepath.Join(dir, "pod-inspect.yaml")
+	config := "piped:\n  max-num-events: 1000000\n  color: never\n  history-db: config.db\n  sections: [events, logs]\n"
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv(configPathEnvVar, path)
+	defer os.Unsetenv(configPathEnvVar)
+
+	var numEvents int
+	var color, rootHistoryDB, subHistoryDB string
+	var sections []string
+	root := pflag.NewFlagSet("root", pflag.ContinueOnError)
+	root.IntVar(&numEvents, "max-num-events", 10, "")
+	root.StringVar(&color, "color", "auto", "")
+	root.StringVar(&rootHistoryDB, "history-db", "", "")
+	root.StringSliceVar(&sections, "sections", nil, "")
+	sub := pflag.NewFlagSet("query", pflag.ContinueOnError)
+	sub.StringVar(&subHistoryDB, "history-db", "", "")
+	if err := sub.Parse([]string{"--history-db", "given.db"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyConfigDefaults(sub, root); err != nil {
+		t.Fatalf("applyConfigDefaults() error = %v", err)
+	}
+	if numEvents != 1000000 || color != "never" || len(sections) != 2 {
+		t.Errorf("applyConfigDefaults() set max-num-events=%d color=%s sections=%v, want 1000000, never, [events logs]", numEvents, color, sections)
+	}
+	// the subcommand's own flag comes first, and what was given on the command line wins
+	if subHistoryDB != "given.db" || rootHistoryDB != "" {
+		t.Errorf("applyConfigDefaults() set history-db to %q and %q, want given.db and unset", subHistoryDB, rootHistoryDB)
+	}
+}
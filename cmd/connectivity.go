@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// connectivityScript checks a target ($1: host:port, or an http(s) URL) from inside a container
+// with whatever the image has: nc or bash's /dev/tcp for TCP, curl or wget for HTTP.  It prints
+// one line, "ok <tool> ...", "fail <tool> <error>", or "notool".
+const connectivityScript = `target="$1"
+case "$target" in
+http://*|https://*)
+  if command -v curl >/dev/null 2>&1; then
+    out=$(curl -sS -o /dev/null -m 5 -w 'HTTP %{http_code}' "$target" 2>&1) && echo "ok curl $out" || echo "fail curl $out"
+  elif command -v wget >/dev/null 2>&1; then
+    out=$(wget -q -O /dev/null -T 5 "$target" 2>&1) && echo "ok wget" || echo "fail wget $out"
+  else
+    echo notool
+  fi ;;
+*)
+  host="${target%:*}"; port="${target##*:}"
+  host="${host#[}"; host="${host%]}"
+  if command -v nc >/dev/null 2>&1; then
+    out=$(nc -z -w 5 "$host" "$port" 2>&1) && echo "ok nc" || echo "fail nc $out"
+  elif command -v bash >/dev/null 2>&1; then
+    out=$(timeout 5 bash -c 'exec 3<>"/dev/tcp/$0/$1"' "$host" "$port" 2>&1) && echo "ok bash" || echo "fail bash $out"
+  else
+    echo notool
+  fi ;;
+esac
+`
+
+func validateConnectivityTargets(targets []string) error {
+	for _, target := range targets {
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			if u, err := url.Parse(target); err != nil || u.Host == "" {
+				return fmt.Errorf("invalid --connectivity URL '%s'", target)
+			}
+			continue
+		}
+		if _, port, err := net.SplitHostPort(target); err != nil || port == "" {
+			return fmt.Errorf("invalid --connectivity target '%s'; expected host:port or an http(s) URL", target)
+		}
+	}
+	return nil
+}
+
+// how long to wait for a command run in a container.  The checks bound themselves well within
+// this, so it only catches an exec that hangs, e.g. on a node that has stopped responding.
+const execTimeout = 15 * time.Second
+
+// execResult is the output of a command run in a container.
+type execResult struct {
+	stdout string
+	stderr string
+	err    error
+}
+
+// execInContainer runs a command in one of the pod's containers, returning its output, or an
+// error if it doesn't finish within execTimeout.
+func (dp *podInspectCommand) execInContainer(pod *v1.Pod, container string, command []string) (string, string, error) {
+	config, err := dp.f.ToRESTConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	req := dp.clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{Container: container, Command: command, Stdout: true, Stderr: true}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", "", err
+	}
+
+	// this client-go's Stream can't be cancelled, so a hung one is left behind rather than waited for
+	done := make(chan execResult, 1)
+	go func() {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		err := executor.Stream(remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+		done <- execResult{stdout: stdout.String(), stderr: stderr.String(), err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.stdout, r.stderr, r.err
+	case <-time.After(execTimeout):
+		return "", "", fmt.Errorf("%s didn't finish within %s", command[0], execTimeout)
+	}
+}
+
+// getConnectivityContainer picks the container to run the checks from: the one named with
+// --connectivity-container, or else the first running one.  All of a pod's containers share
+// its network namespace, so any of them sees the same network.
+func (dp *podInspectCommand) getConnectivityContainer(pod *v1.Pod) string {
+	if dp.connectivityContainer != "" {
+		return dp.connectivityContainer
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Running != nil {
+			return cs.Name
+		}
+	}
+	return ""
+}
+
+// describeConnectivityResult turns the script's output line into a result for the table.
+func describeConnectivityResult(stdout string, stderr string, err error) string {
+	if err != nil {
+		if strings.Contains(err.Error(), "executable file not found") || strings.Contains(stderr, "executable file not found") {
+			return fmt.Sprintf("%s  the image has no shell to run the check with", aurora.Yellow("…").String())
+		}
+		if note := describeForbidden(err); note != "" {
+			return fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), note)
+		}
+		return fmt.Sprintf("%s  exec failed: %v", aurora.Yellow("…").String(), err)
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(stdout), " ", 3)
+	switch fields[0] {
+	case "ok":
+		return fmt.Sprintf("%s  reachable (%s)", aurora.Green("✔").String(), strings.Join(fields[1:], " "))
+	case "fail":
+		detail := fields[1]
+		if len(fields) > 2 {
+			detail = fmt.Sprintf("%s: %s", fields[1], sanitizeLogLine(fields[2]))
+		}
+		return fmt.Sprintf("%s  unreachable (%s)", aurora.Red("✖").String(), detail)
+	case "notool":
+		return fmt.Sprintf("%s  the image has none of nc, bash, curl, or wget to run the check with", aurora.Yellow("…").String())
+	}
+	return fmt.Sprintf("%s  unexpected output: %s", aurora.Yellow("…").String(), sanitizeLogLine(strings.TrimSpace(stdout+" "+stderr)))
+}
+
+// getConnectivityChecks checks, from inside the pod's network namespace, that the --connectivity
+// targets can be reached, which tests egress and network policy as the pod itself sees them.
+// The checks are run with the tools found in the container's image; they can't be run from
+// distroless images, since injecting an ephemeral container is only an alpha feature in the API
+// versions we build against.
+func (dp *podInspectCommand) getConnectivityChecks(pod *v1.Pod) (string, error) {
+	if len(dp.connectivity) == 0 {
+		return "", nil
+	}
+
+	retval := aurora.Cyan("Connectivity:\n\n").String()
+
+	container := dp.getConnectivityContainer(pod)
+	if container == "" {
+		retval += fmt.Sprintf("%s  no container is running to check from\n", aurora.Yellow("…").String())
+		return retval, nil
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Target").String(),
+		aurora.Yellow("From").String(),
+		aurora.Yellow("Result").String(),
+	})
+	for _, target := range dp.connectivity {
+		stdout, stderr, err := dp.execInContainer(pod, container, []string{"sh", "-c", connectivityScript, "sh", target})
+		tw.Append([]string{target, container, describeConnectivityResult(stdout, stderr, err)})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
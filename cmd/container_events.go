@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the kubelet points events about a container at it with a field path like spec.containers{app}
+var containerFieldPathRegexp = regexp.MustCompile(`^spec\.(?:initContainers|containers|ephemeralContainers)\{(.+)\}$`)
+
+// events without a field path name the container in the message ("Back-off restarting failed
+// container app in pod ...")
+var containerMessageRegexp = regexp.MustCompile(`(?i)\bcontainers? "?([a-z0-9]([-a-z0-9]*[a-z0-9])?)"?`)
+
+// getEventContainer returns the name of the pod's container that an event concerns, or "" for
+// events about the pod as a whole.
+func getEventContainer(event v1.Event, pod *v1.Pod) string {
+	if m := containerFieldPathRegexp.FindStringSubmatch(event.InvolvedObject.FieldPath); m != nil {
+		return m[1]
+	}
+
+	names := map[string]bool{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		names[c.Name] = true
+	}
+	for _, m := range containerMessageRegexp.FindAllStringSubmatch(event.Message, -1) {
+		if names[m[1]] {
+			return m[1]
+		}
+	}
+
+	return ""
+}
+
+// groupContainerEvents splits the pod's events into those about each of its containers, by
+// container name, and those about the pod itself.
+func groupContainerEvents(events []v1.Event, pod *v1.Pod) (map[string][]v1.Event, []v1.Event) {
+	byContainer := map[string][]v1.Event{}
+	podEvents := []v1.Event{}
+
+	for _, event := range events {
+		if name := getEventContainer(event, pod); name != "" {
+			byContainer[name] = append(byContainer[name], event)
+		} else {
+			podEvents = append(podEvents, event)
+		}
+	}
+
+	return byContainer, podEvents
+}
+
+// formatContainerEvent renders an event as a line for the container table, with warnings
+// highlighted.
+func (dp *podInspectCommand) formatContainerEvent(event v1.Event) string {
+	line := fmt.Sprintf("%s  %s: %s", dp.formatTimestamp(getEventTimestamp(event)), event.Reason, event.Message)
+	if event.Count > 1 {
+		line += fmt.Sprintf(" (x%d)", event.Count)
+	}
+	if event.Type == v1.EventTypeWarning {
+		return aurora.Yellow(line).String()
+	}
+	return line
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// getContainerResources lists each container's CPU and memory requests and limits, from the pod
+// spec.  A container without requests is scheduled as if it used nothing and is among the first
+// to go when the node runs short; one with a CPU limit is throttled at it, and one with a memory
+// limit is OOM-killed at it, which is behind many of the failures the rest of the report shows.
+//
+// For a running pod, each container's current usage is shown next to them, if metrics-server is
+// installed: a container sitting at its memory limit is about to be OOM-killed, and one at its
+// CPU limit is being throttled.
+func (dp *podInspectCommand) getContainerResources(pod *v1.Pod) (string, error) {
+	retval := aurora.Cyan("Container Resources:\n\n").String()
+
+	notes := []string{}
+
+	var metrics *podMetrics
+	if pod.Status.Phase == v1.PodRunning {
+		var err error
+		metrics, err = dp.getPodMetrics(pod)
+		if note := describeForbidden(err); note != "" {
+			notes = append(notes, fmt.Sprintf("%s  usage not shown: %s", aurora.Yellow("…").String(), note))
+		} else if err != nil {
+			return "", err
+		} else if metrics == nil {
+			notes = append(notes, fmt.Sprintf("%s  usage not shown: metrics-server isn't installed, or has no sample of the pod yet", aurora.Yellow("…").String()))
+		}
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	header := []string{
+		"",
+		aurora.Yellow("CPU Request").String(),
+		aurora.Yellow("CPU Limit").String(),
+	}
+	if metrics != nil {
+		header = append(header, aurora.Yellow("CPU Usage").String())
+	}
+	header = append(header,
+		aurora.Yellow("Memory Request").String(),
+		aurora.Yellow("Memory Limit").String(),
+	)
+	if metrics != nil {
+		header = append(header, aurora.Yellow("Memory Usage").String())
+	}
+	tw.Append(header)
+
+	formatResource := func(resources v1.ResourceList, name v1.ResourceName) string {
+		q, ok := resources[name]
+		if !ok {
+			return formatOptionalQuantity(q, ok)
+		}
+		return formatHeadroomQuantity(name, q)
+	}
+
+	// formatUsage renders a container's usage of a resource, as a share of its limit, or of its
+	// request if it has no limit
+	formatUsage := func(c v1.Container, name v1.ResourceName, label string) string {
+		usage, ok := metrics.getContainerUsage(c.Name, name)
+		if !ok {
+			return "-"
+		}
+		retval := formatHeadroomQuantity(name, usage)
+		if name == v1.ResourceCPU {
+			retval = fmt.Sprintf("%dm", usage.MilliValue())
+		}
+
+		of, bound := "limit", c.Resources.Limits[name]
+		if _, ok := c.Resources.Limits[name]; !ok {
+			of, bound = "request", c.Resources.Requests[name]
+		}
+		if bound.MilliValue() <= 0 {
+			return retval
+		}
+		percent := usage.MilliValue() * 100 / bound.MilliValue()
+		retval = fmt.Sprintf("%s (%d%% of %s)", retval, percent, of)
+		if of != "limit" || percent < oomUsageWarnPercent {
+			return retval
+		}
+
+		if name == v1.ResourceMemory {
+			notes = append(notes, fmt.Sprintf("%s  %s %s is at %d%% of its memory limit, and will be OOM-killed if it grows any further", aurora.Red("✖").String(), label, c.Name, percent))
+			return aurora.Red(retval).String()
+		}
+		notes = append(notes, fmt.Sprintf("%s  %s %s is at %d%% of its CPU limit, and is likely being throttled", aurora.Yellow("…").String(), label, c.Name, percent))
+		return aurora.Yellow(retval).String()
+	}
+
+	for _, containers := range []struct {
+		label      string
+		containers []v1.Container
+	}{
+		{"init container", pod.Spec.InitContainers},
+		{"container", pod.Spec.Containers},
+	} {
+		for _, c := range containers.containers {
+			requests, limits := c.Resources.Requests, c.Resources.Limits
+			row := []string{
+				fmt.Sprintf("%s %s", containers.label, c.Name),
+				formatResource(requests, v1.ResourceCPU),
+				formatResource(limits, v1.ResourceCPU),
+			}
+			if metrics != nil {
+				row = append(row, formatUsage(c, v1.ResourceCPU, containers.label))
+			}
+			row = append(row,
+				formatResource(requests, v1.ResourceMemory),
+				formatResource(limits, v1.ResourceMemory),
+			)
+			if metrics != nil {
+				row = append(row, formatUsage(c, v1.ResourceMemory, containers.label))
+			}
+			tw.Append(row)
+
+			_, cpuRequest := requests[v1.ResourceCPU]
+			_, memoryRequest := requests[v1.ResourceMemory]
+			_, cpuLimit := limits[v1.ResourceCPU]
+			_, memoryLimit := limits[v1.ResourceMemory]
+			// an unset request defaults to the limit, so only a container with neither is unaccounted for
+			if !cpuRequest && !memoryRequest && !cpuLimit && !memoryLimit {
+				notes = append(notes, fmt.Sprintf("%s  %s %s has no requests; it's scheduled as if it used nothing, and is among the first to be evicted when the node runs short", aurora.Yellow("…").String(), containers.label, c.Name))
+			}
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// hours in an average month, for the monthly figure
+const hoursPerMonth = 730
+
+// the default hourly prices per unit of each resource: per core for cpu, per GiB for memory and
+// storage, and per device for everything else.  These are ballpark on-demand list prices for
+// general-purpose cloud VMs; use --cost-prices to substitute your own.
+var defaultResourcePrices = map[string]float64{
+	string(v1.ResourceCPU):              0.0316,
+	string(v1.ResourceMemory):           0.0042,
+	string(v1.ResourceEphemeralStorage): 0.0001,
+	"nvidia.com/gpu":                    0.35,
+}
+
+// parseResourcePrices merges the --cost-prices overrides into the default price table.
+func parseResourcePrices(overrides map[string]string) (map[string]float64, error) {
+	prices := map[string]float64{}
+	for name, price := range defaultResourcePrices {
+		prices[name] = price
+	}
+
+	for name, value := range overrides {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid price '%s' for %s in --cost-prices; must be a non-negative number", value, name)
+		}
+		prices[name] = price
+	}
+
+	return prices, nil
+}
+
+// getResourceUnits converts a quantity into the unit its price is quoted in, returning the
+// amount and the unit's name.
+func getResourceUnits(name v1.ResourceName, q resource.Quantity) (float64, string) {
+	switch {
+	case name == v1.ResourceCPU:
+		return float64(q.MilliValue()) / 1000, "core"
+	case name == v1.ResourceMemory || name == v1.ResourceEphemeralStorage || isHugePagesResource(name):
+		return float64(q.Value()) / (1 << 30), "GiB"
+	default:
+		return float64(q.Value()), "unit"
+	}
+}
+
+// getCostEstimate estimates what the pod costs per hour from its resource requests and the
+// configured price per resource.  It's a rough figure for right-sizing discussions, not a bill:
+// it prices what the pod reserves, not what it uses, and ignores the node's actual price.
+func (dp *podInspectCommand) getCostEstimate(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if !dp.cost {
+		return "", nil
+	}
+
+	prices, err := parseResourcePrices(dp.costPrices)
+	if err != nil {
+		return "", err
+	}
+
+	requests := getPodEffectiveRequests(pod)
+
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	retval += aurora.Cyan("Cost Estimate:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Requested").String(),
+		aurora.Yellow("Price").String(),
+		aurora.Yellow("Per Hour").String(),
+	})
+
+	total := 0.0
+	unpriced := []string{}
+	for _, n := range names {
+		name := v1.ResourceName(n)
+		q := requests[name]
+		price, ok := prices[n]
+		if !ok {
+			unpriced = append(unpriced, n)
+			continue
+		}
+
+		amount, unit := getResourceUnits(name, q)
+		hourly := amount * price
+		total += hourly
+
+		tw.Append([]string{
+			n,
+			q.String(),
+			fmt.Sprintf("$%.4f/%s-hour", price, unit),
+			fmt.Sprintf("$%.4f", hourly),
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	retval += fmt.Sprintf("\ntotal: $%.4f/hour, about $%.2f/month\n", total, total*hoursPerMonth)
+
+	if len(requests) == 0 {
+		retval += fmt.Sprintf("%s  the pod requests no resources, so it is priced at nothing; it still uses whatever it can get\n", aurora.Yellow("…").String())
+	}
+	if len(unpriced) > 0 {
+		retval += fmt.Sprintf("%s  no price for %s; set one with --cost-prices\n", aurora.Yellow("…").String(), strings.Join(unpriced, ", "))
+	}
+
+	// the instance type is a hint at what the node really costs, which may be quite different
+	node, err := dp.getScheduledNode(pod)
+	if err != nil {
+		return "", err
+	}
+	if node != nil {
+		if instanceType := getFirstLabel(node, []string{v1.LabelInstanceTypeStable, v1.LabelInstanceType}); instanceType != "" {
+			hint := fmt.Sprintf("node %s is a %s", node.Name, instanceType)
+			if spotLabel := getSpotNodeLabel(node); spotLabel != "" {
+				hint += " spot instance"
+			}
+			retval += fmt.Sprintf("%s; compare with its list price for a more accurate figure\n", hint)
+		}
+	}
+
+	return retval, nil
+}
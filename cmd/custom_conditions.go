@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the pod conditions the kubelet and the control plane set themselves
+var builtinPodConditions = map[v1.PodConditionType]bool{
+	v1.PodScheduled:             true,
+	v1.PodInitialized:           true,
+	v1.ContainersReady:          true,
+	v1.PodReady:                 true,
+	"PodReadyToStartContainers": true,
+	"PodHasNetwork":             true,
+	"DisruptionTarget":          true,
+	"PodResizePending":          true,
+	"PodResizeInProgress":       true,
+}
+
+// the controllers known to set custom pod conditions, by condition type; patterns match the way
+// path.Match does, so "karpenter.sh/*" matches every condition Karpenter sets
+var knownConditionSources = []struct {
+	pattern string
+	source  string
+}{
+	{"target-health.elbv2.k8s.aws/*", "AWS Load Balancer Controller"},
+	{"target-health.alb.ingress.k8s.aws/*", "AWS ALB Ingress Controller"},
+	{"cloud.google.com/load-balancer-neg-ready", "GKE NEG controller"},
+	{"karpenter.sh/*", "Karpenter"},
+	{"karpenter.k8s.aws/*", "Karpenter"},
+	{"kueue.x-k8s.io/*", "Kueue"},
+}
+
+// the severities --condition-severities can give a custom condition that isn't True
+var conditionSeverityLevels = []string{"error", "warning", "info"}
+
+func validateConditionSeverities(severities map[string]string) error {
+	for pattern, severity := range severities {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid condition pattern '%s' in --condition-severities: %v", pattern, err)
+		}
+		valid := false
+		for _, s := range conditionSeverityLevels {
+			valid = valid || severity == s
+		}
+		if !valid {
+			return fmt.Errorf("unsupported severity '%s' for condition '%s'; must be one of: %s", severity, pattern, strings.Join(conditionSeverityLevels, ", "))
+		}
+	}
+	return nil
+}
+
+// isReadinessGate reports whether the pod has a readiness gate on the condition, which keeps
+// the pod out of Ready until the condition is True.
+func isReadinessGate(pod *v1.Pod, conditionType v1.PodConditionType) bool {
+	for _, gate := range pod.Spec.ReadinessGates {
+		if gate.ConditionType == conditionType {
+			return true
+		}
+	}
+	return false
+}
+
+// getConditionSource names what sets a custom condition, as far as we know it.
+func getConditionSource(pod *v1.Pod, conditionType v1.PodConditionType) string {
+	source := ""
+	for _, known := range knownConditionSources {
+		if matched, _ := path.Match(known.pattern, string(conditionType)); matched {
+			source = known.source
+			break
+		}
+	}
+	if isReadinessGate(pod, conditionType) {
+		source = strings.TrimPrefix(fmt.Sprintf("%s, readiness gate", source), ", ")
+	}
+	return source
+}
+
+// getConditionSeverity returns how bad it is for a custom condition not to be True: as mapped
+// with --condition-severities, by exact type or by the longest matching pattern, or otherwise
+// an error for a readiness gate, which keeps the pod from being Ready, and a warning for
+// anything else.
+func (dp *podInspectCommand) getConditionSeverity(pod *v1.Pod, conditionType v1.PodConditionType) string {
+	if severity, ok := dp.conditionSeverities[string(conditionType)]; ok {
+		return severity
+	}
+
+	patterns := []string{}
+	for pattern := range dp.conditionSeverities {
+		if matched, _ := path.Match(pattern, string(conditionType)); matched {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) > 0 {
+		sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+		return dp.conditionSeverities[patterns[0]]
+	}
+
+	if isReadinessGate(pod, conditionType) {
+		return "error"
+	}
+	return "warning"
+}
+
+// getCustomPodConditions renders the conditions that readiness gates, load balancer controllers,
+// Karpenter and the like set on the pod, with their reasons and messages, which the built-in
+// conditions can't explain: a pod held out of Ready by a readiness gate is only "not ready" as
+// far as Ready and ContainersReady go.  A readiness gate whose condition hasn't been set at all
+// is shown too, since that is how a controller that never picked the pod up looks.
+func (dp *podInspectCommand) getCustomPodConditions(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	conditions := []v1.PodCondition{}
+	seen := map[v1.PodConditionType]bool{}
+	for _, condition := range pod.Status.Conditions {
+		if !builtinPodConditions[condition.Type] {
+			conditions = append(conditions, condition)
+			seen[condition.Type] = true
+		}
+	}
+	for _, gate := range pod.Spec.ReadinessGates {
+		if !seen[gate.ConditionType] {
+			conditions = append(conditions, v1.PodCondition{Type: gate.ConditionType, Status: v1.ConditionUnknown, Message: "not set; the controller behind this readiness gate hasn't reported on the pod"})
+		}
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Custom Pod Conditions:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Condition").String(),
+		aurora.Yellow("Set By").String(),
+		aurora.Yellow("Status").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, condition := range conditions {
+		status := aurora.Green(string(condition.Status)).String()
+		if condition.Status != v1.ConditionTrue {
+			switch dp.getConditionSeverity(pod, condition.Type) {
+			case "error":
+				status = fmt.Sprintf("%s  %s", aurora.Red("✖").String(), aurora.Red(string(condition.Status)))
+			case "warning":
+				status = fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), aurora.Yellow(string(condition.Status)))
+			default:
+				status = string(condition.Status)
+			}
+		}
+
+		tw.Append([]string{
+			string(condition.Type),
+			getConditionSource(pod, condition.Type),
+			status,
+			condition.Reason,
+			condition.Message,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
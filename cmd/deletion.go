@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// the finalizers that the Kubernetes control plane puts on pods, and what removes them
+var knownPodFinalizers = map[string]string{
+	"foregroundDeletion":               "garbage collector; waits for the pod's dependents to be deleted",
+	"orphan":                           "garbage collector; waits for the pod's dependents to be orphaned",
+	"batch.kubernetes.io/job-tracking": "job controller; removed once the Job has counted the pod",
+}
+
+// describeFinalizerOwner says what is, most likely, responsible for removing a finalizer.
+func describeFinalizerOwner(finalizer string) string {
+	if owner, ok := knownPodFinalizers[finalizer]; ok {
+		return owner
+	}
+	if i := strings.Index(finalizer, "/"); i > 0 {
+		return fmt.Sprintf("a controller or operator from %s", finalizer[:i])
+	}
+	return "unknown controller"
+}
+
+// isNodeReady reports whether the node's kubelet is posting a Ready status.
+func isNodeReady(node *v1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getDeletionStatus explains why a pod that is being deleted is still around: how long it has
+// been terminating, the finalizers that are still holding it and who is expected to remove them,
+// the containers that are still running, and whether the kubelet is in any position to finish the
+// job.
+func (dp *podInspectCommand) getDeletionStatus(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if pod.DeletionTimestamp == nil {
+		return "", nil
+	}
+
+	gracePeriod := time.Duration(0)
+	if pod.DeletionGracePeriodSeconds != nil {
+		gracePeriod = time.Duration(*pod.DeletionGracePeriodSeconds) * time.Second
+	}
+	// the deletion timestamp is when the grace period runs out, not when deletion was requested
+	requested := pod.DeletionTimestamp.Add(-gracePeriod)
+	overdue := time.Since(pod.DeletionTimestamp.Time)
+
+	retval += aurora.Cyan("Deletion:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{aurora.Yellow("Terminating for").String(), duration.HumanDuration(time.Since(requested))})
+	grace := fmt.Sprintf("%s (ends %s)", duration.HumanDuration(gracePeriod), pod.DeletionTimestamp.Format(time.RFC3339))
+	if overdue > 0 {
+		grace += aurora.Red(fmt.Sprintf("; overdue by %s", duration.HumanDuration(overdue))).String()
+	}
+	tw.Append([]string{aurora.Yellow("Grace period").String(), grace})
+
+	running := []string{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if cs.State.Running != nil {
+			running = append(running, cs.Name)
+		}
+	}
+	if len(running) > 0 {
+		tw.Append([]string{aurora.Yellow("Still running").String(), strings.Join(running, ", ")})
+	} else {
+		tw.Append([]string{aurora.Yellow("Still running").String(), "no containers"})
+	}
+
+	for i, finalizer := range pod.Finalizers {
+		label := ""
+		if i == 0 {
+			label = aurora.Yellow("Finalizers").String()
+		}
+		tw.Append([]string{label, fmt.Sprintf("%s (%s)", finalizer, describeFinalizerOwner(finalizer))})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if overdue <= 0 {
+		return retval, nil
+	}
+
+	// work out what is holding the pod up
+	node, err := dp.getScheduledNode(pod)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case pod.Spec.NodeName != "" && node == nil:
+		retval += fmt.Sprintf("\n%s  node %s no longer exists, so no kubelet will ever confirm that the containers stopped\n", aurora.Red("✖").String(), pod.Spec.NodeName)
+	case node != nil && !isNodeReady(node):
+		retval += fmt.Sprintf("\n%s  node %s is not ready; its kubelet can't confirm that the containers stopped until it reconnects\n", aurora.Red("✖").String(), node.Name)
+	case len(running) > 0:
+		retval += fmt.Sprintf("\n%s  containers are still running past the grace period; the kubelet may be failing to kill them (check the node's container runtime)\n", aurora.Red("✖").String())
+	}
+
+	if len(pod.Finalizers) > 0 {
+		retval += fmt.Sprintf("\n%s  the pod can't be removed until its finalizers are; once you are sure their controller is gone or stuck, and any cleanup it does isn't needed, remove them with:\n", aurora.Yellow("…").String())
+		retval += fmt.Sprintf("     kubectl patch pod %s -n %s --type=merge -p '{\"metadata\":{\"finalizers\":null}}'\n", pod.Name, pod.Namespace)
+	} else if node == nil || !isNodeReady(node) {
+		retval += fmt.Sprintf("\n%s  if the node is really gone, and the containers with it, force the deletion with:\n", aurora.Yellow("…").String())
+		retval += fmt.Sprintf("     kubectl delete pod %s -n %s --grace-period=0 --force\n", pod.Name, pod.Namespace)
+		retval += "   a StatefulSet pod that is force deleted while it is still running can end up running twice\n"
+	}
+
+	return retval, nil
+}
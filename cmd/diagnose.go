@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// diagnosisRules interpret what has gone wrong with a pod, from its container states, exit codes,
+// events and probe config, each finding naming the likely cause and what to do about it.  They
+// run on every pod with a problem; --diagnosis-rules narrows them down.
+var diagnosisRules = []rule{
+	{
+		Name:        "oom",
+		Description: "containers killed for running out of memory",
+		Check:       diagnoseOOM,
+	},
+	{
+		Name:        "crash-loop",
+		Description: "containers that keep exiting with an error",
+		Check:       diagnoseCrashLoop,
+	},
+	{
+		Name:        "image-pull",
+		Description: "containers that can't pull their image",
+		Check:       diagnoseImagePull,
+	},
+	{
+		Name:        "config-error",
+		Description: "containers that can't be created from their spec",
+		Check:       diagnoseConfigError,
+	},
+	{
+		Name:        "unschedulable",
+		Description: "pods the scheduler can't place",
+		Check:       diagnoseUnschedulable,
+	},
+	{
+		Name:        "probe-failures",
+		Description: "containers failing their readiness or liveness probes",
+		Check:       diagnoseProbeFailures,
+	},
+	{
+		Name:        "mount-failures",
+		Description: "volumes that can't be attached or mounted",
+		Check:       diagnoseMountFailures,
+	},
+}
+
+// containerWithStatus pairs a container of the pod with its status.
+type containerWithStatus struct {
+	Container v1.Container
+	Status    v1.ContainerStatus
+	Init      bool
+}
+
+// getContainersWithStatus returns the pod's init and regular containers that have a status.
+func getContainersWithStatus(pod *v1.Pod) []containerWithStatus {
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		statuses[cs.Name] = cs
+	}
+
+	containers := []containerWithStatus{}
+	for _, c := range pod.Spec.InitContainers {
+		if cs, ok := statuses[c.Name]; ok {
+			containers = append(containers, containerWithStatus{Container: c, Status: cs, Init: true})
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if cs, ok := statuses[c.Name]; ok {
+			containers = append(containers, containerWithStatus{Container: c, Status: cs})
+		}
+	}
+	return containers
+}
+
+// getWaitingReason returns the reason a container is waiting, or "".
+func getWaitingReason(cs v1.ContainerStatus) string {
+	if cs.State.Waiting == nil {
+		return ""
+	}
+	return cs.State.Waiting.Reason
+}
+
+// getLastFailure returns the container's most recent termination with a non-zero exit code:
+// the current state if it's terminated, otherwise the last one.
+func getLastFailure(cs v1.ContainerStatus) *v1.ContainerStateTerminated {
+	for _, t := range []*v1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+		if t != nil && t.ExitCode != 0 {
+			return t
+		}
+	}
+	return nil
+}
+
+// describeCrashState names what a failing container is doing, to lead a diagnosis with.
+func describeCrashState(cs v1.ContainerStatus) string {
+	if reason := getWaitingReason(cs); reason != "" {
+		return reason
+	}
+	if cs.State.Terminated != nil {
+		return "Terminated"
+	}
+	return "Restarting"
+}
+
+func diagnoseOOM(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		t := getLastFailure(c.Status)
+		if t == nil {
+			continue
+		}
+		signal, _ := getTerminationSignal(t)
+		if t.Reason != "OOMKilled" && signal != 9 {
+			continue
+		}
+
+		limit := "no memory limit"
+		if q, ok := c.Container.Resources.Limits[v1.ResourceMemory]; ok {
+			limit = fmt.Sprintf("a memory limit of %s", q.String())
+		}
+
+		if t.Reason == "OOMKilled" {
+			findings = append(findings, fmt.Sprintf("%s: container %s exits with %d, OOMKilled — it ran out of memory with %s; raise the memory limit of container %s, or find out what is using the memory",
+				describeCrashState(c.Status), c.Container.Name, t.ExitCode, limit, c.Container.Name))
+			continue
+		}
+		findings = append(findings, fmt.Sprintf("%s: container %s exits with %d, killed with SIGKILL — likely OOMKilled (it has %s) or killed by a failed liveness probe; check the node's kernel log for the OOM killer, or the events for probe failures",
+			describeCrashState(c.Status), c.Container.Name, t.ExitCode, limit))
+	}
+	return findings, nil
+}
+
+func diagnoseCrashLoop(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		if getWaitingReason(c.Status) != "CrashLoopBackOff" && !(c.Status.State.Terminated != nil && c.Status.State.Terminated.ExitCode != 0) {
+			continue
+		}
+		t := getLastFailure(c.Status)
+		if t == nil {
+			continue
+		}
+		finding := fmt.Sprintf("%s: container %s exits with %d", describeCrashState(c.Status), c.Container.Name, t.ExitCode)
+		signal, _ := getTerminationSignal(t)
+		switch {
+		case signal == 9:
+			// the oom rule covers SIGKILL
+			continue
+		case signal != 0:
+			hint := getSignalHint(t)
+			if hint == "" {
+				hint = fmt.Sprintf("died of %s", getSignalName(signal))
+			}
+			finding += fmt.Sprintf(" — it %s", hint)
+		case t.ExitCode == 126:
+			finding += " — the command can't be run; check that the command and args name an executable file in the image, and its permissions"
+		case t.ExitCode == 127:
+			finding += fmt.Sprintf(" — the command wasn't found; check the command and args of container %s against the image's entrypoint and PATH", c.Container.Name)
+		default:
+			finding += " — the application is failing on its own; its logs (below) usually say why: missing config or env vars, a bad argument, or a dependency it can't reach"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+func diagnoseImagePull(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		reason := getWaitingReason(c.Status)
+		switch reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+		default:
+			continue
+		}
+		message := strings.ToLower(c.Status.State.Waiting.Message)
+
+		finding := fmt.Sprintf("%s: container %s can't pull %s", reason, c.Container.Name, c.Container.Image)
+		switch {
+		case reason == "InvalidImageName":
+			finding += " — the image reference isn't valid; fix the image name"
+		case reason == "ErrImageNeverPull":
+			finding += " — imagePullPolicy is Never and the image isn't on the node; load it onto the node, or change the pull policy"
+		case strings.Contains(message, "not found") || strings.Contains(message, "manifest unknown"):
+			finding += " — the image or tag doesn't exist; check the repository name and tag"
+		case strings.Contains(message, "unauthorized") || strings.Contains(message, "denied") || strings.Contains(message, "authentication required") || strings.Contains(message, "403"):
+			finding += " — the registry refused the credentials; add or fix the pod's imagePullSecrets"
+		case strings.Contains(message, "toomanyrequests") || strings.Contains(message, "rate limit"):
+			finding += " — the registry is rate limiting pulls; authenticate the pulls, or use a mirror"
+		case strings.Contains(message, "timeout") || strings.Contains(message, "no such host") || strings.Contains(message, "connection refused") || strings.Contains(message, "i/o"):
+			finding += " — the node can't reach the registry; check its DNS, proxy and egress to the registry"
+		default:
+			finding += " — see the waiting message and events for the registry's error"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+func diagnoseConfigError(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		reason := getWaitingReason(c.Status)
+		switch reason {
+		case "CreateContainerConfigError", "CreateContainerError", "RunContainerError":
+		default:
+			continue
+		}
+		message := c.Status.State.Waiting.Message
+
+		finding := fmt.Sprintf("%s: container %s: %s", reason, c.Container.Name, message)
+		lower := strings.ToLower(message)
+		switch {
+		case strings.Contains(lower, "secret") && strings.Contains(lower, "not found"):
+			finding += " — create the secret, or fix the reference to it in the env or envFrom of the container"
+		case strings.Contains(lower, "configmap") && strings.Contains(lower, "not found"):
+			finding += " — create the ConfigMap, or fix the reference to it in the env or envFrom of the container"
+		case strings.Contains(lower, "couldn't find key"):
+			finding += " — add the key, or fix the key name in the container's env"
+		case strings.Contains(lower, "runasnonroot"):
+			finding += " — set runAsUser to a non-zero UID, or use an image that doesn't run as root"
+		case strings.Contains(lower, "executable file not found") || strings.Contains(lower, "no such file or directory"):
+			finding += " — the command isn't in the image; check the command and args against the image's entrypoint"
+		default:
+			finding += " — the container runtime rejected the container; fix the spec as the message says"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+func diagnoseUnschedulable(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	if !isPodUnschedulable(pod) {
+		return nil, nil
+	}
+
+	message := ""
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled {
+			message = condition.Message
+		}
+	}
+	lower := strings.ToLower(message)
+
+	findings := []string{}
+	add := func(hint string) {
+		findings = append(findings, fmt.Sprintf("Unschedulable: %s — %s", message, hint))
+	}
+	switch {
+	case strings.Contains(lower, "insufficient"):
+		add("no node has enough free resources for the pod's requests; lower the requests, free up capacity, or add nodes")
+	case strings.Contains(lower, "unbound immediate persistentvolumeclaims") || strings.Contains(lower, "persistentvolumeclaim"):
+		add("a PersistentVolumeClaim isn't bound; check that it and its StorageClass exist, and that a volume can be provisioned")
+	case strings.Contains(lower, "untolerated taint") || strings.Contains(lower, "taint"):
+		add("the nodes that would fit are tainted; add a toleration, or schedule the pod elsewhere")
+	case strings.Contains(lower, "volume node affinity"):
+		add("the pod's volume is in a zone none of the schedulable nodes are in")
+	case strings.Contains(lower, "node affinity") || strings.Contains(lower, "node selector"):
+		add("no node matches the pod's nodeSelector or node affinity; fix the selector, or label a node to match")
+	case strings.Contains(lower, "pod affinity") || strings.Contains(lower, "anti-affinity"):
+		add("the pod's (anti-)affinity rules rule out every node; relax them, or add nodes")
+	default:
+		add("see the scheduler's message for what rules each node out")
+	}
+	return findings, nil
+}
+
+func diagnoseProbeFailures(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return nil, err
+	}
+	byContainer, _ := groupContainerEvents(events, pod)
+
+	findings := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		if c.Init || c.Status.Ready || c.Status.State.Running == nil {
+			continue
+		}
+		for _, event := range byContainer[c.Container.Name] {
+			if event.Reason == "Unhealthy" && strings.HasPrefix(event.Message, "Readiness probe failed") {
+				findings = append(findings, fmt.Sprintf("NotReady: container %s is running but failing its readiness probe (%s) — check that the probe's port and path match what the app serves, and that its timeoutSeconds allows for the app's response time",
+					c.Container.Name, strings.TrimSpace(strings.TrimPrefix(event.Message, "Readiness probe failed:"))))
+				break
+			}
+		}
+		if kills := countProbeKills(byContainer[c.Container.Name], "liveness"); kills > 0 {
+			findings = append(findings, fmt.Sprintf("Restarting: container %s was restarted %d times by its liveness probe — see Startup Probes for whether it was still starting; otherwise the app hung, or the probe is too strict", c.Container.Name, kills))
+		}
+	}
+	return findings, nil
+}
+
+func diagnoseMountFailures(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		if event.Reason != "FailedMount" && event.Reason != "FailedAttachVolume" {
+			continue
+		}
+		if seen[event.Message] {
+			continue
+		}
+		seen[event.Message] = true
+
+		finding := fmt.Sprintf("%s: %s", event.Reason, event.Message)
+		lower := strings.ToLower(event.Message)
+		switch {
+		case strings.Contains(lower, "not found"):
+			finding += " — create the secret, ConfigMap or claim the volume refers to, or fix the volume's reference"
+		case strings.Contains(lower, "multi-attach"):
+			finding += " — the volume is still attached to another node, usually by a pod that's being replaced; wait for it to detach, or use a ReadWriteMany volume"
+		case strings.Contains(lower, "timed out"):
+			finding += " — the volume couldn't be attached in time; check the CSI driver's pods and the volume in the cloud provider"
+		default:
+			finding += " — check the volume's source, and the CSI driver if there is one"
+		}
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// getDiagnosis runs the diagnosis rules against a pod, and lists the likely causes of its
+// problems along with suggested fixes.  Healthy pods get no section.
+func (dp *podInspectCommand) getDiagnosis(pod *v1.Pod) (string, error) {
+	if !dp.diagnose {
+		return "", nil
+	}
+
+	rules, err := selectRules(diagnosisRules, dp.diagnosisRules)
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := dp.runRules(rules, pod)
+	if err != nil || len(rows) == 0 {
+		return "", err
+	}
+
+	retval := aurora.Cyan("Diagnosis:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Rule").String(),
+		aurora.Yellow("Likely Cause and Fix").String(),
+	})
+	for _, row := range rows {
+		tw.Append(row)
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// lines of unchanged context around each change in -o diff, as in diff -u
+const diffContextLines = 3
+
+// diffOp is a line of a diff: kept (' '), removed ('-') or added ('+'), with the number of lines
+// of each side that come before it.
+type diffOp struct {
+	kind  byte
+	line  string
+	aLine int
+	bLine int
+}
+
+// diffLines matches up the lines of a and b by their longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitDiffLines splits text into lines, without the empty line after its final newline.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// unifiedDiff renders the changes from a to b as a unified diff, or "" if there are none.
+func unifiedDiff(fromName, toName, a, b string) string {
+	ops := diffLines(splitDiffLines(a), splitDiffLines(b))
+
+	sb := &strings.Builder{}
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// a hunk runs until the changes are more than twice the context apart
+		last := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				last = j
+			} else if j-last > 2*diffContextLines {
+				break
+			}
+		}
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := last + 1 + diffContextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		// an empty side is numbered by the line it comes after
+		aStart, bStart := ops[start].aLine+1, ops[start].bLine+1
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(sb, "--- %s\n+++ %s\n", fromName, toName)
+		}
+		fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(sb, "%c%s\n", op.kind, op.line)
+		}
+
+		i = end
+	}
+	return sb.String()
+}
+
+// pruneToApplied returns the parts of the live object that were applied, walking it as
+// compareApplied does: fields that weren't applied are left out, named list items are put in the
+// applied order, and values the API server only normalized are given as applied, so that a diff
+// against the applied object shows just the drift.
+func pruneToApplied(applied, live interface{}) interface{} {
+	switch a := applied.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := map[string]interface{}{}
+		for k, av := range a {
+			if lv, ok := l[k]; ok && av != nil {
+				pruned[k] = pruneToApplied(av, lv)
+			}
+		}
+		return pruned
+
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		if names, ok := listItemNames(a); ok {
+			if liveNames, ok := listItemNames(l); ok {
+				liveItems := map[string]interface{}{}
+				for i, name := range liveNames {
+					liveItems[name] = l[i]
+				}
+				pruned := []interface{}{}
+				for i, name := range names {
+					if lv, ok := liveItems[name]; ok {
+						pruned = append(pruned, pruneToApplied(a[i], lv))
+					}
+				}
+				return pruned
+			}
+		}
+		if len(a) != len(l) {
+			return live
+		}
+		pruned := make([]interface{}, len(l))
+		for i := range l {
+			pruned[i] = pruneToApplied(a[i], l[i])
+		}
+		return pruned
+
+	default:
+		if driftValuesEqual(applied, live) {
+			return applied
+		}
+		return live
+	}
+}
+
+// getAppliedDiff renders the drift of an object from its last-applied configuration as a diff of
+// the two in normalized YAML; the live object is cut down to the fields that were applied.
+func getAppliedDiff(namespace string, obj appliedObject) (string, error) {
+	var applied, live map[string]interface{}
+	if err := json.Unmarshal([]byte(obj.last), &applied); err != nil {
+		return "", fmt.Errorf("%s: unparseable %s annotation: %v", obj.label, lastAppliedAnnotation, err)
+	}
+	if err := json.Unmarshal(obj.raw, &live); err != nil {
+		return "", err
+	}
+	delete(applied, "status")
+
+	// pruning the applied object against itself drops its nulls, as compareApplied skips them
+	appliedYAML, err := yaml.Marshal(pruneToApplied(applied, applied))
+	if err != nil {
+		return "", err
+	}
+	liveYAML, err := yaml.Marshal(pruneToApplied(applied, live))
+	if err != nil {
+		return "", err
+	}
+
+	name := namespace + "/" + obj.label
+	return unifiedDiff(name+" (last applied)", name+" (live)", string(appliedYAML), string(liveYAML)), nil
+}
+
+// settingsYAML renders the settings with the given keys, as returned by getComparableSettings,
+// as YAML grouped by kind.
+func settingsYAML(settings map[string]string, keys []string) (string, error) {
+	grouped := map[string]map[string]string{}
+	for _, key := range keys {
+		value, ok := settings[key]
+		if !ok {
+			continue
+		}
+		parts := strings.SplitN(key, "\x00", 2)
+		if grouped[parts[0]] == nil {
+			grouped[parts[0]] = map[string]string{}
+		}
+		grouped[parts[0]][parts[1]] = value
+	}
+	if len(grouped) == 0 {
+		return "", nil
+	}
+
+	data, err := yaml.Marshal(grouped)
+	return string(data), err
+}
+
+// getContainerDiff diffs the env and mounts of the pod's first container against each of the
+// others, on the settings shared by some of them, as the containers comparison shows them.
+func getContainerDiff(pod *v1.Pod) (string, error) {
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	if len(containers) < 2 {
+		return "", nil
+	}
+
+	columns := []map[string]string{}
+	for _, c := range containers {
+		columns = append(columns, getComparableSettings(c))
+	}
+	keys := getInconsistentSettings(columns, 2)
+
+	first, err := settingsYAML(columns[0], keys)
+	if err != nil {
+		return "", err
+	}
+
+	retval := ""
+	for i, c := range containers[1:] {
+		other, err := settingsYAML(columns[i+1], keys)
+		if err != nil {
+			return "", err
+		}
+		name := fmt.Sprintf("%s/Pod/%s container ", pod.Namespace, pod.Name)
+		retval += unifiedDiff(name+containers[0].Name, name+c.Name, first, other)
+	}
+	return retval, nil
+}
+
+// getReplicaDiff diffs the env and mounts of the pod against each group of the workload's other
+// replicas whose settings differ from its own, on the settings that differ between any of them.
+func (dp *podInspectCommand) getReplicaDiff(pod *v1.Pod) (string, error) {
+	pods, workload, err := dp.getWorkloadPods(pod)
+	if err != nil || workload == nil {
+		return "", err
+	}
+
+	// each replica's settings, by container, cut down to those that aren't the same everywhere
+	rendered := map[string]map[string]map[string]string{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		columns := []map[string]string{}
+		podNames := []string{}
+		for _, p := range pods {
+			for _, pc := range append(append([]v1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+				if pc.Name == c.Name {
+					columns = append(columns, getComparableSettings(pc))
+					podNames = append(podNames, p.Name)
+				}
+			}
+		}
+
+		keys := getInconsistentSettings(columns, 1)
+		for i, column := range columns {
+			if rendered[podNames[i]] == nil {
+				rendered[podNames[i]] = map[string]map[string]string{}
+			}
+			for _, key := range keys {
+				if value, ok := column[key]; ok {
+					if rendered[podNames[i]][c.Name] == nil {
+						rendered[podNames[i]][c.Name] = map[string]string{}
+					}
+					rendered[podNames[i]][c.Name][strings.Replace(key, "\x00", " ", 1)] = value
+				}
+			}
+		}
+	}
+
+	// group the replicas by their settings
+	byText := map[string][]string{}
+	for name, settings := range rendered {
+		text := ""
+		if len(settings) > 0 {
+			data, err := yaml.Marshal(settings)
+			if err != nil {
+				return "", err
+			}
+			text = string(data)
+		}
+		byText[text] = append(byText[text], name)
+	}
+
+	own := ""
+	for text, names := range byText {
+		for _, name := range names {
+			if name == pod.Name {
+				own = text
+			}
+		}
+	}
+
+	texts := make([]string, 0, len(byText))
+	for text := range byText {
+		if text != own {
+			texts = append(texts, text)
+		}
+	}
+	sort.Strings(texts)
+
+	retval := ""
+	for _, text := range texts {
+		names := byText[text]
+		sort.Strings(names)
+		shown := strings.Join(names, ", ")
+		if len(names) > maxComparePodNames {
+			shown = fmt.Sprintf("%s and %d more", strings.Join(names[:maxComparePodNames], ", "), len(names)-maxComparePodNames)
+		}
+		retval += unifiedDiff(
+			fmt.Sprintf("%s/Pod/%s (this pod)", pod.Namespace, pod.Name),
+			fmt.Sprintf("%s/%s/%s replicas %s", pod.Namespace, workload.Kind, workload.Name, shown),
+			own, text)
+	}
+	return retval, nil
+}
+
+// getPodDiffs renders, for -o diff, the pod's drift from its last-applied configuration and, with
+// --compare, the differences between its containers or replicas.  Objects in seen, such as a
+// controller shared with a pod already shown, are left out.
+func (dp *podInspectCommand) getPodDiffs(pod *v1.Pod, rawPod []byte, seen map[string]bool) (string, error) {
+	retval := ""
+
+	objects, err := dp.getAppliedObjects(pod, rawPod)
+	if err != nil {
+		return "", err
+	}
+	for _, obj := range objects {
+		key := pod.Namespace + "/" + obj.label
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		diff, err := getAppliedDiff(pod.Namespace, obj)
+		if err != nil {
+			return "", err
+		}
+		retval += diff
+	}
+
+	switch dp.compare {
+	case "containers":
+		diff, err := getContainerDiff(pod)
+		if err != nil {
+			return "", err
+		}
+		retval += diff
+	case "replicas":
+		diff, err := dp.getReplicaDiff(pod)
+		if err != nil {
+			return "", err
+		}
+		retval += diff
+	}
+
+	return retval, nil
+}
+
+// printDiffs prints the diffs of the named pod or, without a name, of every pod that passes the
+// filters.  Nothing is printed for pods that have neither drifted nor differ.
+func (dp *podInspectCommand) printDiffs(args []string) error {
+	seen := map[string]bool{}
+
+	if len(args) == 1 {
+		pod, rawPod, err := dp.getPod(args[0])
+		if err != nil {
+			return err
+		}
+		diffs, err := dp.getPodDiffs(pod, rawPod, seen)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(dp.out, diffs)
+		return err
+	}
+
+	return dp.forEachPod(func(pod *v1.Pod) error {
+		// the listed pod stands in for the raw one a GET would return
+		rawPod, err := json.Marshal(pod)
+		if err != nil {
+			return err
+		}
+		diffs, err := dp.getPodDiffs(pod, rawPod, seen)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(dp.out, diffs)
+		return err
+	})
+}
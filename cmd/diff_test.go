@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", ""},
+		{"empty", "", "", ""},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "--- from\n+++ to\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "added to nothing",
+			a:    "",
+			b:    "a\nb\n",
+			want: "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "context cut short",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "1\n2\n3\n4\n5\n6\n7\nX\n",
+			want: "--- from\n+++ to\n@@ -5,4 +5,4 @@\n 5\n 6\n 7\n-8\n+X\n",
+		},
+		{
+			name: "distant changes in separate hunks",
+			a:    "A\n2\n3\n4\n5\n6\n7\n8\n9\n10\nK\n",
+			b:    "a\n2\n3\n4\n5\n6\n7\n8\n9\n10\nk\n",
+			want: "--- from\n+++ to\n@@ -1,4 +1,4 @@\n-A\n+a\n 2\n 3\n 4\n@@ -8,4 +8,4 @@\n 8\n 9\n 10\n-K\n+k\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("from", "to", tt.a, tt.b); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAppliedDiff(t *testing.T) {
+	obj := appliedObject{
+		label: "Deployment/web",
+		last:  `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","labels":null},"spec":{"replicas":2,"template":{"spec":{"containers":[{"name":"app","image":"web:1","resources":{"limits":{"cpu":"0.5"}}},{"name":"proxy","image":"envoy:1"}]}}}}`,
+		raw:   []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","uid":"1234","generation":5},"spec":{"replicas":5,"template":{"spec":{"containers":[{"name":"proxy","image":"envoy:1"},{"name":"app","image":"web:1","imagePullPolicy":"IfNotPresent","resources":{"limits":{"cpu":"500m"}}}]}}},"status":{"replicas":5}}`),
+	}
+
+	got, err := getAppliedDiff("default", obj)
+	if err != nil {
+		t.Fatalf("getAppliedDiff() error = %v", err)
+	}
+
+	// only the manual scale shows: defaulted fields, normalized quantities and the order the
+	// containers come back in are left out
+	want := "--- default/Deployment/web (last applied)\n+++ default/Deployment/web (live)\n" +
+		"@@ -3,7 +3,7 @@\n" +
+		" metadata:\n" +
+		"   name: web\n" +
+		" spec:\n" +
+		"-  replicas: 2\n" +
+		"+  replicas: 5\n" +
+		"   template:\n" +
+		"     spec:\n" +
+		"       containers:\n"
+	if got != want {
+		t.Errorf("getAppliedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	obj.raw = []byte(obj.last)
+	if got, err := getAppliedDiff("default", obj); err != nil || got != "" {
+		t.Errorf("getAppliedDiff() of an unchanged object = %q, %v, want no diff", got, err)
+	}
+}
+
+func TestGetContainerDiff(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-0"},
+		Spec: v1.PodSpec{Containers: []v1.Container{
+			{Name: "app", Env: []v1.EnvVar{{Name: "LOG_LEVEL", Value: "info"}, {Name: "APP_ONLY", Value: "1"}}},
+			{Name: "sidecar", Env: []v1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}}},
+		}},
+	}
+
+	got, err := getContainerDiff(pod)
+	if err != nil {
+		t.Fatalf("getContainerDiff() error = %v", err)
+	}
+	want := "--- default/Pod/web-0 container app\n+++ default/Pod/web-0 container sidecar\n" +
+		"@@ -1,2 +1,2 @@\n" +
+		" env:\n" +
+		"-  LOG_LEVEL: '\"info\"'\n" +
+		"+  LOG_LEVEL: '\"debug\"'\n"
+	if got != want {
+		t.Errorf("getContainerDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "APP_ONLY") {
+		t.Errorf("getContainerDiff() compares a setting only one container has:\n%s", got)
+	}
+}
+
+func TestValidateOutputFormatDiff(t *testing.T) {
+	if err := validateOutputFormat("diff"); err != nil {
+		t.Errorf("validateOutputFormat(diff) error = %v", err)
+	}
+	if err := validateOutputFormat("diff=x"); err == nil {
+		t.Errorf("validateOutputFormat(diff=x) error = nil, want an error")
+	}
+}
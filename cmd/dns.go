@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the cluster domain is a kubelet setting that isn't visible through the API; nearly every
+// cluster keeps the default
+const defaultClusterDomain = "cluster.local"
+
+// getPodHostname returns the hostname the pod's containers see: spec.hostname, or else the pod
+// name.
+func getPodHostname(pod *v1.Pod) string {
+	if pod.Spec.Hostname != "" {
+		return pod.Spec.Hostname
+	}
+	return pod.Name
+}
+
+// labelsMatch reports whether a Service selector selects a pod with the given labels.
+func labelsMatch(selector map[string]string, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatLabelSelector renders a Service selector as kubectl would write it, e.g. "app=db,tier=data".
+func formatLabelSelector(selector map[string]string) string {
+	if len(selector) == 0 {
+		return "empty"
+	}
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// isPodReady reports whether the pod's Ready condition is true, which is what endpoints follow.
+func isPodReady(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getPodDNS shows the pod's hostname and subdomain, and the DNS name they give it under its
+// headless Service, then checks that the Service will actually publish a record for it.  Peers
+// of a StatefulSet find each other by these names, so this is the first thing to look at when
+// peer discovery fails.
+func (dp *podInspectCommand) getPodDNS(pod *v1.Pod) (string, error) {
+	if pod.Spec.Hostname == "" && pod.Spec.Subdomain == "" {
+		return "", nil
+	}
+
+	retval := aurora.Cyan("DNS:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	hostname := getPodHostname(pod)
+	tw.Append([]string{aurora.Yellow("Hostname").String(), hostname})
+
+	if pod.Spec.Subdomain == "" {
+		tw.Append([]string{aurora.Yellow("Subdomain").String(), "none"})
+		tw.Render()
+		retval += sb.String()
+		retval += fmt.Sprintf("\n%s  without a subdomain, the pod gets no DNS name of its own; set spec.subdomain to the name of a headless Service\n", aurora.Yellow("…").String())
+		return retval, nil
+	}
+
+	fqdn := fmt.Sprintf("%s.%s.%s.svc.%s", hostname, pod.Spec.Subdomain, pod.Namespace, defaultClusterDomain)
+	tw.Append([]string{aurora.Yellow("Subdomain").String(), pod.Spec.Subdomain})
+	tw.Append([]string{aurora.Yellow("DNS name").String(), fmt.Sprintf("%s (assuming the default cluster domain)", fqdn)})
+	if pod.Spec.SetHostnameAsFQDN != nil && *pod.Spec.SetHostnameAsFQDN {
+		tw.Append([]string{aurora.Yellow("Kernel hostname").String(), "set to the DNS name (setHostnameAsFQDN)"})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	problem := ""
+	svc, err := dp.clientset.CoreV1().Services(pod.Namespace).Get(context.Background(), pod.Spec.Subdomain, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		problem = fmt.Sprintf("there is no Service %s in namespace %s; the DNS name won't resolve", pod.Spec.Subdomain, pod.Namespace)
+	case err != nil:
+		return "", err
+	case svc.Spec.ClusterIP != v1.ClusterIPNone:
+		problem = fmt.Sprintf("Service %s isn't headless (its clusterIP is %s); records for individual pods are only published for headless Services", svc.Name, svc.Spec.ClusterIP)
+	case len(svc.Spec.Selector) == 0 || !labelsMatch(svc.Spec.Selector, pod.Labels):
+		problem = fmt.Sprintf("Service %s doesn't select this pod (its selector is %s); the DNS name won't resolve", svc.Name, formatLabelSelector(svc.Spec.Selector))
+	}
+
+	if problem != "" {
+		retval += fmt.Sprintf("\n%s  %s\n", aurora.Red("✖").String(), problem)
+	} else if !isPodReady(pod) && !svc.Spec.PublishNotReadyAddresses {
+		retval += fmt.Sprintf("\n%s  the pod isn't ready, and Service %s doesn't publish not-ready addresses; the name won't resolve until the pod is ready\n", aurora.Yellow("…").String(), svc.Name)
+	} else {
+		retval += fmt.Sprintf("\n%s  Service %s is headless and selects the pod; the name should resolve\n", aurora.Green("✔").String(), svc.Name)
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// longest value shown in the drift table before it is cut short
+const maxDriftValueLength = 60
+
+// appliedDrift is a field whose live value no longer matches what was last applied.
+type appliedDrift struct {
+	Path    string
+	Applied interface{}
+	Live    interface{}
+	Missing bool
+}
+
+// appliedObject is the pod or one of its controllers, with its last-applied configuration.
+type appliedObject struct {
+	label string
+	raw   []byte
+	last  string
+}
+
+// getAppliedObjects returns the pod and those of its controllers that have a
+// last-applied-configuration annotation.
+func (dp *podInspectCommand) getAppliedObjects(pod *v1.Pod, rawPod []byte) ([]appliedObject, error) {
+	objects := []appliedObject{}
+	if last, ok := pod.Annotations[lastAppliedAnnotation]; ok {
+		objects = append(objects, appliedObject{label: "Pod/" + pod.Name, raw: rawPod, last: last})
+	}
+
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil {
+		return nil, err
+	}
+	for _, owner := range chain {
+		if last, ok := owner.Metadata.Annotations[lastAppliedAnnotation]; ok {
+			objects = append(objects, appliedObject{label: owner.Kind + "/" + owner.Name, raw: owner.Raw, last: last})
+		}
+	}
+
+	return objects, nil
+}
+
+// getLastAppliedDrift compares the pod and each of its controllers against their
+// last-applied-configuration annotations and reports the fields that have since changed.  Those
+// are usually manual edits (kubectl edit, patch, scale, set image) that the manifests in source
+// control don't know about, which explains many a "this pod behaves differently".
+func (dp *podInspectCommand) getLastAppliedDrift(pod *v1.Pod, rawPod []byte) (string, error) {
+	retval := ""
+
+	objects, err := dp.getAppliedObjects(pod, rawPod)
+	if err != nil || len(objects) == 0 {
+		return "", err
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Object").String(),
+		aurora.Yellow("Field").String(),
+		aurora.Yellow("Last Applied").String(),
+		aurora.Yellow("Live").String(),
+	})
+
+	found := false
+	for _, obj := range objects {
+		var applied, live interface{}
+		if err := json.Unmarshal([]byte(obj.last), &applied); err != nil {
+			// a hand-edited annotation shouldn't break the whole report
+			tw.Append([]string{obj.label, "", aurora.Red("unparseable annotation").String(), ""})
+			found = true
+			continue
+		}
+		if err := json.Unmarshal(obj.raw, &live); err != nil {
+			return "", err
+		}
+
+		drift := []appliedDrift{}
+		compareApplied("", applied, live, &drift)
+
+		for _, d := range drift {
+			liveValue := formatDriftValue(d.Live)
+			if d.Missing {
+				liveValue = aurora.Red("(removed)").String()
+			}
+			tw.Append([]string{obj.label, d.Path, formatDriftValue(d.Applied), liveValue})
+			found = true
+		}
+	}
+
+	if !found {
+		return "", nil
+	}
+
+	tw.Render()
+
+	retval += aurora.Cyan("Drift From Last Applied Configuration:\n\n").String()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+// compareApplied walks the last-applied configuration and records each field whose live value
+// differs.  Only fields that were applied are compared: anything else in the live object may just
+// be a default filled in by the API server, so it can't be told apart from a manual addition.
+func compareApplied(path string, applied, live interface{}, drift *[]appliedDrift) {
+	switch a := applied.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			*drift = append(*drift, appliedDrift{Path: path, Applied: applied, Live: live})
+			return
+		}
+
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if path == "" && k == "status" {
+				continue
+			}
+			if a[k] == nil {
+				continue
+			}
+
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+
+			lv, ok := l[k]
+			if !ok {
+				*drift = append(*drift, appliedDrift{Path: childPath, Applied: a[k], Missing: true})
+				continue
+			}
+			compareApplied(childPath, a[k], lv, drift)
+		}
+
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			*drift = append(*drift, appliedDrift{Path: path, Applied: applied, Live: live})
+			return
+		}
+
+		// lists of named things (containers, env, ports, volumes, ...) are matched up by name,
+		// so that a reordering isn't reported and the path says which item changed
+		if names, ok := listItemNames(a); ok {
+			if liveNames, ok := listItemNames(l); ok {
+				liveItems := map[string]interface{}{}
+				for i, name := range liveNames {
+					liveItems[name] = l[i]
+				}
+				for i, name := range names {
+					itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+					lv, ok := liveItems[name]
+					if !ok {
+						*drift = append(*drift, appliedDrift{Path: itemPath, Applied: a[i], Missing: true})
+						continue
+					}
+					compareApplied(itemPath, a[i], lv, drift)
+				}
+				return
+			}
+		}
+
+		if len(a) != len(l) {
+			*drift = append(*drift, appliedDrift{Path: path, Applied: applied, Live: live})
+			return
+		}
+		for i := range a {
+			compareApplied(fmt.Sprintf("%s[%d]", path, i), a[i], l[i], drift)
+		}
+
+	default:
+		if !driftValuesEqual(applied, live) {
+			*drift = append(*drift, appliedDrift{Path: path, Applied: applied, Live: live})
+		}
+	}
+}
+
+// listItemNames returns the "name" of each item if every item in the list is an object with one.
+func listItemNames(list []interface{}) ([]string, bool) {
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		names = append(names, name)
+	}
+	return names, len(names) > 0
+}
+
+// driftValuesEqual compares two scalars, treating resource quantities that the API server
+// normalized (e.g. cpu "0.5" stored as "500m") as equal.
+func driftValuesEqual(applied, live interface{}) bool {
+	if reflect.DeepEqual(applied, live) {
+		return true
+	}
+
+	a, err := resource.ParseQuantity(fmt.Sprintf("%v", applied))
+	if err != nil {
+		return false
+	}
+	l, err := resource.ParseQuantity(fmt.Sprintf("%v", live))
+	if err != nil {
+		return false
+	}
+	return a.Equal(l)
+}
+
+func formatDriftValue(value interface{}) string {
+	var s string
+	if str, ok := value.(string); ok {
+		s = str
+	} else {
+		b, err := json.Marshal(value)
+		if err != nil {
+			s = fmt.Sprintf("%v", value)
+		} else {
+			s = string(b)
+		}
+	}
+
+	if r := []rune(s); len(r) > maxDriftValueLength {
+		s = string(r[:maxDriftValueLength-3]) + "..."
+	}
+	return s
+}
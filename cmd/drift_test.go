@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareApplied(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied string
+		live    string
+		want    []string
+	}{
+		{
+			name:    "unchanged",
+			applied: `{"spec": {"replicas": 2}}`,
+			live:    `{"spec": {"replicas": 2, "revisionHistoryLimit": 10}, "status": {"replicas": 2}}`,
+			want:    []string{},
+		},
+		{
+			name:    "scalar changed",
+			applied: `{"spec": {"replicas": 2}}`,
+			live:    `{"spec": {"replicas": 5}}`,
+			want:    []string{"spec.replicas"},
+		},
+		{
+			name:    "field removed",
+			applied: `{"metadata": {"labels": {"app": "web", "tier": "front"}}}`,
+			live:    `{"metadata": {"labels": {"app": "web"}}}`,
+			want:    []string{"metadata.labels.tier (missing)"},
+		},
+		{
+			name:    "status and nulls ignored",
+			applied: `{"status": {"phase": "Pending"}, "spec": {"nodeName": null}}`,
+			live:    `{"status": {"phase": "Running"}, "spec": {}}`,
+			want:    []string{},
+		},
+		{
+			name:    "normalized quantities",
+			applied: `{"resources": {"cpu": "0.5", "memory": "1Gi"}}`,
+			live:    `{"resources": {"cpu": "500m", "memory": "1024Mi"}}`,
+			want:    []string{},
+		},
+		{
+			name:    "named list items matched by name",
+			applied: `{"containers": [{"name": "app", "image": "app:1"}, {"name": "proxy", "image": "proxy:1"}]}`,
+			live:    `{"containers": [{"name": "proxy", "image": "proxy:1"}, {"name": "app", "image": "app:2"}]}`,
+			want:    []string{"containers[name=app].image"},
+		},
+		{
+			name:    "named list item removed",
+			applied: `{"containers": [{"name": "app"}, {"name": "proxy"}]}`,
+			live:    `{"containers": [{"name": "app"}]}`,
+			want:    []string{"containers[name=proxy] (missing)"},
+		},
+		{
+			name:    "unnamed list compared by index",
+			applied: `{"args": ["--port", "8080"]}`,
+			live:    `{"args": ["--port", "9090"]}`,
+			want:    []string{"args[1]"},
+		},
+		{
+			name:    "unnamed list length changed",
+			applied: `{"args": ["--port", "8080"]}`,
+			live:    `{"args": ["--port"]}`,
+			want:    []string{"args"},
+		},
+		{
+			name:    "type changed",
+			applied: `{"spec": {"selector": {"app": "web"}}}`,
+			live:    `{"spec": {"selector": "app=web"}}`,
+			want:    []string{"spec.selector"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied, live interface{}
+			if err := json.Unmarshal([]byte(tt.applied), &applied); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.live), &live); err != nil {
+				t.Fatal(err)
+			}
+
+			drift := []appliedDrift{}
+			compareApplied("", applied, live, &drift)
+
+			got := []string{}
+			for _, d := range drift {
+				path := d.Path
+				if d.Missing {
+					path += " (missing)"
+				}
+				got = append(got, path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDriftValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"string", "nginx:1.19", "nginx:1.19"},
+		{"number", float64(3), "3"},
+		{"object", map[string]interface{}{"app": "web"}, `{"app":"web"}`},
+		{"long", strings.Repeat("x", 100), strings.Repeat("x", maxDriftValueLength-3) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDriftValue(tt.value); got != tt.want {
+				t.Errorf("formatDriftValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// apiCall describes one kind of request the plugin makes against the API server.
+type apiCall struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Namespace   string
+	When        string
+}
+
+func (c apiCall) resourceString() string {
+	r := c.Resource
+	if c.Group != "" {
+		r = fmt.Sprintf("%s.%s", c.Resource, c.Group)
+	}
+	if c.Subresource != "" {
+		r = fmt.Sprintf("%s/%s", r, c.Subresource)
+	}
+	return r
+}
+
+// plannedAPICalls lists the API requests a run with the given arguments would make.  Which of
+// the per-pod requests actually happen depends on what's found on each pod.
+func (dp *podInspectCommand) plannedAPICalls(args []string) []apiCall {
+	calls := []apiCall{}
+
+	if len(args) == 1 {
+		calls = append(calls, apiCall{Verb: "get", Resource: "pods", Namespace: dp.namespace, When: fmt.Sprintf("fetch pod %s", args[0])})
+		if dp.postMortem {
+			calls = append(calls, apiCall{Verb: "list", Resource: "events", Namespace: dp.namespace, When: "if the pod doesn't exist any more, find its traces in the namespace's events (--post-mortem)"})
+		}
+	} else if dp.podIP != "" {
+		calls = append(calls,
+			apiCall{Verb: "list", Resource: "pods", Namespace: dp.namespace, When: fmt.Sprintf("find the pod with IP %s (field selector status.podIP=%s)", dp.podIP, dp.podIP)},
+			apiCall{Verb: "get", Resource: "pods", Namespace: dp.namespace, When: "fetch the pod"},
+		)
+	} else if dp.uid != "" {
+		calls = append(calls,
+			apiCall{Verb: "list", Resource: "pods", Namespace: dp.listNamespace(), When: fmt.Sprintf("find the pod with UID %s (matched client-side)", dp.uid)},
+			apiCall{Verb: "list", Resource: "events", Namespace: dp.listNamespace(), When: "find a deleted pod with the UID by its events"},
+			apiCall{Verb: "get", Resource: "pods", Namespace: dp.namespace, When: "fetch the pod"},
+		)
+	} else {
+		if dp.owner != "" {
+			kind, name, _ := parseWorkloadName(dp.owner)
+			r := ownerResources[kind]
+			calls = append(calls, apiCall{Verb: "get", Group: r.group, Resource: r.resource, Namespace: dp.namespace, When: fmt.Sprintf("fetch %s %s", kind, name)})
+		}
+		when := "find the pods to inspect"
+		if dp.owner != "" {
+			when = fmt.Sprintf("%s (those controlled by %s, filtered client-side)", when, dp.owner)
+		}
+		if dp.selector != "" {
+			when = fmt.Sprintf("%s (label selector %s)", when, dp.selector)
+		}
+		if selector := dp.podListFieldSelector(); selector != "" {
+			when = fmt.Sprintf("%s (field selector %s)", when, selector)
+		}
+		if dp.namePattern != "" {
+			when = fmt.Sprintf("%s (names matching %s, filtered client-side)", when, dp.namePattern)
+		}
+		if dp.nameRegex != "" {
+			when = fmt.Sprintf("%s (names matching /%s/, filtered client-side)", when, dp.nameRegex)
+		}
+		calls = append(calls,
+			apiCall{Verb: "list", Resource: "pods", Namespace: dp.namespace, When: when},
+			apiCall{Verb: "get", Resource: "pods", Namespace: dp.namespace, When: "fetch each pod"},
+		)
+	}
+
+	if dp.watch {
+		calls = append(calls,
+			apiCall{Verb: "watch", Resource: "pods", Namespace: dp.listNamespace(), When: "keep the pods up to date for --watch, after listing them once"},
+			apiCall{Verb: "watch", Resource: "events", Namespace: dp.listNamespace(), When: "keep the pod events up to date for --watch, after listing them once"},
+		)
+	}
+
+	logsWhen := "for each container that isn't ok"
+	if dp.allLogs {
+		logsWhen = "for each container"
+	}
+	if dp.followLogs {
+		logsWhen += ", then streamed for each container that isn't ready"
+	}
+	eventsWhen := "for each pod"
+	if len(args) == 0 && dp.podIP == "" && dp.uid == "" {
+		eventsWhen = "once for the namespace's pod events, indexed by pod (paged)"
+	}
+	calls = append(calls,
+		apiCall{Verb: "list", Resource: "events", Namespace: dp.namespace, When: eventsWhen},
+		apiCall{Verb: "get", Resource: "pods", Subresource: "log", Namespace: dp.namespace, When: logsWhen},
+		apiCall{Verb: "get", Resource: "nodes", When: "for scheduled pods (node taints, GPUs, hugepages)"},
+		apiCall{Verb: "list", Resource: "nodes", When: "for unscheduled pods (scheduling analysis, GPUs)"},
+		apiCall{Verb: "list", Resource: "events", When: "once per node of the pods requesting GPUs or on spot nodes (node events)"},
+	)
+
+	metricsVerb, metricsWhen := "get", "for running pods (container usage, if metrics-server is installed)"
+	if len(args) == 0 && dp.podIP == "" && dp.uid == "" {
+		metricsVerb, metricsWhen = "list", "once for the namespace's running pods (container usage, if metrics-server is installed)"
+	}
+	calls = append(calls, apiCall{Verb: metricsVerb, Group: "metrics.k8s.io", Resource: "pods", Namespace: dp.namespace, When: metricsWhen})
+	secretsWhen := "for pods that can't pull their images (image pull secrets)"
+	if dp.imageSignatures && dp.validatePullSecrets {
+		secretsWhen = "for each pod (image pull secrets, for --image-signatures)"
+	} else if dp.validatePullSecrets {
+		secretsWhen = "for pods that can't pull their images, or whose images fail with a platform mismatch (image pull secrets)"
+	}
+	calls = append(calls, apiCall{Verb: "get", Resource: "secrets", Namespace: dp.namespace, When: secretsWhen})
+	nodePodsWhen := "for pods with restarting containers (node headroom), and unscheduled pods (scheduling analysis)"
+	if dp.nodeAllocation {
+		nodePodsWhen = "for scheduled pods (node allocation, node headroom), and unscheduled pods (scheduling analysis)"
+	}
+	calls = append(calls, apiCall{Verb: "list", Resource: "pods", When: nodePodsWhen})
+	namespacesWhen := "for pods with hostPath volumes (Pod Security level)"
+	if dp.psaDryRun != "" {
+		namespacesWhen = "for each pod (Pod Security level, for --psa-dry-run)"
+	}
+	calls = append(calls, apiCall{Verb: "get", Resource: "namespaces", When: namespacesWhen})
+	claimsWhen := "for pending pods with PersistentVolumeClaims (claim status)"
+	if dp.showVolumes {
+		claimsWhen = "for pods with PersistentVolumeClaims (claim status, for --show-volumes)"
+	}
+	calls = append(calls,
+		apiCall{Verb: "get", Resource: "persistentvolumeclaims", Namespace: dp.namespace, When: claimsWhen},
+		apiCall{Verb: "list", Resource: "events", Namespace: dp.namespace, When: "for unbound PersistentVolumeClaims (claim events)"},
+	)
+	calls = append(calls, apiCall{Verb: "get", Resource: "services", Namespace: dp.namespace, When: "for pods with a subdomain (headless Service DNS)"})
+	calls = append(calls, apiCall{Verb: "get", Resource: "configmaps", Namespace: autoscalerStatusNamespace, When: "for unschedulable pods (cluster-autoscaler status)"})
+
+	calls = append(calls,
+		apiCall{Verb: "get", Group: "karpenter.sh", Resource: "nodeclaims", When: "for unscheduled pods that Karpenter is provisioning for"},
+		apiCall{Verb: "list", Resource: "events", When: "for unscheduled pods that Karpenter is provisioning for (NodeClaim events)"},
+	)
+
+	calls = append(calls, apiCall{Verb: "list", Group: "apps", Resource: "replicasets", Namespace: dp.namespace, When: "for Deployment pods with restarting containers (rollout history; by the Deployment's selector, once per Deployment)"})
+
+	if len(dp.connectivity) > 0 {
+		calls = append(calls, apiCall{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: dp.namespace, When: "for running pods (--connectivity)"})
+	}
+	if dp.fsUsage {
+		calls = append(calls, apiCall{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: dp.namespace, When: "for running containers (--fs-usage)"})
+	}
+
+	if dp.lint {
+		calls = append(calls, apiCall{Verb: "list", Group: "policy", Resource: "poddisruptionbudgets", Namespace: dp.namespace, When: "for single-replica workloads (--lint)"})
+	}
+
+	if dp.compare == "replicas" {
+		calls = append(calls, apiCall{Verb: "list", Resource: "pods", Namespace: dp.namespace, When: "for pods with a controller (--compare=replicas; by the workload's selector, once per workload)"})
+	}
+
+	// the pod's controllers, walked up through its owner references
+	for _, kind := range []string{"ReplicaSet", "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob", "ReplicationController"} {
+		r := ownerResources[kind]
+		calls = append(calls, apiCall{Verb: "get", Group: r.group, Resource: r.resource, Namespace: dp.namespace, When: fmt.Sprintf("for pods controlled by a %s", kind)})
+	}
+
+	// the namespaced requests are made in every namespace
+	if dp.allNamespaces {
+		for i := range calls {
+			if calls[i].Namespace == dp.namespace {
+				calls[i].Namespace = ""
+			}
+		}
+	}
+
+	return calls
+}
+
+// printDryRun prints the API requests that a run would make, without making any of them, so
+// that users can check their RBAC permissions and the scope of a sweep up front.
+func (dp *podInspectCommand) printDryRun(args []string) error {
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Verb").String(),
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Namespace").String(),
+		aurora.Yellow("When").String(),
+	})
+
+	for _, call := range dp.plannedAPICalls(args) {
+		namespace := call.Namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+		tw.Append([]string{
+			call.Verb,
+			call.resourceString(),
+			namespace,
+			call.When,
+		})
+	}
+	tw.Render()
+
+	_, err := fmt.Fprintf(dp.out, "%s\n\n%s", aurora.Cyan("Dry run; these API requests would be made:"), sb.String())
+	return err
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// formatAge renders how long ago a timestamp was, in the same style kubectl uses for its AGE
+// column (e.g. "3d2h", "45m"); zero timestamps render as "n/a".
+func formatAge(t metav1.Time) string {
+	if t.IsZero() {
+		return "n/a"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+// formatTimestamp renders an event time per --time-format: as the time itself, or as how long
+// ago it was.
+func (dp *podInspectCommand) formatTimestamp(t metav1.Time) string {
+	if dp.timeFormat == "relative" {
+		if t.IsZero() {
+			return "n/a"
+		}
+		return formatAge(t) + " ago"
+	}
+	return t.String()
+}
+
+// formatDuration renders the time between two timestamps; if the end is zero, the duration
+// runs up to now.
+func formatDuration(start, end metav1.Time) string {
+	if start.IsZero() {
+		return "n/a"
+	}
+	if end.IsZero() {
+		return duration.HumanDuration(time.Since(start.Time))
+	}
+	return duration.HumanDuration(end.Sub(start.Time))
+}
+
+// a container that has never run and has been waiting longer than this is stuck, not starting
+const stuckWaitingThreshold = 10 * time.Minute
+
+// getContainerStateSince returns when the container entered its current state: when it started
+// running or terminated or, for a waiting container, when its last instance exited or else when
+// it became the container's turn to start, which the status doesn't record for waiting.  It is
+// zero if not even that is known, which for a regular container includes while the init
+// containers are still running: it isn't its turn yet, however long they take.
+func getContainerStateSince(pod *v1.Pod, status v1.ContainerStatus, init bool) metav1.Time {
+	switch {
+	case status.State.Running != nil:
+		return status.State.Running.StartedAt
+	case status.State.Terminated != nil:
+		return status.State.Terminated.FinishedAt
+	case status.State.Waiting != nil && status.LastTerminationState.Terminated != nil:
+		return status.LastTerminationState.Terminated.FinishedAt
+	}
+
+	// regular containers are started once the init containers are done
+	if !init {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == v1.PodInitialized && condition.Status == v1.ConditionTrue {
+				return condition.LastTransitionTime
+			}
+		}
+		return metav1.Time{}
+	}
+	if pod.Status.StartTime != nil {
+		return *pod.Status.StartTime
+	}
+	return metav1.Time{}
+}
+
+// isContainerStuck reports whether a container that has never run has been waiting for longer
+// than it takes to start one, e.g. in ContainerCreating on a volume that can't be mounted.  One
+// waiting in PodInitializing is waiting for the init containers, not stuck itself.
+func isContainerStuck(status v1.ContainerStatus, since metav1.Time) bool {
+	waiting := status.State.Waiting
+	if waiting == nil || waiting.Reason == "PodInitializing" || status.LastTerminationState.Terminated != nil {
+		return false
+	}
+	return !since.IsZero() && time.Since(since.Time) > stuckWaitingThreshold
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsContainerStuck(t *testing.T) {
+	started := metav1.NewTime(time.Now().Add(-time.Hour))
+	waiting := func(reason string) v1.ContainerStatus {
+		return v1.ContainerStatus{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}}}
+	}
+
+	// a migration running in an init container for an hour, with the app container behind it
+	initializing := &v1.Pod{
+		Status: v1.PodStatus{
+			StartTime:  &started,
+			Conditions: []v1.PodCondition{{Type: v1.PodInitialized, Status: v1.ConditionFalse, LastTransitionTime: started}},
+		},
+	}
+	initialized := &v1.Pod{
+		Status: v1.PodStatus{
+			StartTime:  &started,
+			Conditions: []v1.PodCondition{{Type: v1.PodInitialized, Status: v1.ConditionTrue, LastTransitionTime: started}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		pod    *v1.Pod
+		status v1.ContainerStatus
+		init   bool
+		want   bool
+	}{
+		{"behind a running init container", initializing, waiting("PodInitializing"), false, false},
+		{"init container still creating", initializing, waiting("ContainerCreating"), true, true},
+		{"creating after the init containers", initialized, waiting("ContainerCreating"), false, true},
+		{"waiting for the init containers without a reason", initializing, waiting(""), false, false},
+		{"restarting", initialized, v1.ContainerStatus{
+			State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{FinishedAt: started}},
+		}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since := getContainerStateSince(tt.pod, tt.status, tt.init)
+			if got := isContainerStuck(tt.status, since); got != tt.want {
+				t.Errorf("isContainerStuck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
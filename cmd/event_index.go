@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// number of events fetched per list request when indexing a namespace's events
+const eventListPageSize = 500
+
+// podEventIndex is every pod event in a namespace, indexed by the pod they're about, so that a
+// sweep over many pods lists events once instead of once per pod.
+type podEventIndex struct {
+	byUID  map[types.UID][]v1.Event
+	byName map[string][]v1.Event
+}
+
+// getPodEventIndex lists the pod events of the namespace (of every namespace, for
+// --all-namespaces) and indexes them by involvedObject.  Indexes are cached for the lifetime of
+// the command, like the per-pod event lists they stand in for.
+func (dp *podInspectCommand) getPodEventIndex(namespace string) (*podEventIndex, error) {
+	key := namespace
+	if dp.allNamespaces {
+		key = ""
+	}
+	if index, ok := dp.podEventIndexes[key]; ok {
+		return index, nil
+	}
+
+	index := &podEventIndex{byUID: map[types.UID][]v1.Event{}, byName: map[string][]v1.Event{}}
+	opts := metav1.ListOptions{Limit: eventListPageSize, FieldSelector: "involvedObject.kind=Pod"}
+	for {
+		eventList, err := dp.clientset.CoreV1().Events(key).List(context.Background(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range eventList.Items {
+			obj := event.InvolvedObject
+			index.byUID[obj.UID] = append(index.byUID[obj.UID], event)
+			index.byName[obj.Namespace+"/"+obj.Name] = append(index.byName[obj.Namespace+"/"+obj.Name], event)
+		}
+		if eventList.Continue == "" {
+			break
+		}
+		opts.Continue = eventList.Continue
+	}
+
+	if dp.podEventIndexes == nil {
+		dp.podEventIndexes = map[string]*podEventIndex{}
+	}
+	dp.podEventIndexes[key] = index
+
+	return index, nil
+}
+
+// getPodEvents returns the pod's events from the index: those of this pod, or with
+// --include-prior-events, of every pod that has had its name.
+func (index *podEventIndex) getPodEvents(pod *v1.Pod, includePrior bool) []v1.Event {
+	if includePrior {
+		return index.byName[pod.Namespace+"/"+pod.Name]
+	}
+	return index.byUID[pod.UID]
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// what the exit codes a container commonly dies with usually mean; 128+N is a death by signal N
+var exitCodeHints = map[int32]string{
+	1:   "application error; the logs of the previous instance should say what went wrong",
+	2:   "misuse of a shell builtin, or a bad command-line argument",
+	126: "the command couldn't be executed; it isn't executable, or the permissions are wrong",
+	127: "the command wasn't found; check the image's entrypoint and the container's command",
+	128: "the process exited with an invalid exit code",
+	130: "interrupted (SIGINT)",
+	134: "aborted (SIGABRT); a failed assertion, or a crash in native code",
+	137: "killed with SIGKILL; usually the OOM killer, or the kubelet once the grace period ran out",
+	139: "segmentation fault (SIGSEGV); a crash in the application or in a native library",
+	143: "stopped with SIGTERM; pod deletion, eviction, or a failed liveness probe",
+	255: "the exit code was out of range, or the process exited with -1",
+}
+
+// describeExitCode explains a container instance's exit code, e.g. "exit code 127: the command
+// wasn't found; ...".  Returns "" for a clean exit, or a code we have nothing to say about.
+func describeExitCode(t *v1.ContainerStateTerminated) string {
+	if t == nil || t.ExitCode == 0 {
+		return ""
+	}
+	if hint, ok := exitCodeHints[t.ExitCode]; ok {
+		return fmt.Sprintf("exit code %d: %s", t.ExitCode, hint)
+	}
+	return ""
+}
+
+// getExitCodeMessage returns the explanation of the exit code to show under a container's state:
+// that of its current termination or, while it waits to be restarted, of its last one.
+func getExitCodeMessage(status v1.ContainerStatus) string {
+	if status.State.Terminated != nil {
+		return describeExitCode(status.State.Terminated)
+	}
+	if status.State.Waiting != nil {
+		if msg := describeExitCode(status.LastTerminationState.Terminated); msg != "" {
+			return "last " + msg
+		}
+	}
+	return ""
+}
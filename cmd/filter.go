@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the pod phases accepted by --phase
+var podPhases = []v1.PodPhase{
+	v1.PodPending,
+	v1.PodRunning,
+	v1.PodSucceeded,
+	v1.PodFailed,
+	v1.PodUnknown,
+}
+
+func validatePhaseFilter(phases []string) error {
+	for _, phase := range phases {
+		if parsePodPhase(phase) == "" {
+			names := make([]string, 0, len(podPhases))
+			for _, p := range podPhases {
+				names = append(names, string(p))
+			}
+			return fmt.Errorf("unsupported phase '%s'; must be one of: %s", phase, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+// validateSelector rejects a --selector that is only a number.  -l used to be short for
+// --max-num-log-lines, so a number there is a log line count from an old command line, which
+// would otherwise be sent to the API server as a label selector matching no pods.
+func validateSelector(selector string) error {
+	if _, err := strconv.Atoi(selector); err == nil {
+		return fmt.Errorf("-l is short for --selector, which takes a label selector, not '%s'; use --max-num-log-lines to limit the log lines", selector)
+	}
+	return nil
+}
+
+// parsePodPhase matches a --phase value case-insensitively; returns "" if it isn't a phase.
+func parsePodPhase(phase string) v1.PodPhase {
+	for _, p := range podPhases {
+		if strings.EqualFold(phase, string(p)) {
+			return p
+		}
+	}
+	return ""
+}
+
+// isPodNamePattern reports whether a pod name argument is a glob pattern ('api-server-*') rather
+// than a name; pod names can't contain any of the glob metacharacters.
+func isPodNamePattern(arg string) bool {
+	return strings.ContainsAny(arg, "*?[")
+}
+
+// compileNameFilters checks the pod name pattern and compiles --name-regex.
+func (dp *podInspectCommand) compileNameFilters() error {
+	if dp.namePattern != "" {
+		if _, err := path.Match(dp.namePattern, ""); err != nil {
+			return fmt.Errorf("invalid pod name pattern '%s': %v", dp.namePattern, err)
+		}
+	}
+	if dp.nameRegex != "" {
+		re, err := regexp.Compile(dp.nameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --name-regex '%s': %v", dp.nameRegex, err)
+		}
+		dp.nameRegexp = re
+	}
+	return nil
+}
+
+// matchesPodName applies the pod name pattern and --name-regex; the API server can't select pods
+// by anything but their exact name, so these are matched against each listed pod.
+func (dp *podInspectCommand) matchesPodName(name string) bool {
+	if dp.namePattern != "" {
+		if matched, _ := path.Match(dp.namePattern, name); !matched {
+			return false
+		}
+	}
+	if dp.nameRegexp != nil && !dp.nameRegexp.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+// podListFieldSelector returns the field selector that pushes --phase and --problems-only down to
+// the API server, along with --node and the user's own --field-selector, so that a sweep doesn't download
+// every healthy pod only to throw it away.  Field selectors can't express "phase is one of", but
+// since the set of phases is closed, that is the same as "phase is none of the others".
+func (dp *podInspectCommand) podListFieldSelector() string {
+	excluded := map[v1.PodPhase]bool{}
+
+	if len(dp.phases) > 0 {
+		wanted := map[v1.PodPhase]bool{}
+		for _, phase := range dp.phases {
+			wanted[parsePodPhase(phase)] = true
+		}
+		for _, p := range podPhases {
+			if !wanted[p] {
+				excluded[p] = true
+			}
+		}
+	}
+
+	// pods that ran to completion never need attention
+	if dp.problemsOnly {
+		excluded[v1.PodSucceeded] = true
+	}
+
+	selectors := []string{}
+	if dp.node != "" {
+		selectors = append(selectors, fmt.Sprintf("spec.nodeName=%s", dp.node))
+	}
+	if dp.fieldSelector != "" {
+		selectors = append(selectors, dp.fieldSelector)
+	}
+	for _, p := range podPhases {
+		if excluded[p] {
+			selectors = append(selectors, fmt.Sprintf("status.phase!=%s", p))
+		}
+	}
+
+	return strings.Join(selectors, ",")
+}
+
+// includePod applies the part of the filtering that the API server can't do for us.
+func (dp *podInspectCommand) includePod(pod *v1.Pod) bool {
+	if !dp.matchesPodName(pod.Name) {
+		return false
+	}
+	if dp.problemsOnly {
+		return isProblemPod(pod)
+	}
+	return true
+}
+
+// isProblemPod reports whether anything about the pod would be flagged in its report: a phase
+// other than Running or Succeeded, or a container that isn't ok or isn't ready.
+func isProblemPod(pod *v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning && pod.Status.Phase != v1.PodSucceeded {
+		return true
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if _, _, _, status := classifyContainerState(cs); status != PODINSPECT_STATUS_OK {
+			return true
+		}
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if _, _, _, status := classifyContainerState(cs); status != PODINSPECT_STATUS_OK {
+			return true
+		}
+		if !cs.Ready && pod.Status.Phase == v1.PodRunning {
+			return true
+		}
+	}
+
+	return false
+}
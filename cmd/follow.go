@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// how long to wait before reopening a log stream that ended, e.g. because the container exited
+// and is waiting to be restarted
+const followRetryInterval = 2 * time.Second
+
+// getFollowContainers returns the containers whose logs --follow-logs streams: the init
+// containers that haven't finished, and the containers that aren't ready.
+func getFollowContainers(pod *v1.Pod) []string {
+	names := []string{}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if t := cs.State.Terminated; t == nil || t.ExitCode != 0 {
+			names = append(names, cs.Name)
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if !cs.Ready {
+			names = append(names, cs.Name)
+		}
+	}
+	return names
+}
+
+// followContainerLogs streams the logs of the pod's containers that aren't ready, after the
+// report, until interrupted; it saves running kubectl logs -f for each of them.  Each line is
+// prefixed with the name of its container.  A stream that ends, because the container crashed or
+// hasn't started yet, is reopened from where it left off, so a crashlooping container's next
+// attempt shows up too.  The streams start at since, which is taken before the report fetched the
+// logs' tails, so that nothing logged while the report was being put together is missed.
+func (dp *podInspectCommand) followContainerLogs(podName string, since metav1.Time) error {
+	pod, _, err := dp.getPod(podName)
+	if err != nil {
+		return err
+	}
+
+	names := getFollowContainers(pod)
+	if len(names) == 0 {
+		fmt.Fprintf(dp.out, "\n%s  all containers are ready; no logs to follow\n", aurora.Green("✔").String())
+		return nil
+	}
+
+	fmt.Fprintf(dp.out, "\n%s %s %s\n\n", aurora.Cyan("Following logs of"), strings.Join(names, ", "), aurora.Cyan("(Ctrl-C to stop):"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	go func() {
+		select {
+		case <-interrupted:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	mu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			dp.streamContainerLogs(ctx, podName, name, since, mu)
+		}(name)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// splitLogTimestamp splits the timestamp the kubelet prefixes a log line with off the line.
+// Returns a zero time and the whole line if it doesn't start with one.
+func splitLogTimestamp(line string) (time.Time, string) {
+	parts := strings.SplitN(line, " ", 2)
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, line
+	}
+	if len(parts) == 1 {
+		return t, ""
+	}
+	return t, parts[1]
+}
+
+// streamContainerLogs follows one container's log until the context is cancelled or the pod is
+// gone, writing each line under mu so that lines from different containers don't interleave.
+// The lines are requested with their timestamps, so that a reopened stream carries on after the
+// last line received rather than from when it was reopened; they are left off again unless
+// --log-timestamps asked for them.
+func (dp *podInspectCommand) streamContainerLogs(ctx context.Context, podName, containerName string, since metav1.Time, mu *sync.Mutex) {
+	prefix := aurora.Cyan(fmt.Sprintf("[%s]", containerName)).String()
+
+	var last time.Time
+	for ctx.Err() == nil {
+		logOptions := v1.PodLogOptions{Container: containerName, Follow: true, SinceTime: &since, Timestamps: true}
+		stream, err := dp.clientset.CoreV1().Pods(dp.namespace).GetLogs(podName, &logOptions).Stream(ctx)
+		if err == nil {
+			// the API server only takes sinceTime to the second, so a reopened stream starts
+			// with lines that were already received
+			replaying := !last.IsZero()
+			reader := bufio.NewReader(stream)
+			for {
+				line, err := readLogLine(reader)
+				if err != nil {
+					break
+				}
+				t, rest := splitLogTimestamp(line)
+				if !t.IsZero() {
+					if replaying && !t.After(last) {
+						continue
+					}
+					replaying = false
+					last = t
+					since = metav1.NewTime(t)
+				}
+				if !dp.logTimestamps {
+					line = rest
+				}
+				line = dp.formatLogTimestamps(dp.colorizeLogLevels(dp.formatJSONLogs(sanitizeLogLine(line) + "\n")))
+				mu.Lock()
+				fmt.Fprintf(dp.out, "%s %s", prefix, line)
+				mu.Unlock()
+			}
+			stream.Close()
+		} else if apierrors.IsNotFound(err) {
+			mu.Lock()
+			fmt.Fprintf(dp.out, "%s %s  %s\n", prefix, aurora.Yellow("…").String(), aurora.Yellow(fmt.Sprintf("pod %s no longer exists; stopped following its logs", podName)))
+			mu.Unlock()
+			return
+		} else if note := describeForbidden(err); note != "" {
+			mu.Lock()
+			fmt.Fprintf(dp.out, "%s %s  %s\n", prefix, aurora.Yellow("…").String(), aurora.Yellow(note))
+			mu.Unlock()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(followRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// how full a filesystem can get before --fs-usage warns about it, and before it calls it full
+const (
+	fsUsageWarnPercent = 80
+	fsUsageFullPercent = 90
+)
+
+// how many seconds du may walk an emptyDir for.  It is run under timeout, like the connectivity
+// checks' bash, so that on a huge volume it is killed in the container rather than left running
+// there once execInContainer has stopped waiting for it.
+const duTimeoutSeconds = "10"
+
+// fsUsagePath is a path in a container whose filesystem --fs-usage checks: the root, which is
+// the container's writable layer, or where an emptyDir is mounted.
+type fsUsagePath struct {
+	Path      string
+	Volume    string
+	EmptyDir  *v1.EmptyDirVolumeSource
+	SizeLimit int64 // KiB; 0 if the emptyDir has none
+}
+
+// getFSUsagePaths returns the paths to check in a container: its root filesystem, and each
+// emptyDir it mounts writable.
+func getFSUsagePaths(pod *v1.Pod, c v1.Container) []fsUsagePath {
+	emptyDirs := map[string]*v1.EmptyDirVolumeSource{}
+	for _, v := range pod.Spec.Volumes {
+		if v.EmptyDir != nil {
+			emptyDirs[v.Name] = v.EmptyDir
+		}
+	}
+
+	paths := []fsUsagePath{{Path: "/"}}
+	for _, vm := range c.VolumeMounts {
+		ed, ok := emptyDirs[vm.Name]
+		if !ok || vm.ReadOnly {
+			continue
+		}
+		p := fsUsagePath{Path: vm.MountPath, Volume: vm.Name, EmptyDir: ed}
+		if ed.SizeLimit != nil {
+			p.SizeLimit = ed.SizeLimit.Value() / 1024
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+// dfUsage is one line of df -Pk output, in KiB.
+type dfUsage struct {
+	Size      int64
+	Used      int64
+	Available int64
+}
+
+// parseDF reads the output of df -Pk for the given paths.  df writes a line per path, in order,
+// after a header; the numbers are the last fields, since the filesystem name can have spaces.
+func parseDF(output string, paths int) ([]dfUsage, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != paths+1 {
+		return nil, fmt.Errorf("unexpected df output: %q", output)
+	}
+
+	usages := []dfUsage{}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("unexpected df output: %q", line)
+		}
+		n := len(fields)
+		size, err1 := strconv.ParseInt(fields[n-5], 10, 64)
+		used, err2 := strconv.ParseInt(fields[n-4], 10, 64)
+		available, err3 := strconv.ParseInt(fields[n-3], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("unexpected df output: %q", line)
+		}
+		usages = append(usages, dfUsage{Size: size, Used: used, Available: available})
+	}
+	return usages, nil
+}
+
+// parseDU reads the KiB total out of du -sk output.
+func parseDU(output string) (int64, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected du output: %q", output)
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+// formatKiB renders a size in KiB the way kubectl renders quantities, e.g. 512Mi.
+func formatKiB(kib int64) string {
+	switch {
+	case kib >= 1024*1024:
+		return fmt.Sprintf("%.1fGi", float64(kib)/(1024*1024))
+	case kib >= 1024:
+		return fmt.Sprintf("%.0fMi", float64(kib)/1024)
+	}
+	return fmt.Sprintf("%dKi", kib)
+}
+
+// describeFSUsage renders how full a filesystem is, with an icon once it's getting full.
+func describeFSUsage(used, size int64) string {
+	if size <= 0 {
+		return "n/a"
+	}
+	percent := used * 100 / size
+	s := fmt.Sprintf("%d%%", percent)
+	switch {
+	case percent >= fsUsageFullPercent:
+		return fmt.Sprintf("%s  %s", aurora.Red("✖").String(), aurora.Red(s))
+	case percent >= fsUsageWarnPercent:
+		return fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), aurora.Yellow(s))
+	}
+	return s
+}
+
+// getFSUsage runs df (and du, for emptyDirs with a sizeLimit) in each running container, for
+// --fs-usage, to catch a full writable layer or emptyDir before it shows up as "no space left on
+// device" in the logs.  An emptyDir without a sizeLimit shares the node's disk, which is what df
+// reports; one with a sizeLimit is evicted by the kubelet once what's in it (du) passes the limit.
+func (dp *podInspectCommand) getFSUsage(pod *v1.Pod) (string, error) {
+	if !dp.fsUsage {
+		return "", nil
+	}
+
+	running := map[string]bool{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		running[cs.Name] = cs.State.Running != nil
+	}
+
+	retval := aurora.Cyan("Filesystem Usage:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Path").String(),
+		aurora.Yellow("Volume").String(),
+		aurora.Yellow("Size").String(),
+		aurora.Yellow("Used").String(),
+		aurora.Yellow("Available").String(),
+		aurora.Yellow("Use%").String(),
+	})
+
+	checked := 0
+	for _, c := range pod.Spec.Containers {
+		if !running[c.Name] {
+			continue
+		}
+		checked++
+
+		paths := getFSUsagePaths(pod, c)
+		command := []string{"df", "-Pk"}
+		for _, p := range paths {
+			command = append(command, p.Path)
+		}
+
+		stdout, stderr, err := dp.execInContainer(pod, c.Name, command)
+		if note := describeForbidden(err); note != "" {
+			return "", err
+		}
+		if err != nil {
+			reason := strings.TrimSpace(stderr)
+			if strings.Contains(err.Error(), "executable file not found") || strings.Contains(stderr, "executable file not found") {
+				reason = "the image has no df to check with"
+			} else if reason == "" {
+				reason = err.Error()
+			}
+			tw.Append([]string{c.Name, "", "", "", "", "", fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), reason)})
+			continue
+		}
+		usages, err := parseDF(stdout, len(paths))
+		if err != nil {
+			tw.Append([]string{c.Name, "", "", "", "", "", fmt.Sprintf("%s  %v", aurora.Yellow("…").String(), err)})
+			continue
+		}
+
+		for i, p := range paths {
+			u := usages[i]
+			volume := "(writable layer)"
+			if p.Volume != "" {
+				volume = fmt.Sprintf("%s (emptyDir)", p.Volume)
+				if p.EmptyDir.Medium == v1.StorageMediumMemory {
+					volume = fmt.Sprintf("%s (emptyDir, memory)", p.Volume)
+				}
+			}
+			row := []string{c.Name, p.Path, volume, formatKiB(u.Size), formatKiB(u.Used), formatKiB(u.Available), describeFSUsage(u.Used, u.Size)}
+
+			if p.SizeLimit > 0 {
+				out, _, err := dp.execInContainer(pod, c.Name, []string{"timeout", duTimeoutSeconds, "du", "-sk", p.Path})
+				if used, perr := parseDU(out); err == nil && perr == nil {
+					row[3] = fmt.Sprintf("%s (sizeLimit)", formatKiB(p.SizeLimit))
+					row[4] = formatKiB(used)
+					// past the limit there's nothing left; the Use% column shows by how much it's over
+					available := p.SizeLimit - used
+					if available < 0 {
+						available = 0
+					}
+					row[5] = formatKiB(available)
+					row[6] = describeFSUsage(used, p.SizeLimit)
+				}
+			}
+			tw.Append(row)
+		}
+	}
+
+	if checked == 0 {
+		retval += fmt.Sprintf("%s  no container is running to check\n", aurora.Yellow("…").String())
+		return retval, nil
+	}
+
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// labels that cloud providers, the NVIDIA GPU operator, and node-feature-discovery put on nodes
+// that physically have GPUs attached.  If one of these is present but the node advertises no
+// allocatable GPUs, the device plugin on that node is almost certainly unhealthy.
+var gpuNodeLabels = []string{
+	"nvidia.com/gpu.present",
+	"nvidia.com/gpu.count",
+	"nvidia.com/gpu.product",
+	"cloud.google.com/gke-accelerator",
+	"k8s.amazonaws.com/accelerator",
+	"feature.node.kubernetes.io/pci-10de.present",
+}
+
+var gpuEventRegexp = regexp.MustCompile(`(?i)gpu|nvidia|device.?plugin`)
+
+func isGPUResource(name v1.ResourceName) bool {
+	n := strings.ToLower(string(name))
+	return strings.Contains(n, "gpu") || strings.HasPrefix(n, "nvidia.com/mig-")
+}
+
+// getPodGPURequests returns the effective GPU requests of the pod: the larger of the sum over the
+// regular containers and the largest single init container, which is how the scheduler sees it.
+func getPodGPURequests(pod *v1.Pod) v1.ResourceList {
+	requests := v1.ResourceList{}
+
+	for _, c := range pod.Spec.Containers {
+		for name, q := range getContainerExtendedResources(c) {
+			if !isGPUResource(name) {
+				continue
+			}
+			total := requests[name]
+			total.Add(q)
+			requests[name] = total
+		}
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		for name, q := range getContainerExtendedResources(c) {
+			if !isGPUResource(name) {
+				continue
+			}
+			if total, ok := requests[name]; !ok || q.Cmp(total) > 0 {
+				requests[name] = q
+			}
+		}
+	}
+
+	return requests
+}
+
+// getSortedResourceNames returns the names of the resources in the list, sorted so that they are
+// reported in the same order every time.
+func getSortedResourceNames(resources v1.ResourceList) []v1.ResourceName {
+	names := []string{}
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	sorted := []v1.ResourceName{}
+	for _, name := range names {
+		sorted = append(sorted, v1.ResourceName(name))
+	}
+	return sorted
+}
+
+// getContainerExtendedResources returns the container's requests, falling back to its limits;
+// extended resources may be specified with limits only, in which case requests default to them.
+func getContainerExtendedResources(c v1.Container) v1.ResourceList {
+	resources := v1.ResourceList{}
+	for name, q := range c.Resources.Limits {
+		resources[name] = q
+	}
+	for name, q := range c.Resources.Requests {
+		resources[name] = q
+	}
+	return resources
+}
+
+func getNodeGPULabel(node *v1.Node) string {
+	for _, label := range gpuNodeLabels {
+		if value, ok := node.Labels[label]; ok {
+			return fmt.Sprintf("%s=%s", label, value)
+		}
+	}
+	return ""
+}
+
+func (dp *podInspectCommand) getGPUHealth(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	gpuRequests := getPodGPURequests(pod)
+	if len(gpuRequests) == 0 {
+		return "", nil
+	}
+	gpuNames := getSortedResourceNames(gpuRequests)
+
+	nodes := []*v1.Node{}
+	if pod.Spec.NodeName != "" {
+		node, err := dp.getScheduledNode(pod)
+		if err != nil || node == nil {
+			return "", err
+		}
+		nodes = append(nodes, node)
+	} else {
+		// the pod hasn't been scheduled, so look at every node that claims to have GPUs
+		nodeList, err := dp.listNodes()
+		if err != nil {
+			return "", err
+		}
+		for i := range nodeList {
+			node := &nodeList[i]
+			if getNodeGPULabel(node) != "" {
+				nodes = append(nodes, node)
+				continue
+			}
+			for _, name := range gpuNames {
+				capacity := node.Status.Capacity[name]
+				if !capacity.IsZero() {
+					nodes = append(nodes, node)
+					break
+				}
+			}
+		}
+	}
+
+	retval += aurora.Cyan("GPU Health:\n\n").String()
+
+	if len(nodes) == 0 {
+		for _, name := range gpuNames {
+			retval += fmt.Sprintf("%s  no node in the cluster advertises %s\n", aurora.Red("✖").String(), name)
+		}
+		return retval, nil
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Node").String(),
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Requested").String(),
+		aurora.Yellow("Capacity").String(),
+		aurora.Yellow("Allocatable").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	for _, node := range nodes {
+		gpuLabel := getNodeGPULabel(node)
+		for _, name := range gpuNames {
+			requested := gpuRequests[name]
+			capacity := node.Status.Capacity[name]
+			allocatable := node.Status.Allocatable[name]
+
+			tw.Append([]string{
+				node.Name,
+				string(name),
+				requested.String(),
+				capacity.String(),
+				allocatable.String(),
+				getGPUNodeStatus(gpuLabel, requested, allocatable),
+			})
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	gpuEvents, err := dp.getGPUNodeEvents(nodes)
+	if err != nil {
+		return "", err
+	}
+	if gpuEvents != "" {
+		retval += "\n" + gpuEvents
+	}
+
+	return retval, nil
+}
+
+func getGPUNodeStatus(gpuLabel string, requested, allocatable resource.Quantity) string {
+	if allocatable.IsZero() {
+		if gpuLabel != "" {
+			return fmt.Sprintf("%s  labeled %s but 0 allocatable; check the device plugin", aurora.Red("✖").String(), gpuLabel)
+		}
+		return fmt.Sprintf("%s  no allocatable GPUs", aurora.Red("✖").String())
+	}
+
+	if allocatable.Cmp(requested) < 0 {
+		return fmt.Sprintf("%s  fewer allocatable GPUs than requested", aurora.Red("✖").String())
+	}
+
+	return aurora.Green("✔").String()
+}
+
+// getGPUNodeEvents renders the recent events on the given nodes that look like they came from (or
+// concern) a GPU device plugin.
+func (dp *podInspectCommand) getGPUNodeEvents(nodes []*v1.Node) (string, error) {
+	events := []v1.Event{}
+	for _, node := range nodes {
+		nodeEvents, err := dp.getNodeEvents(node.Name)
+		if err != nil {
+			return "", err
+		}
+
+		for _, event := range nodeEvents {
+			if gpuEventRegexp.MatchString(event.Reason) || gpuEventRegexp.MatchString(event.Message) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	if len(events) == 0 {
+		return "", nil
+	}
+
+	if dp.numEvents > 0 && len(events) > dp.numEvents {
+		events = events[len(events)-dp.numEvents:]
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Last Seen").String(),
+		aurora.Yellow("Node").String(),
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, event := range events {
+		timestamp := getEventTimestamp(event)
+		tw.Append([]string{
+			dp.formatTimestamp(timestamp),
+			event.InvolvedObject.Name,
+			event.Type,
+			event.Reason,
+			event.Message,
+		})
+	}
+	tw.Render()
+
+	return aurora.Cyan("GPU device plugin events:\n\n").String() + sb.String(), nil
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// isPodRestarting reports whether any of the pod's containers have restarted or are failing.
+func isPodRestarting(pod *v1.Pod) bool {
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if cs.RestartCount > 0 {
+			return true
+		}
+		if _, _, _, status := classifyContainerState(cs); status == PODINSPECT_STATUS_FAILED {
+			return true
+		}
+	}
+	return false
+}
+
+// getNodeHeadroom checks, for a pod whose containers are restarting, whether its node has the free
+// allocatable capacity for the containers to come back up at their limits.  The scheduler only
+// reserves requests; a crashlooping container that bursts to its limits on every start on a
+// packed node puts the node under memory pressure, and the kubelet then evicts its neighbors.
+func (dp *podInspectCommand) getNodeHeadroom(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if !isPodRestarting(pod) {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil || node == nil {
+		return "", err
+	}
+
+	nodePods, err := dp.getNodePods(node.Name)
+	if err != nil {
+		return "", err
+	}
+
+	nodeRequests := v1.ResourceList{}
+	for i := range nodePods {
+		for name, q := range getPodEffectiveRequests(&nodePods[i]) {
+			total := nodeRequests[name]
+			total.Add(q)
+			nodeRequests[name] = total
+		}
+	}
+
+	podRequests := getPodEffectiveRequests(pod)
+	podLimits, unbounded := getPodEffectiveLimits(pod)
+
+	retval += aurora.Cyan(fmt.Sprintf("Node Headroom (%s, %d pods):\n\n", node.Name, len(nodePods))).String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Allocatable").String(),
+		aurora.Yellow("Requested").String(),
+		aurora.Yellow("Free").String(),
+		aurora.Yellow("Pod Request").String(),
+		aurora.Yellow("Pod Limit").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		allocatable := node.Status.Allocatable[name]
+		requested := nodeRequests[name]
+
+		free := allocatable.DeepCopy()
+		free.Sub(requested)
+
+		podRequest := podRequests[name]
+		podLimit := podLimits[name]
+
+		// what the pod can use beyond its reservation when it starts up at its limits
+		burst := podLimit.DeepCopy()
+		burst.Sub(podRequest)
+
+		limit := formatHeadroomQuantity(name, podLimit)
+		if unbounded[name] {
+			limit = "none"
+		}
+		status := aurora.Green("✔").String()
+		switch {
+		case free.Sign() < 0:
+			status = fmt.Sprintf("%s  node is overcommitted on requests", aurora.Red("✖").String())
+		case unbounded[name]:
+			status = fmt.Sprintf("%s  no limit; on restart the pod can take whatever is free", aurora.Yellow("…").String())
+		case burst.Cmp(free) > 0:
+			short := burst.DeepCopy()
+			short.Sub(free)
+			severity := aurora.Yellow("…").String()
+			if name == v1.ResourceMemory {
+				// cpu is throttled, but running out of memory gets pods evicted
+				severity = aurora.Red("✖").String()
+			}
+			status = fmt.Sprintf("%s  restarting at its limit needs %s more than is free; neighbors risk eviction", severity, formatHeadroomQuantity(name, short))
+		}
+
+		tw.Append([]string{
+			string(name),
+			formatHeadroomQuantity(name, allocatable),
+			formatHeadroomQuantity(name, requested),
+			formatHeadroomQuantity(name, free),
+			formatHeadroomQuantity(name, podRequest),
+			limit,
+			status,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+// formatHeadroomQuantity renders memory in binary units, which is easier to compare at a glance
+// than the raw byte counts that sums of mixed units end up as.
+func formatHeadroomQuantity(name v1.ResourceName, q resource.Quantity) string {
+	if name != v1.ResourceMemory {
+		return q.String()
+	}
+
+	const mi = 1 << 20
+	value := q.Value()
+	if value > -mi && value < mi {
+		return q.String()
+	}
+	return resource.NewQuantity((value/mi)*mi, resource.BinarySI).String()
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+	"github.com/spf13/cobra"
+
+	// a pure-Go SQLite, so that the plugin still builds without cgo
+	_ "modernc.org/sqlite"
+)
+
+// the pod_health table holds a row per pod per scan; times are RFC 3339 in UTC, so that they
+// sort as text
+const historySchema = `
+CREATE TABLE IF NOT EXISTS pod_health (
+	scanned_at TEXT NOT NULL,
+	namespace  TEXT NOT NULL,
+	pod        TEXT NOT NULL,
+	uid        TEXT NOT NULL,
+	node       TEXT NOT NULL,
+	phase      TEXT NOT NULL,
+	verdict    TEXT NOT NULL,
+	restarts   INTEGER NOT NULL,
+	reason     TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS pod_health_scanned_at ON pod_health (scanned_at);
+CREATE INDEX IF NOT EXISTS pod_health_pod ON pod_health (namespace, pod);
+`
+
+// historyRow is what --history-db records about a pod in a scan.
+type historyRow struct {
+	ScannedAt string
+	Namespace string
+	Pod       string
+	UID       string
+	Node      string
+	Phase     string
+	Verdict   string
+	Restarts  int32
+	Reason    string
+}
+
+// getPodHealthReason names what's wrong with the pod, for the history: the reason of the first
+// container that isn't ok (e.g. CrashLoopBackOff or OOMKilled), or else the pod's own reason
+// (e.g. Evicted).
+func getPodHealthReason(pod *v1.Pod) string {
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if _, reason, _, status := classifyContainerState(cs); status != PODINSPECT_STATUS_OK && reason != "" {
+			return reason
+		}
+	}
+	return pod.Status.Reason
+}
+
+func newHistoryRow(pod *v1.Pod, verdict report.Verdict, scannedAt time.Time) historyRow {
+	return historyRow{
+		ScannedAt: scannedAt.UTC().Format(time.RFC3339),
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		UID:       string(pod.UID),
+		Node:      pod.Spec.NodeName,
+		Phase:     string(pod.Status.Phase),
+		Verdict:   string(verdict),
+		Restarts:  getPodRestarts(pod),
+		Reason:    getPodHealthReason(pod),
+	}
+}
+
+// openHistoryDB opens the --history-db file, creating it and its table if need be.
+func openHistoryDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("--history-db %s: %v", path, err)
+	}
+	return db, nil
+}
+
+// recordScan appends the rows of a scan to the --history-db, in one transaction so that a scan
+// is either recorded whole or not at all.
+func (dp *podInspectCommand) recordScan(s *scanSummary) error {
+	if dp.historyDB == "" || len(s.rows) == 0 {
+		return nil
+	}
+
+	db, err := openHistoryDB(dp.historyDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, r := range s.rows {
+		_, err := tx.Exec("INSERT INTO pod_health (scanned_at, namespace, pod, uid, node, phase, verdict, restarts, reason) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			r.ScannedAt, r.Namespace, r.Pod, r.UID, r.Node, r.Phase, r.Verdict, r.Restarts, r.Reason)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("--history-db %s: %v", dp.historyDB, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// historyQuery holds the filters of the query subcommand.
+type historyQuery struct {
+	namespace string
+	pod       string
+	verdicts  []string
+	since     time.Duration
+	limit     int
+}
+
+// buildSQL renders the query as SQL with its arguments, newest rows first.
+func (q historyQuery) buildSQL(now time.Time) (string, []interface{}) {
+	where := []string{}
+	args := []interface{}{}
+	if q.namespace != "" {
+		where = append(where, "namespace = ?")
+		args = append(args, q.namespace)
+	}
+	if q.pod != "" {
+		where = append(where, "pod = ?")
+		args = append(args, q.pod)
+	}
+	if len(q.verdicts) > 0 {
+		placeholders := []string{}
+		for _, v := range q.verdicts {
+			placeholders = append(placeholders, "?")
+			args = append(args, strings.ToLower(v))
+		}
+		where = append(where, fmt.Sprintf("lower(verdict) IN (%s)", strings.Join(placeholders, ", ")))
+	}
+	if q.since > 0 {
+		where = append(where, "scanned_at >= ?")
+		args = append(args, now.Add(-q.since).UTC().Format(time.RFC3339))
+	}
+
+	query := "SELECT scanned_at, namespace, pod, uid, node, phase, verdict, restarts, reason FROM pod_health"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY scanned_at DESC, namespace, pod"
+	if q.limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.limit)
+	}
+	return query, args
+}
+
+func newQueryCmd(dp *podInspectCommand) *cobra.Command {
+	q := historyQuery{}
+
+	cmd := &cobra.Command{
+		Use:          "query",
+		Short:        "show the pod health recorded by --history-db",
+		Long:         "Lists the pod health rows that sweeps and watches with --history-db have recorded, newest first, filtered by namespace (-n), pod, verdict, and age.  It reads only the file; the cluster isn't contacted.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("this command does not accept arguments")
+			}
+			if dp.historyDB == "" {
+				return fmt.Errorf("--history-db is required")
+			}
+			// -n filters the rows; without it, every namespace's are shown
+			if ns := cmd.Flag("namespace"); ns != nil && ns.Changed {
+				q.namespace = ns.Value.String()
+			}
+			return dp.runQuery(q)
+		},
+	}
+
+	cmd.Flags().StringVar(&dp.historyDB, "history-db", "", "The SQLite file that --history-db scans recorded into")
+	cmd.Flags().StringVar(&q.pod, "pod", "", "Only show the rows of the pod with this name")
+	cmd.Flags().StringSliceVar(&q.verdicts, "verdict", nil, "Only show the rows with these verdicts, e.g. --verdict failed,degraded")
+	cmd.Flags().DurationVar(&q.since, "since", 0, "Only show the rows recorded within this long, e.g. 24h")
+	cmd.Flags().IntVar(&q.limit, "limit", 100, "Show at most this many rows; 0 means no limit")
+
+	// see newVersionCmd
+	oldLine := `{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+	newLine := `
+  kubectl pod-inspect query --history-db <file> [flags]{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+
+	cmd.SetUsageTemplate(strings.Replace(cmd.UsageTemplate(), oldLine, newLine, 1))
+
+	return cmd
+}
+
+// colorForVerdict picks the color a recorded verdict is shown in.
+func colorForVerdict(verdict string) colorFunc {
+	switch report.Verdict(verdict) {
+	case report.VerdictHealthy:
+		return aurora.Green
+	case report.VerdictFailed:
+		return aurora.Red
+	}
+	return aurora.Yellow
+}
+
+// runQuery prints the history rows that match the query.
+func (dp *podInspectCommand) runQuery(q historyQuery) error {
+	db, err := openHistoryDB(dp.historyDB)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	query, args := q.buildSQL(time.Now())
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tw := dp.newTablewriter(dp.out)
+	tw.Append([]string{
+		aurora.Yellow("Scanned").String(),
+		aurora.Yellow("Namespace").String(),
+		aurora.Yellow("Pod").String(),
+		aurora.Yellow("Verdict").String(),
+		aurora.Yellow("Phase").String(),
+		aurora.Yellow("Restarts").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Node").String(),
+	})
+
+	n := 0
+	for rows.Next() {
+		r := historyRow{}
+		if err := rows.Scan(&r.ScannedAt, &r.Namespace, &r.Pod, &r.UID, &r.Node, &r.Phase, &r.Verdict, &r.Restarts, &r.Reason); err != nil {
+			return err
+		}
+		n++
+		tw.Append([]string{
+			r.ScannedAt,
+			r.Namespace,
+			r.Pod,
+			colorForVerdict(r.Verdict)(r.Verdict).String(),
+			r.Phase,
+			fmt.Sprintf("%d", r.Restarts),
+			r.Reason,
+			r.Node,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if n == 0 {
+		_, err := fmt.Fprintf(dp.out, "%s  no recorded rows match\n", aurora.Yellow("…").String())
+		return err
+	}
+	tw.Render()
+	return nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+)
+
+func TestHistoryQueryBuildSQL(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	selectRows := "SELECT scanned_at, namespace, pod, uid, node, phase, verdict, restarts, reason FROM pod_health"
+	order := " ORDER BY scanned_at DESC, namespace, pod"
+
+	tests := []struct {
+		name     string
+		query    historyQuery
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"everything", historyQuery{}, selectRows + order, []interface{}{}},
+		{"limit", historyQuery{limit: 10}, selectRows + order + " LIMIT ?", []interface{}{10}},
+		{
+			"all filters",
+			historyQuery{namespace: "prod", pod: "web-0", verdicts: []string{"Failed", "degraded"}, since: time.Hour, limit: 5},
+			selectRows + " WHERE namespace = ? AND pod = ? AND lower(verdict) IN (?, ?) AND scanned_at >= ?" + order + " LIMIT ?",
+			[]interface{}{"prod", "web-0", "failed", "degraded", "2020-10-01T11:00:00Z", 5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := tt.query.buildSQL(now)
+			if sql != tt.wantSQL {
+				t.Errorf("buildSQL() = %q, want %q", sql, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("buildSQL() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestGetPodHealthReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status v1.PodStatus
+		want   string
+	}{
+		{"healthy", v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{Name: "app", Ready: true, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}}}, ""},
+		{"crashlooping", v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}}, "CrashLoopBackOff"},
+		{"init container first", v1.PodStatus{
+			InitContainerStatuses: []v1.ContainerStatus{{Name: "init", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}}},
+			ContainerStatuses:     []v1.ContainerStatus{{Name: "app", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "PodInitializing"}}}},
+		}, "ImagePullBackOff"},
+		{"evicted", v1.PodStatus{Phase: v1.PodFailed, Reason: "Evicted"}, "Evicted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPodHealthReason(&v1.Pod{Status: tt.status}); got != tt.want {
+				t.Errorf("getPodHealthReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordScanAndQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pod-inspect-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := &bytes.Buffer{}
+	dp := &podInspectCommand{out: out, historyDB: filepath.Join(dir, "history.db"), podEvents: map[types.UID][]v1.Event{}}
+
+	pod := func(namespace, name string, restarts int32) *v1.Pod {
+		p := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "-" + name)},
+			Spec:       v1.PodSpec{NodeName: "node-1"},
+			Status: v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{
+				{Name: "app", RestartCount: restarts, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true},
+			}},
+		}
+		dp.podEvents[p.UID] = []v1.Event{}
+		return p
+	}
+
+	// nothing to record isn't an error, and doesn't create the file
+	if err := dp.recordScan(newScanSummary()); err != nil {
+		t.Fatalf("recordScan() of an empty scan error = %v", err)
+	}
+
+	for _, scan := range [][]*v1.Pod{
+		{pod("prod", "web-0", 0), pod("dev", "api-0", 0)},
+		{pod("prod", "web-0", 3), pod("dev", "api-0", 0)},
+	} {
+		s := newScanSummary()
+		for i, p := range scan {
+			verdict := report.VerdictHealthy
+			if i == 0 && p.Status.ContainerStatuses[0].RestartCount > 0 {
+				verdict = report.VerdictDegraded
+			}
+			s.addPod(dp, p, verdict)
+		}
+		if err := dp.recordScan(s); err != nil {
+			t.Fatalf("recordScan() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    historyQuery
+		wantRows int
+		want     []string
+	}{
+		{"everything", historyQuery{}, 4, []string{"prod", "dev", "web-0", "api-0", "node-1"}},
+		{"by namespace", historyQuery{namespace: "dev"}, 2, []string{"api-0"}},
+		{"by verdict", historyQuery{verdicts: []string{"Degraded"}}, 1, []string{"web-0", "Degraded", "3"}},
+		{"limited", historyQuery{limit: 1}, 1, nil},
+		{"nothing matches", historyQuery{pod: "no-such-pod"}, 0, []string{"no recorded rows match"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			if err := dp.runQuery(tt.query); err != nil {
+				t.Fatalf("runQuery() error = %v", err)
+			}
+			got := out.String()
+			// a header line and a line per row
+			if rows := strings.Count(got, "\n") - 1; tt.wantRows > 0 && rows != tt.wantRows {
+				t.Errorf("runQuery() printed %d rows, want %d:\n%s", rows, tt.wantRows, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("runQuery() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
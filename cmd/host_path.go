@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the namespace label that makes the API server enforce a Pod Security Standard
+const podSecurityEnforceLabel = "pod-security.kubernetes.io/enforce"
+
+// the annotation OpenShift records the security context constraint a pod was admitted under in
+const openshiftSCCAnnotation = "openshift.io/scc"
+
+// the SCCs that let a pod use host directories freely
+var hostMountSCCs = map[string]bool{
+	"privileged":       true,
+	"hostmount-anyuid": true,
+	"hostaccess":       true,
+	"node-exporter":    true,
+}
+
+// host paths that only exist on nodes running a particular container runtime, keyed by the
+// prefix of the runtime's version string in the node status
+var runtimeHostPaths = []struct {
+	prefix  string
+	runtime string
+}{
+	{"/var/run/docker.sock", "docker"},
+	{"/run/docker.sock", "docker"},
+	{"/var/lib/docker", "docker"},
+	{"/run/containerd", "containerd"},
+	{"/var/run/containerd", "containerd"},
+	{"/run/crio", "cri-o"},
+	{"/var/run/crio", "cri-o"},
+}
+
+// describeMissingHostPath says why the node likely lacks a host path, judging by its container
+// runtime and hardware, or returns "" if there's no reason to think so.
+func describeMissingHostPath(path string, node *v1.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	runtime := node.Status.NodeInfo.ContainerRuntimeVersion
+	for _, p := range runtimeHostPaths {
+		if (path == p.prefix || strings.HasPrefix(path, p.prefix+"/")) && !strings.HasPrefix(runtime, p.runtime+"://") {
+			return fmt.Sprintf("%s belongs to %s, but node %s runs %s", path, p.runtime, node.Name, runtime)
+		}
+	}
+
+	if strings.HasPrefix(path, "/dev/nvidia") && getNodeGPULabel(node) == "" {
+		hasGPUs := false
+		for name := range node.Status.Capacity {
+			if isGPUResource(name) {
+				hasGPUs = true
+			}
+		}
+		if !hasGPUs {
+			return fmt.Sprintf("%s is an NVIDIA device, but node %s has no GPUs", path, node.Name)
+		}
+	}
+
+	return ""
+}
+
+// isNonRootContainer reports whether the container runs as a user other than root, and as which
+// uid if that's known.
+func isNonRootContainer(pod *v1.Pod, c v1.Container) (bool, string) {
+	var runAsUser *int64
+	var runAsNonRoot *bool
+	if psc := pod.Spec.SecurityContext; psc != nil {
+		runAsUser, runAsNonRoot = psc.RunAsUser, psc.RunAsNonRoot
+	}
+	if sc := c.SecurityContext; sc != nil {
+		if sc.RunAsUser != nil {
+			runAsUser = sc.RunAsUser
+		}
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+	}
+
+	if runAsUser != nil {
+		return *runAsUser != 0, fmt.Sprintf("uid %d", *runAsUser)
+	}
+	if runAsNonRoot != nil && *runAsNonRoot {
+		return true, "runAsNonRoot"
+	}
+	return false, ""
+}
+
+func isPrivilegedContainer(c v1.Container) bool {
+	return c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged
+}
+
+// getHostPathMounts lists the pod's hostPath volumes, with their paths and types and the
+// containers that mount them, and warns about what commonly goes wrong with them: the path not
+// existing on the node, containers that aren't allowed to use what they mount (non-root users,
+// SELinux under an OpenShift SCC), and namespaces whose Pod Security level forbids them.
+func (dp *podInspectCommand) getHostPathMounts(pod *v1.Pod) (string, error) {
+	volumes := []v1.Volume{}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.HostPath != nil {
+			volumes = append(volumes, vol)
+		}
+	}
+	if len(volumes) == 0 {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil && describeForbidden(err) == "" {
+		return "", err
+	}
+
+	warnings := []string{}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Volume").String(),
+		aurora.Yellow("Host Path").String(),
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Mounted In").String(),
+	})
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, vol := range volumes {
+		hostPathType := v1.HostPathUnset
+		if vol.HostPath.Type != nil {
+			hostPathType = *vol.HostPath.Type
+		}
+		typeName := string(hostPathType)
+		if typeName == "" {
+			typeName = "(unset)"
+		}
+
+		mounts := []string{}
+		for _, c := range containers {
+			for _, m := range c.VolumeMounts {
+				if m.Name != vol.Name {
+					continue
+				}
+				mode := "rw"
+				if m.ReadOnly {
+					mode = "ro"
+				}
+				mounts = append(mounts, fmt.Sprintf("%s:%s (%s)", c.Name, m.MountPath, mode))
+
+				if nonRoot, user := isNonRootContainer(pod, c); nonRoot && !m.ReadOnly && !isPrivilegedContainer(c) {
+					warnings = append(warnings, fmt.Sprintf("%s  container %s runs as non-root (%s) and mounts %s writable; host directories are usually owned by root, so writes fail with permission denied", aurora.Yellow("…").String(), c.Name, user, vol.HostPath.Path))
+				}
+			}
+		}
+		tw.Append([]string{vol.Name, vol.HostPath.Path, typeName, strings.Join(mounts, ", ")})
+
+		if reason := describeMissingHostPath(vol.HostPath.Path, node); reason != "" {
+			consequence := "the mount fails"
+			switch hostPathType {
+			case v1.HostPathUnset, v1.HostPathDirectoryOrCreate, v1.HostPathFileOrCreate:
+				consequence = "the kubelet creates an empty one in its place, which the container won't be able to use"
+			}
+			warnings = append(warnings, fmt.Sprintf("%s  %s; the path is most likely missing, and %s", aurora.Red("✖").String(), reason, consequence))
+		}
+	}
+	tw.Render()
+
+	if scc, ok := pod.Annotations[openshiftSCCAnnotation]; ok && !hostMountSCCs[scc] {
+		privileged := false
+		for _, c := range containers {
+			privileged = privileged || isPrivilegedContainer(c)
+		}
+		if !privileged {
+			warnings = append(warnings, fmt.Sprintf("%s  the pod runs under SCC %s; SELinux denies unprivileged containers access to host files that aren't labeled for containers", aurora.Yellow("…").String(), scc))
+		}
+	}
+
+	ns, err := dp.clientset.CoreV1().Namespaces().Get(context.Background(), pod.Namespace, metav1.GetOptions{})
+	if err == nil {
+		switch level := ns.Labels[podSecurityEnforceLabel]; level {
+		case "baseline", "restricted":
+			warnings = append(warnings, fmt.Sprintf("%s  namespace %s enforces the %s Pod Security Standard, which forbids hostPath volumes; pods replacing this one will be rejected", aurora.Red("✖").String(), pod.Namespace, level))
+		}
+	} else if describeForbidden(err) == "" {
+		return "", err
+	}
+
+	retval := aurora.Cyan("Host Paths:\n\n").String()
+	retval += sb.String()
+	if len(warnings) > 0 {
+		retval += "\n" + strings.Join(warnings, "\n") + "\n"
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func isHugePagesResource(name v1.ResourceName) bool {
+	return strings.HasPrefix(string(name), v1.ResourceHugePagesPrefix)
+}
+
+// getHugePagesRequests returns a container's hugepages-* requests, taking an unset request to be
+// the limit, as the API server defaults it.
+func getHugePagesRequests(c v1.Container) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for name, limit := range c.Resources.Limits {
+		if isHugePagesResource(name) {
+			requests[name] = limit
+		}
+	}
+	for name, request := range c.Resources.Requests {
+		if isHugePagesResource(name) {
+			requests[name] = request
+		}
+	}
+	return requests
+}
+
+// getHugePagesValidation checks the pod's hugepages-* requests against their limits and against
+// what the node actually allocates.  Mismatches here otherwise surface as scheduling failures or
+// as containers that die at runtime with mmap errors.
+func (dp *podInspectCommand) getHugePagesValidation(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+
+	// init containers run one at a time before the others, so, like the scheduler, charge the
+	// pod the larger of its containers' total and its largest init container's request
+	podRequests := sumPodResources(pod, getHugePagesRequests)
+
+	type hugePagesRow struct {
+		container string
+		name      v1.ResourceName
+		request   resource.Quantity
+		limit     resource.Quantity
+	}
+	rows := []hugePagesRow{}
+
+	for _, c := range containers {
+		names := map[v1.ResourceName]bool{}
+		for name := range c.Resources.Requests {
+			if isHugePagesResource(name) {
+				names[name] = true
+			}
+		}
+		for name := range c.Resources.Limits {
+			if isHugePagesResource(name) {
+				names[name] = true
+			}
+		}
+
+		sortedNames := make([]string, 0, len(names))
+		for name := range names {
+			sortedNames = append(sortedNames, string(name))
+		}
+		sort.Strings(sortedNames)
+
+		for _, n := range sortedNames {
+			name := v1.ResourceName(n)
+			limit := c.Resources.Limits[name]
+			request, ok := c.Resources.Requests[name]
+			if !ok {
+				request = limit
+			}
+			rows = append(rows, hugePagesRow{container: c.Name, name: name, request: request, limit: limit})
+		}
+	}
+
+	volumeProblems := []string{}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir == nil || !strings.HasPrefix(string(vol.EmptyDir.Medium), string(v1.StorageMediumHugePages)) {
+			continue
+		}
+
+		// a medium of "HugePages-2Mi" needs hugepages-2Mi; a plain "HugePages" medium needs
+		// exactly one page size to be requested
+		pageSize := strings.TrimPrefix(strings.TrimPrefix(string(vol.EmptyDir.Medium), string(v1.StorageMediumHugePages)), "-")
+		if pageSize != "" {
+			name := v1.ResourceName(v1.ResourceHugePagesPrefix + pageSize)
+			if _, ok := podRequests[name]; !ok {
+				volumeProblems = append(volumeProblems, fmt.Sprintf("volume '%s' uses medium %s but no container requests %s", vol.Name, vol.EmptyDir.Medium, name))
+			}
+		} else if len(podRequests) == 0 {
+			volumeProblems = append(volumeProblems, fmt.Sprintf("volume '%s' uses medium %s but no container requests hugepages", vol.Name, vol.EmptyDir.Medium))
+		} else if len(podRequests) > 1 {
+			volumeProblems = append(volumeProblems, fmt.Sprintf("volume '%s' uses medium %s but multiple page sizes are requested; use HugePages-<size>", vol.Name, vol.EmptyDir.Medium))
+		}
+	}
+
+	if len(rows) == 0 && len(volumeProblems) == 0 {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil {
+		return "", err
+	}
+
+	retval += aurora.Cyan("HugePages:\n\n").String()
+
+	if len(rows) > 0 {
+		sb := &strings.Builder{}
+		tw := dp.newTablewriter(sb)
+
+		tw.Append([]string{
+			aurora.Yellow("Container").String(),
+			aurora.Yellow("Resource").String(),
+			aurora.Yellow("Request").String(),
+			aurora.Yellow("Limit").String(),
+			aurora.Yellow("Node Allocatable").String(),
+			aurora.Yellow("Status").String(),
+		})
+
+		for _, row := range rows {
+			allocatable := "n/a"
+			status := aurora.Green("✔").String()
+
+			if !row.request.Equal(row.limit) {
+				status = fmt.Sprintf("%s  request does not match limit", aurora.Red("✖").String())
+			} else if node != nil {
+				nodeAllocatable, ok := node.Status.Allocatable[row.name]
+				allocatable = nodeAllocatable.String()
+				podTotal := podRequests[row.name]
+				if !ok || nodeAllocatable.IsZero() {
+					allocatable = "0"
+					status = fmt.Sprintf("%s  node %s does not allocate %s", aurora.Red("✖").String(), node.Name, row.name)
+				} else if nodeAllocatable.Cmp(podTotal) < 0 {
+					status = fmt.Sprintf("%s  pod requests %s in total, more than the node allocates", aurora.Red("✖").String(), podTotal.String())
+				}
+			}
+
+			tw.Append([]string{
+				row.container,
+				string(row.name),
+				row.request.String(),
+				row.limit.String(),
+				allocatable,
+				status,
+			})
+		}
+		tw.Render()
+		retval += sb.String()
+	}
+
+	for _, problem := range volumeProblems {
+		retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), problem)
+	}
+
+	return retval, nil
+}
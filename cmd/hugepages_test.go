@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func hugePagesContainer(name, request, limit string) v1.Container {
+	c := v1.Container{Name: name, Resources: v1.ResourceRequirements{Requests: v1.ResourceList{}, Limits: v1.ResourceList{}}}
+	if request != "" {
+		c.Resources.Requests["hugepages-2Mi"] = resource.MustParse(request)
+	}
+	if limit != "" {
+		c.Resources.Limits["hugepages-2Mi"] = resource.MustParse(limit)
+	}
+	c.Resources.Requests[v1.ResourceCPU] = resource.MustParse("100m")
+	return c
+}
+
+func TestGetHugePagesRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		request, limit string
+		want           string
+	}{
+		{"request", "4Mi", "4Mi", "4Mi"},
+		{"request defaults to the limit", "", "8Mi", "8Mi"},
+		{"none", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := getHugePagesRequests(hugePagesContainer("app", tt.request, tt.limit))
+			if _, ok := requests[v1.ResourceCPU]; ok {
+				t.Errorf("getHugePagesRequests() = %v, want only hugepages", requests)
+			}
+			got := ""
+			if q, ok := requests["hugepages-2Mi"]; ok {
+				got = q.String()
+			}
+			if got != tt.want {
+				t.Errorf("getHugePagesRequests() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHugePagesValidation(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Allocatable: v1.ResourceList{"hugepages-2Mi": resource.MustParse("10Mi")}},
+	}
+
+	tests := []struct {
+		name  string
+		spec  v1.PodSpec
+		want  []string
+		wantN []string
+	}{
+		{
+			name: "no hugepages",
+			spec: v1.PodSpec{NodeName: "node-1", Containers: []v1.Container{{Name: "app"}}},
+		},
+		{
+			name:  "fits",
+			spec:  v1.PodSpec{NodeName: "node-1", Containers: []v1.Container{hugePagesContainer("app", "4Mi", "4Mi")}},
+			want:  []string{"HugePages:", "10Mi"},
+			wantN: []string{"✖"},
+		},
+		{
+			name: "request does not match limit",
+			spec: v1.PodSpec{NodeName: "node-1", Containers: []v1.Container{hugePagesContainer("app", "2Mi", "4Mi")}},
+			want: []string{"request does not match limit"},
+		},
+		{
+			name: "more than the node allocates",
+			spec: v1.PodSpec{NodeName: "node-1", Containers: []v1.Container{hugePagesContainer("a", "6Mi", "6Mi"), hugePagesContainer("b", "6Mi", "6Mi")}},
+			want: []string{"pod requests 12Mi in total, more than the node allocates"},
+		},
+		{
+			name: "init containers don't add up",
+			spec: v1.PodSpec{
+				NodeName:       "node-1",
+				InitContainers: []v1.Container{hugePagesContainer("init-1", "8Mi", "8Mi"), hugePagesContainer("init-2", "8Mi", "8Mi")},
+				Containers:     []v1.Container{hugePagesContainer("app", "4Mi", "4Mi")},
+			},
+			wantN: []string{"✖"},
+		},
+		{
+			name: "largest init container counts",
+			spec: v1.PodSpec{
+				NodeName:       "node-1",
+				InitContainers: []v1.Container{hugePagesContainer("init", "12Mi", "12Mi")},
+				Containers:     []v1.Container{hugePagesContainer("app", "4Mi", "4Mi")},
+			},
+			want: []string{"pod requests 12Mi in total"},
+		},
+		{
+			name: "volume page size not requested",
+			spec: v1.PodSpec{
+				NodeName:   "node-1",
+				Containers: []v1.Container{{Name: "app"}},
+				Volumes:    []v1.Volume{{Name: "pages", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: "HugePages-1Gi"}}}},
+			},
+			want: []string{"volume 'pages' uses medium HugePages-1Gi but no container requests hugepages-1Gi"},
+		},
+		{
+			name: "node doesn't allocate hugepages",
+			spec: v1.PodSpec{NodeName: "node-2", Containers: []v1.Container{hugePagesContainer("app", "4Mi", "4Mi")}},
+			want: []string{"node node-2 does not allocate hugepages-2Mi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &podInspectCommand{nodes: map[string]*v1.Node{
+				"node-1": node,
+				"node-2": {ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+			}}
+			got, err := dp.getHugePagesValidation(&v1.Pod{Spec: tt.spec})
+			if err != nil {
+				t.Fatalf("getHugePagesValidation() error = %v", err)
+			}
+			if len(tt.want) == 0 && len(tt.wantN) == 0 && got != "" {
+				t.Errorf("getHugePagesValidation() = %q, want no section", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("getHugePagesValidation() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.wantN {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("getHugePagesValidation() = %q, want it not to contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
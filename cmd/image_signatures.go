@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the kinds of artifact cosign attaches to an image, stored next to it in its repository under
+// a tag named for the image's digest, e.g. sha256-<hex>.sig
+var cosignArtifactTypes = []struct {
+	Suffix string
+	Name   string
+}{
+	{".sig", "Signature"},
+	{".att", "Attestations"},
+	{".sbom", "SBOM"},
+}
+
+// cosignArtifacts is the cached result of looking up an image's cosign artifacts in its
+// registry, keyed by tag suffix.
+type cosignArtifacts struct {
+	found map[string]bool
+	err   error
+}
+
+// getCosignTag returns the tag cosign stores an artifact of the image with this digest under.
+func getCosignTag(digest, suffix string) string {
+	return strings.Replace(digest, ":", "-", 1) + suffix
+}
+
+// getCosignArtifacts looks up which cosign artifacts an image digest has in its registry.
+// Lookups are cached for the lifetime of the command, like those of image platforms.
+func (dp *podInspectCommand) getCosignArtifacts(secrets []pullSecret, ref imageReference) (map[string]bool, error) {
+	key := ref.String()
+	if cached, ok := dp.cosignArtifacts[key]; ok {
+		return cached.found, cached.err
+	}
+
+	rc, _ := newPullSecretRegistryClient(secrets, ref)
+	found := map[string]bool{}
+	var err error
+	for _, a := range cosignArtifactTypes {
+		tagRef := ref
+		tagRef.Reference = getCosignTag(ref.Reference, a.Suffix)
+		if found[a.Suffix], err = rc.hasManifest(context.Background(), tagRef); err != nil {
+			found = nil
+			break
+		}
+	}
+
+	if dp.cosignArtifacts == nil {
+		dp.cosignArtifacts = map[string]cosignArtifacts{}
+	}
+	dp.cosignArtifacts[key] = cosignArtifacts{found: found, err: err}
+	return found, err
+}
+
+// getImageSignatures looks up, for --image-signatures, the cosign signatures, attestations and
+// SBOMs of the image digests the pod's containers are running, and calls out the unsigned
+// images.  Only the presence of a signature is checked, not who signed it: that takes the
+// signer's keys or identity, which is what cosign verify is for.
+func (dp *podInspectCommand) getImageSignatures(pod *v1.Pod) (string, error) {
+	if !dp.imageSignatures {
+		return "", nil
+	}
+
+	// the registry is asked anonymously unless the pull secrets may be used
+	var secrets []pullSecret
+	if dp.validatePullSecrets {
+		var err error
+		secrets, err = dp.getPullSecrets(pod)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	retval := aurora.Cyan("Image Signatures:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	header := []string{aurora.Yellow("Container").String(), aurora.Yellow("Image Digest").String()}
+	for _, a := range cosignArtifactTypes {
+		header = append(header, aurora.Yellow(a.Name).String())
+	}
+	tw.Append(header)
+
+	unsigned := []string{}
+	check := func(c v1.Container, statuses []v1.ContainerStatus) {
+		image := getContainerImage(c, statuses)
+		if !strings.Contains(image, "@sha256:") {
+			tw.Append([]string{c.Name, c.Image, fmt.Sprintf("%s  not pulled yet; no digest to look up", aurora.Yellow("…").String()), "", ""})
+			return
+		}
+
+		ref, err := parseImageReference(image)
+		if err != nil {
+			tw.Append([]string{c.Name, image, fmt.Sprintf("%s  %v", aurora.Red("✖").String(), err), "", ""})
+			return
+		}
+
+		found, err := dp.getCosignArtifacts(secrets, ref)
+		if err != nil {
+			reason := fmt.Sprintf("registry not reachable from here: %v", err)
+			if _, ok := err.(*registryError); ok {
+				reason = err.Error()
+			}
+			tw.Append([]string{c.Name, ref.String(), fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), reason), "", ""})
+			return
+		}
+
+		row := []string{c.Name, ref.String()}
+		for _, a := range cosignArtifactTypes {
+			switch {
+			case found[a.Suffix]:
+				row = append(row, fmt.Sprintf("%s  found", aurora.Green("✔").String()))
+			case a.Suffix == ".sig":
+				row = append(row, fmt.Sprintf("%s  unsigned", aurora.Red("✖").String()))
+				unsigned = append(unsigned, c.Name)
+			default:
+				row = append(row, "none")
+			}
+		}
+		tw.Append(row)
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		check(c, pod.Status.InitContainerStatuses)
+	}
+	for _, c := range pod.Spec.Containers {
+		check(c, pod.Status.ContainerStatuses)
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if len(unsigned) > 0 {
+		retval += fmt.Sprintf("\n%s  unsigned images in containers %s; if images must be signed here, the admission policy that should enforce it isn't\n", aurora.Red("✖").String(), strings.Join(unsigned, ", "))
+	}
+	retval += fmt.Sprintf("\n%s  signatures are looked up, not verified; cosign verify checks them against the signer's key or identity\n", aurora.Yellow("…").String())
+
+	return retval, nil
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// the indexes kept over the pod events informer's store, matching the two ways listPodEvents
+// looks a pod's events up
+const (
+	eventPodUIDIndex  = "podUID"
+	eventPodNameIndex = "podName"
+)
+
+// how long to wait for the informers' initial LIST before giving up on the watch
+const informerSyncTimeout = 30 * time.Second
+
+var eventIndexers = cache.Indexers{
+	eventPodUIDIndex: func(obj interface{}) ([]string, error) {
+		return []string{string(obj.(*v1.Event).InvolvedObject.UID)}, nil
+	},
+	eventPodNameIndex: func(obj interface{}) ([]string, error) {
+		o := obj.(*v1.Event).InvolvedObject
+		return []string{o.Namespace + "/" + o.Name}, nil
+	},
+}
+
+// watchInformers keeps, for --watch, the pods being watched and the pod events of their
+// namespace in memory, from shared informers that LIST once and then WATCH for changes, so that
+// each poll reads them locally instead of listing them again.  Pods are kept unstructured so that
+// their raw JSON, with the fields this client's API types don't know about, can be reproduced.
+type watchInformers struct {
+	pods   cache.SharedIndexInformer
+	events cache.SharedIndexInformer
+	stop   chan struct{}
+	denied chan error
+}
+
+// startWatchInformers starts the pod and event informers for the watch and waits for their
+// initial LIST to finish.  The pods informer is filtered like forEachPod's LIST, or down to the
+// named pod.
+func (dp *podInspectCommand) startWatchInformers(args []string) (*watchInformers, error) {
+	dynamicClient, err := dp.f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := dp.listNamespace()
+	fieldSelector := dp.podListFieldSelector()
+	labelSelector := dp.selector
+	if len(args) == 1 {
+		namespace = dp.namespace
+		fieldSelector = fmt.Sprintf("metadata.name=%s", args[0])
+		labelSelector = ""
+	}
+
+	w := &watchInformers{stop: make(chan struct{}), denied: make(chan error, 1)}
+
+	podsClient := dynamicClient.Resource(v1.SchemeGroupVersion.WithResource("pods")).Namespace(namespace)
+	w.pods = cache.NewSharedIndexInformer(w.reportDenied(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.LabelSelector = labelSelector
+			opts.FieldSelector = fieldSelector
+			return podsClient.List(context.Background(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.LabelSelector = labelSelector
+			opts.FieldSelector = fieldSelector
+			return podsClient.Watch(context.Background(), opts)
+		},
+	}), &unstructured.Unstructured{}, 0, cache.Indexers{})
+
+	eventsClient := dp.clientset.CoreV1().Events(namespace)
+	w.events = cache.NewSharedIndexInformer(w.reportDenied(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = "involvedObject.kind=Pod"
+			return eventsClient.List(context.Background(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = "involvedObject.kind=Pod"
+			return eventsClient.Watch(context.Background(), opts)
+		},
+	}), &v1.Event{}, 0, eventIndexers)
+
+	if err := w.run(informerSyncTimeout); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// reportDenied passes on the first LIST or WATCH that the user isn't allowed to make.  The
+// informers would only log it and retry forever, and the error they log has lost its status.
+func (w *watchInformers) reportDenied(lw *cache.ListWatch) *cache.ListWatch {
+	report := func(err error) {
+		if apierrors.IsForbidden(err) {
+			select {
+			case w.denied <- err:
+			default:
+			}
+		}
+	}
+
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			obj, err := lw.ListFunc(opts)
+			report(err)
+			return obj, err
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			watcher, err := lw.WatchFunc(opts)
+			report(err)
+			return watcher, err
+		},
+	}
+}
+
+// run starts the informers and waits for them to sync, for at most timeout, or until one of
+// them is denied its LIST or WATCH, which is reported as the permission that is missing.
+func (w *watchInformers) run(timeout time.Duration) error {
+	go w.pods.Run(w.stop)
+	go w.events.Run(w.stop)
+
+	giveUp := make(chan struct{})
+	synced := make(chan struct{})
+	var denied error
+	go func() {
+		defer close(giveUp)
+		select {
+		case denied = <-w.denied:
+		case <-time.After(timeout):
+		case <-synced:
+		}
+	}()
+
+	ok := cache.WaitForCacheSync(giveUp, w.pods.HasSynced, w.events.HasSynced)
+	close(synced)
+	<-giveUp
+	if ok {
+		return nil
+	}
+	close(w.stop)
+
+	if note := describeForbidden(denied); note != "" {
+		return fmt.Errorf("can't watch the pods and their events: %s", note)
+	}
+	return fmt.Errorf("timed out after %s waiting for the pod and event informers to sync", timeout)
+}
+
+// decodePod converts a pod from the informer's store to the typed pod and its raw JSON, as
+// getPod returns them.
+func decodePod(obj interface{}) (*v1.Pod, []byte, error) {
+	rawPod, err := obj.(*unstructured.Unstructured).MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pod := &v1.Pod{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, pod); err != nil {
+		return nil, nil, err
+	}
+
+	return pod, rawPod, nil
+}
+
+// getPod returns the named pod from the store, or a NotFound error like the API server's.
+func (w *watchInformers) getPod(namespace, name string) (*v1.Pod, []byte, error) {
+	obj, exists, err := w.pods.GetStore().GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, apierrors.NewNotFound(v1.Resource("pods"), name)
+	}
+	return decodePod(obj)
+}
+
+// listPods returns the pods in the store, ordered by namespace and name as a LIST would be.
+func (w *watchInformers) listPods() ([]*v1.Pod, error) {
+	pods := []*v1.Pod{}
+	for _, obj := range w.pods.GetStore().List() {
+		pod, _, err := decodePod(obj)
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	return pods, nil
+}
+
+// getPodEvents returns the pod's events from the store, oldest first: those of this pod, or with
+// --include-prior-events, of every pod that has had its name.
+func (w *watchInformers) getPodEvents(pod *v1.Pod, includePrior bool) ([]v1.Event, error) {
+	index, key := eventPodUIDIndex, string(pod.UID)
+	if includePrior {
+		index, key = eventPodNameIndex, pod.Namespace+"/"+pod.Name
+	}
+	objs, err := w.events.GetIndexer().ByIndex(index, key)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []v1.Event{}
+	for _, obj := range objs {
+		events = append(events, *obj.(*v1.Event))
+	}
+	// the index keeps them in no particular order, unlike a LIST; oldest first
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := getEventTimestamp(events[i]), getEventTimestamp(events[j])
+		return a.Before(&b)
+	})
+	return events, nil
+}
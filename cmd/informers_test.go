@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newTestInformers returns informers whose stores are filled by hand rather than from a LIST.
+func newTestInformers(t *testing.T, pods []*v1.Pod, events []*v1.Event) *watchInformers {
+	w := &watchInformers{
+		pods:   cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{}),
+		events: cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1.Event{}, 0, eventIndexers),
+		stop:   make(chan struct{}),
+	}
+	for _, pod := range pods {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.pods.GetStore().Add(&unstructured.Unstructured{Object: obj}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, event := range events {
+		if err := w.events.GetStore().Add(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w
+}
+
+func testPod(namespace, name string, uid types.UID) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: uid}}
+}
+
+func testPodEvent(name string, pod *v1.Pod, lastSeen time.Time) *v1.Event {
+	return &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: pod.Namespace, Name: name},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID},
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
+
+func TestWatchInformersPods(t *testing.T) {
+	w := newTestInformers(t, []*v1.Pod{
+		testPod("prod", "web-b", "2"),
+		testPod("dev", "web-z", "3"),
+		testPod("prod", "web-a", "1"),
+	}, nil)
+
+	pod, raw, err := w.getPod("prod", "web-a")
+	if err != nil {
+		t.Fatalf("getPod() error = %v", err)
+	}
+	if pod.UID != "1" || !strings.Contains(string(raw), `"web-a"`) {
+		t.Errorf("getPod() = %s, %s, want pod web-a", pod.UID, raw)
+	}
+
+	if _, _, err := w.getPod("prod", "web-c"); !apierrors.IsNotFound(err) {
+		t.Errorf("getPod() of a missing pod error = %v, want NotFound", err)
+	}
+
+	pods, err := w.listPods()
+	if err != nil {
+		t.Fatalf("listPods() error = %v", err)
+	}
+	names := []string{}
+	for _, p := range pods {
+		names = append(names, p.Namespace+"/"+p.Name)
+	}
+	if got, want := strings.Join(names, " "), "dev/web-z prod/web-a prod/web-b"; got != want {
+		t.Errorf("listPods() = %s, want %s", got, want)
+	}
+}
+
+func TestWatchInformersPodEvents(t *testing.T) {
+	now := time.Now()
+	pod := testPod("prod", "web-0", "new")
+	prior := testPod("prod", "web-0", "old")
+	other := testPod("prod", "web-1", "other")
+
+	w := newTestInformers(t, nil, []*v1.Event{
+		testPodEvent("pulled", pod, now.Add(-time.Minute)),
+		testPodEvent("scheduled", pod, now.Add(-3*time.Minute)),
+		testPodEvent("killed", prior, now.Add(-5*time.Minute)),
+		testPodEvent("started", pod, now),
+		testPodEvent("unrelated", other, now),
+	})
+
+	tests := []struct {
+		name         string
+		includePrior bool
+		want         string
+	}{
+		{"this pod", false, "scheduled pulled started"},
+		{"with prior pods", true, "killed scheduled pulled started"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := w.getPodEvents(pod, tt.includePrior)
+			if err != nil {
+				t.Fatalf("getPodEvents() error = %v", err)
+			}
+			names := []string{}
+			for _, e := range events {
+				names = append(names, e.Name)
+			}
+			if got := strings.Join(names, " "); got != tt.want {
+				t.Errorf("getPodEvents() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchInformersRunForbidden(t *testing.T) {
+	denial := errors.New(`User "jane" cannot list resource "events" in API group "" in the namespace "default"`)
+	forbidden := &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return nil, apierrors.NewForbidden(v1.Resource("events"), "", denial)
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return nil, apierrors.NewForbidden(v1.Resource("events"), "", denial)
+		},
+	}
+	allowed := &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &unstructured.UnstructuredList{}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+	w := &watchInformers{stop: make(chan struct{}), denied: make(chan error, 1)}
+	w.pods = cache.NewSharedIndexInformer(w.reportDenied(allowed), &unstructured.Unstructured{}, 0, cache.Indexers{})
+	w.events = cache.NewSharedIndexInformer(w.reportDenied(forbidden), &v1.Event{}, 0, eventIndexers)
+
+	start := time.Now()
+	err := w.run(time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "permission denied for list events") {
+		t.Errorf("run() error = %v, want a permission denied note", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("run() took %s to give up on a forbidden LIST", elapsed)
+	}
+}
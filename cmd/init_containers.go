@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getBlockingInitContainer returns the name of the init container whose failure is keeping the
+// pod from initializing, if any.  Init containers run one at a time in spec order, so it's the
+// first one that hasn't completed successfully -- provided that one has actually failed.
+func getBlockingInitContainer(pod *v1.Pod) string {
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		cs, ok := statuses[c.Name]
+		if !ok {
+			return ""
+		}
+
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0 {
+			continue
+		}
+
+		_, _, podInspectStatus, _ := getContainerStateInfo(cs)
+		if podInspectStatus == PODINSPECT_STATUS_FAILED {
+			return c.Name
+		}
+		return ""
+	}
+
+	return ""
+}
+
+// getInitContainerTimeline renders the init containers in execution order with their start and
+// finish times and how long each one ran, so that a slow migration or a hung wait-for-dependency
+// step is obvious.
+func (dp *podInspectCommand) getInitContainerTimeline(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if len(pod.Spec.InitContainers) == 0 {
+		return "", nil
+	}
+
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+
+	retval += aurora.Cyan("Init Containers:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("#").String(),
+		aurora.Yellow("Name").String(),
+		aurora.Yellow("Started").String(),
+		aurora.Yellow("Finished").String(),
+		aurora.Yellow("Duration").String(),
+		aurora.Yellow("Result").String(),
+	})
+
+	for i, c := range pod.Spec.InitContainers {
+		started := "-"
+		finished := "-"
+		duration := "-"
+		result := aurora.Yellow("not started").String()
+
+		if cs, ok := statuses[c.Name]; ok {
+			state := cs.State
+			if state.Terminated != nil {
+				started = state.Terminated.StartedAt.String()
+				finished = state.Terminated.FinishedAt.String()
+				duration = formatDuration(state.Terminated.StartedAt, state.Terminated.FinishedAt)
+				if state.Terminated.ExitCode == 0 {
+					result = aurora.Green(state.Terminated.Reason).String()
+				} else {
+					result = aurora.Red(fmt.Sprintf("%s (%d)", state.Terminated.Reason, state.Terminated.ExitCode)).String()
+				}
+			} else if state.Running != nil {
+				started = state.Running.StartedAt.String()
+				duration = aurora.Yellow(formatDuration(state.Running.StartedAt, metav1.Time{})).String()
+				result = aurora.Yellow("running").String()
+			} else if state.Waiting != nil {
+				// an init container waiting to be retried still tells us how long its last attempt ran
+				if lts := cs.LastTerminationState.Terminated; lts != nil {
+					started = lts.StartedAt.String()
+					finished = lts.FinishedAt.String()
+					duration = formatDuration(lts.StartedAt, lts.FinishedAt)
+				}
+				if state.Waiting.Reason != "" && state.Waiting.Reason != "PodInitializing" {
+					result = aurora.Red(state.Waiting.Reason).String()
+				}
+			}
+		}
+
+		tw.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			c.Name,
+			started,
+			finished,
+			duration,
+			result,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
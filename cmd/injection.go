@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// mount path of the service account token that the built-in ServiceAccount admission plugin adds
+// to every pod; it isn't a webhook mutation, so it is left out of the report
+const serviceAccountMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// knownInjector describes a mutating webhook by the names of what it adds to pods.  Names ending
+// in "*" match as prefixes.
+type knownInjector struct {
+	name        string
+	containers  []string
+	volumes     []string
+	env         []string
+	annotations []string
+}
+
+var knownInjectors = []knownInjector{
+	{name: "Istio", containers: []string{"istio-proxy", "istio-init", "istio-validation"}, volumes: []string{"istio-*", "istiod-ca-cert"}, annotations: []string{"sidecar.istio.io/status"}},
+	{name: "Linkerd", containers: []string{"linkerd-proxy", "linkerd-init", "linkerd-network-validator"}, volumes: []string{"linkerd-*"}, annotations: []string{"linkerd.io/proxy-version"}},
+	{name: "Vault Agent Injector", containers: []string{"vault-agent", "vault-agent-init"}, volumes: []string{"vault-*"}, annotations: []string{"vault.hashicorp.com/agent-inject-status"}},
+	{name: "Consul", containers: []string{"consul-dataplane", "consul-connect-*", "consul-sidecar"}, volumes: []string{"consul-connect-*"}, annotations: []string{"consul.hashicorp.com/connect-inject-status"}},
+	{name: "Kuma", containers: []string{"kuma-sidecar", "kuma-init"}, annotations: []string{"kuma.io/sidecar-injected"}},
+	{name: "Dapr", containers: []string{"daprd"}, annotations: []string{"dapr.io/enabled"}},
+	{name: "AWS App Mesh", containers: []string{"envoy", "proxyinit"}, annotations: []string{"appmesh.k8s.aws/sidecarInjectorWebhook"}},
+	{name: "EKS Pod Identity Webhook", volumes: []string{"aws-iam-token"}, env: []string{"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_STS_REGIONAL_ENDPOINTS", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_CONTAINER_*"}},
+	{name: "Azure Workload Identity", volumes: []string{"azure-identity-token"}, env: []string{"AZURE_CLIENT_ID", "AZURE_TENANT_ID", "AZURE_FEDERATED_TOKEN_FILE", "AZURE_AUTHORITY_HOST"}},
+	{name: "Datadog Admission Controller", containers: []string{"datadog-lib-*", "datadog-init-*"}, volumes: []string{"datadog*"}, env: []string{"DD_*"}},
+	{name: "OpenTelemetry Operator", containers: []string{"otc-container", "opentelemetry-auto-instrumentation*"}, volumes: []string{"opentelemetry-auto-instrumentation*"}, env: []string{"OTEL_*"}},
+}
+
+// injectedItem is something in the running pod that its controller's template doesn't have.
+type injectedItem struct {
+	kind      string
+	name      string
+	container string
+}
+
+func matchesInjectorName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") {
+			if strings.HasPrefix(name, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// attributeInjection names the known injector responsible for an injected item.  Injectors whose
+// annotations are on the pod are preferred, since names like "envoy" are generic.
+func attributeInjection(pod *v1.Pod, item injectedItem) string {
+	candidates := []knownInjector{}
+	for _, injector := range knownInjectors {
+		var patterns []string
+		switch item.kind {
+		case "container", "init container":
+			patterns = injector.containers
+		case "volume", "volume mount":
+			patterns = injector.volumes
+		case "env":
+			patterns = injector.env
+		}
+		if matchesInjectorName(patterns, item.name) {
+			candidates = append(candidates, injector)
+		}
+	}
+
+	for _, injector := range candidates {
+		for _, annotation := range injector.annotations {
+			if _, ok := pod.Annotations[annotation]; ok {
+				return injector.name
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0].name
+	}
+	return "unknown webhook"
+}
+
+// getControllerPodTemplate returns the pod template of the pod's controller, along with the
+// controller's kind and name, or nil if the pod has no controller with a template we can fetch.
+func (dp *podInspectCommand) getControllerPodTemplate(pod *v1.Pod) (*v1.PodTemplateSpec, string, error) {
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(chain) == 0 {
+		return nil, "", nil
+	}
+
+	owner := chain[0]
+	obj := struct {
+		Spec struct {
+			Template             *v1.PodTemplateSpec `json:"template"`
+			VolumeClaimTemplates []struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			} `json:"volumeClaimTemplates"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(owner.Raw, &obj); err != nil {
+		return nil, "", err
+	}
+
+	template := obj.Spec.Template
+	// a StatefulSet adds a volume to each pod for every claim template
+	if template != nil {
+		for _, claim := range obj.Spec.VolumeClaimTemplates {
+			template.Spec.Volumes = append(template.Spec.Volumes, v1.Volume{Name: claim.Metadata.Name})
+		}
+	}
+
+	return template, owner.Kind + "/" + owner.Name, nil
+}
+
+// getInjectedComponents compares the running pod against its controller's template and reports
+// the containers, volumes, and env vars that mutating admission webhooks added, attributed to
+// the known injectors where possible.  An injected sidecar that fails is a common reason a pod
+// won't start, and it's easy to overlook because it isn't in anybody's manifests.
+func (dp *podInspectCommand) getInjectedComponents(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	template, controller, err := dp.getControllerPodTemplate(pod)
+	if err != nil {
+		return "", err
+	}
+	if template == nil {
+		return "", nil
+	}
+
+	items := []injectedItem{}
+
+	templateContainers := map[string]v1.Container{}
+	for _, c := range template.Spec.InitContainers {
+		templateContainers[c.Name] = c
+	}
+	for _, c := range template.Spec.Containers {
+		templateContainers[c.Name] = c
+	}
+
+	addContainers := func(kind string, containers []v1.Container) {
+		for _, c := range containers {
+			tc, ok := templateContainers[c.Name]
+			if !ok {
+				items = append(items, injectedItem{kind: kind, name: c.Name})
+				continue
+			}
+
+			templateEnv := map[string]bool{}
+			for _, env := range tc.Env {
+				templateEnv[env.Name] = true
+			}
+			for _, env := range c.Env {
+				if !templateEnv[env.Name] {
+					items = append(items, injectedItem{kind: "env", name: env.Name, container: c.Name})
+				}
+			}
+
+			templateMounts := map[string]bool{}
+			for _, mount := range tc.VolumeMounts {
+				templateMounts[mount.Name] = true
+			}
+			for _, mount := range c.VolumeMounts {
+				if !templateMounts[mount.Name] && mount.MountPath != serviceAccountMountPath {
+					items = append(items, injectedItem{kind: "volume mount", name: mount.Name, container: c.Name})
+				}
+			}
+		}
+	}
+	addContainers("init container", pod.Spec.InitContainers)
+	addContainers("container", pod.Spec.Containers)
+
+	templateVolumes := map[string]bool{}
+	for _, vol := range template.Spec.Volumes {
+		templateVolumes[vol.Name] = true
+	}
+	serviceAccountVolumes := map[string]bool{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, mount := range c.VolumeMounts {
+			if mount.MountPath == serviceAccountMountPath {
+				serviceAccountVolumes[mount.Name] = true
+			}
+		}
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if !templateVolumes[vol.Name] && !serviceAccountVolumes[vol.Name] {
+			items = append(items, injectedItem{kind: "volume", name: vol.Name})
+		}
+	}
+
+	if len(items) == 0 {
+		return "", nil
+	}
+
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		statuses[cs.Name] = cs
+	}
+
+	retval += aurora.Cyan(fmt.Sprintf("Injected By Admission Webhooks (compared to %s):\n\n", controller)).String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Kind").String(),
+		aurora.Yellow("Name").String(),
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Injected By").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	failing := []string{}
+	for _, item := range items {
+		injector := attributeInjection(pod, item)
+
+		status := ""
+		if item.kind == "container" || item.kind == "init container" {
+			if cs, ok := statuses[item.name]; ok {
+				_, _, podInspectStatus, readyIcon := getContainerStateInfo(cs)
+				status = readyIcon
+				if podInspectStatus == PODINSPECT_STATUS_FAILED {
+					failing = append(failing, fmt.Sprintf("%s '%s' (%s)", item.kind, item.name, injector))
+				}
+			}
+		}
+
+		tw.Append([]string{
+			item.kind,
+			item.name,
+			item.container,
+			injector,
+			status,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	for _, f := range failing {
+		retval += fmt.Sprintf("\n%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("injected %s is failing; it isn't part of the workload's own spec", f)))
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// injectionConflict is a setting that two parts of the running pod both claim: an env var set
+// twice with different values, two mounts at overlapping paths, or two containers listening on
+// the same port.
+type injectionConflict struct {
+	kind       string
+	name       string
+	containers string
+	detail     string
+	injectedBy string
+}
+
+// templateHas reports whether the controller's template has a setting of the container, so that
+// a conflict can be put down to what was injected.  With no template, nothing is known to be
+// injected.
+func templateHas(template *v1.PodTemplateSpec, container string, has func(c v1.Container) bool) bool {
+	if template == nil {
+		return true
+	}
+	for _, c := range append(append([]v1.Container{}, template.Spec.InitContainers...), template.Spec.Containers...) {
+		if c.Name == container {
+			return has(c)
+		}
+	}
+	return false
+}
+
+// mountPathsOverlap reports whether one mount path is the other, or is inside it.
+func mountPathsOverlap(a, b string) bool {
+	a, b = strings.TrimSuffix(a, "/")+"/", strings.TrimSuffix(b, "/")+"/"
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// getEnvConflicts finds the env vars a container sets more than once with different values; the
+// last one wins, which is rarely what whoever set the first one meant.
+func getEnvConflicts(pod *v1.Pod, template *v1.PodTemplateSpec, c v1.Container) []injectionConflict {
+	conflicts := []injectionConflict{}
+
+	seen := map[string][]v1.EnvVar{}
+	names := []string{}
+	for _, e := range c.Env {
+		if _, ok := seen[e.Name]; !ok {
+			names = append(names, e.Name)
+		}
+		seen[e.Name] = append(seen[e.Name], e)
+	}
+
+	for _, name := range names {
+		envs := seen[name]
+		values := []string{}
+		distinct := map[string]bool{}
+		for _, e := range envs {
+			value := describeEnvVar(e)
+			values = append(values, value)
+			distinct[value] = true
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+
+		// the template setting the variable as many times as the pod does means nobody added to it
+		injectedBy := ""
+		if !templateHas(template, c.Name, func(tc v1.Container) bool {
+			n := 0
+			for _, e := range tc.Env {
+				if e.Name == name {
+					n++
+				}
+			}
+			return n >= len(envs)
+		}) {
+			injectedBy = attributeInjection(pod, injectedItem{kind: "env", name: name, container: c.Name})
+		}
+
+		conflicts = append(conflicts, injectionConflict{
+			kind:       "env",
+			name:       name,
+			containers: c.Name,
+			detail:     fmt.Sprintf("set %d times: %s; the last one wins", len(envs), strings.Join(values, ", ")),
+			injectedBy: injectedBy,
+		})
+	}
+
+	return conflicts
+}
+
+// getMountConflicts finds the container's volume mounts whose paths overlap, where one volume
+// hides the other, or part of it.
+func getMountConflicts(pod *v1.Pod, template *v1.PodTemplateSpec, c v1.Container) []injectionConflict {
+	conflicts := []injectionConflict{}
+
+	injectedMount := func(m v1.VolumeMount) bool {
+		return !templateHas(template, c.Name, func(tc v1.Container) bool {
+			for _, tm := range tc.VolumeMounts {
+				if tm.Name == m.Name && tm.MountPath == m.MountPath {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	for i, a := range c.VolumeMounts {
+		for _, b := range c.VolumeMounts[i+1:] {
+			if !mountPathsOverlap(a.MountPath, b.MountPath) {
+				continue
+			}
+			// a subPath mount of a file into a mounted directory is a common and deliberate layout
+			if a.MountPath != b.MountPath && (a.SubPath != "" || b.SubPath != "") {
+				continue
+			}
+
+			injectedBy := ""
+			for _, m := range []v1.VolumeMount{a, b} {
+				if injectedMount(m) {
+					injectedBy = attributeInjection(pod, injectedItem{kind: "volume mount", name: m.Name, container: c.Name})
+					break
+				}
+			}
+
+			detail := fmt.Sprintf("volumes %s and %s are both mounted at %s", a.Name, b.Name, a.MountPath)
+			if a.MountPath != b.MountPath {
+				outer, inner := a, b
+				if len(outer.MountPath) > len(inner.MountPath) {
+					outer, inner = inner, outer
+				}
+				detail = fmt.Sprintf("volume %s at %s hides part of volume %s at %s", inner.Name, inner.MountPath, outer.Name, outer.MountPath)
+			}
+
+			conflicts = append(conflicts, injectionConflict{
+				kind:       "volume mount",
+				name:       fmt.Sprintf("%s, %s", a.MountPath, b.MountPath),
+				containers: c.Name,
+				detail:     detail,
+				injectedBy: injectedBy,
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// getInjectedPortOwner returns who injected a container's port: the injector of the container,
+// if the container itself was injected, or an unknown webhook, if only the port was.  Returns ""
+// if the controller's template declares it.
+func getInjectedPortOwner(pod *v1.Pod, template *v1.PodTemplateSpec, container string, port v1.ContainerPort) string {
+	if !templateHas(template, container, func(v1.Container) bool { return true }) {
+		return attributeInjection(pod, injectedItem{kind: "container", name: container})
+	}
+	if !templateHas(template, container, func(tc v1.Container) bool {
+		for _, tp := range tc.Ports {
+			if tp.ContainerPort == port.ContainerPort {
+				return true
+			}
+		}
+		return false
+	}) {
+		return "unknown webhook"
+	}
+	return ""
+}
+
+// getPortConflicts finds the ports that more than one of the pod's containers declare.  The
+// containers of a pod share a network namespace, so only one of them can listen on a port; the
+// other fails to bind it.
+func getPortConflicts(pod *v1.Pod, template *v1.PodTemplateSpec) []injectionConflict {
+	conflicts := []injectionConflict{}
+
+	type portUser struct {
+		container string
+		port      v1.ContainerPort
+	}
+	users := map[string][]portUser{}
+	keys := []string{}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = v1.ProtocolTCP
+			}
+			key := fmt.Sprintf("%d/%s", p.ContainerPort, protocol)
+			if _, ok := users[key]; !ok {
+				keys = append(keys, key)
+			}
+			users[key] = append(users[key], portUser{container: c.Name, port: p})
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		containers := []string{}
+		seen := map[string]bool{}
+		injectedBy := ""
+		for _, u := range users[key] {
+			if !seen[u.container] {
+				seen[u.container] = true
+				containers = append(containers, u.container)
+			}
+			if injectedBy == "" {
+				injectedBy = getInjectedPortOwner(pod, template, u.container, u.port)
+			}
+		}
+		if len(containers) < 2 {
+			continue
+		}
+
+		conflicts = append(conflicts, injectionConflict{
+			kind:       "port",
+			name:       key,
+			containers: strings.Join(containers, ", "),
+			detail:     "declared by more than one container; they share the pod's network, so only one of them can listen on it",
+			injectedBy: injectedBy,
+		})
+	}
+
+	return conflicts
+}
+
+// getInjectionConflicts flags the settings of the running pod that clash: env vars set twice with
+// different values, overlapping mount paths, and container ports declared twice.  These usually
+// come from a mutating webhook injecting a sidecar, or env and mounts into the workload's own
+// containers, so they break the pod only after injection and can't be seen in its manifests; a
+// conflict is put down to the injector when the controller's template doesn't have one side of it.
+func (dp *podInspectCommand) getInjectionConflicts(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	template, _, err := dp.getControllerPodTemplate(pod)
+	if err != nil {
+		return "", err
+	}
+
+	conflicts := []injectionConflict{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		conflicts = append(conflicts, getEnvConflicts(pod, template, c)...)
+		conflicts = append(conflicts, getMountConflicts(pod, template, c)...)
+	}
+	conflicts = append(conflicts, getPortConflicts(pod, template)...)
+
+	if len(conflicts) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Conflicting Settings:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Kind").String(),
+		aurora.Yellow("Name").String(),
+		aurora.Yellow("Containers").String(),
+		aurora.Yellow("Injected By").String(),
+		aurora.Yellow("Conflict").String(),
+	})
+
+	for _, c := range conflicts {
+		icon := aurora.Yellow("…").String()
+		if c.kind == "port" {
+			icon = aurora.Red("✖").String()
+		}
+		tw.Append([]string{
+			c.kind,
+			c.name,
+			c.containers,
+			c.injectedBy,
+			fmt.Sprintf("%s  %s", icon, c.detail),
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMountPathsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"/etc/config", "/etc/config", true},
+		{"/etc/config/", "/etc/config", true},
+		{"/etc", "/etc/config", true},
+		{"/etc/config/app.yaml", "/etc/config", true},
+		{"/etc/config", "/etc/configs", false},
+		{"/data", "/var/data", false},
+	}
+	for _, tt := range tests {
+		if got := mountPathsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("mountPathsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGetEnvConflicts(t *testing.T) {
+	env := func(pairs ...string) []v1.EnvVar {
+		vars := []v1.EnvVar{}
+		for i := 0; i < len(pairs); i += 2 {
+			vars = append(vars, v1.EnvVar{Name: pairs[i], Value: pairs[i+1]})
+		}
+		return vars
+	}
+	template := func(vars []v1.EnvVar) *v1.PodTemplateSpec {
+		return &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "app", Env: vars}}}}
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+
+	tests := []struct {
+		name         string
+		env          []v1.EnvVar
+		template     *v1.PodTemplateSpec
+		wantNames    []string
+		wantInjector string
+	}{
+		{"no duplicates", env("A", "1", "B", "2"), nil, []string{}, ""},
+		{"same value twice", env("A", "1", "A", "1"), nil, []string{}, ""},
+		{"no template", env("A", "1", "A", "2"), nil, []string{"A"}, ""},
+		{"duplicated in the template", env("A", "1", "A", "2"), template(env("A", "1", "A", "2")), []string{"A"}, ""},
+		{"injected", env("OTEL_SERVICE_NAME", "app", "OTEL_SERVICE_NAME", "injected"), template(env("OTEL_SERVICE_NAME", "app")), []string{"OTEL_SERVICE_NAME"}, "OpenTelemetry Operator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := getEnvConflicts(pod, tt.template, v1.Container{Name: "app", Env: tt.env})
+			if len(conflicts) != len(tt.wantNames) {
+				t.Fatalf("getEnvConflicts() = %+v, want conflicts on %v", conflicts, tt.wantNames)
+			}
+			for i, c := range conflicts {
+				if c.name != tt.wantNames[i] || c.injectedBy != tt.wantInjector {
+					t.Errorf("getEnvConflicts()[%d] = %+v, want %s injected by %q", i, c, tt.wantNames[i], tt.wantInjector)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMountConflicts(t *testing.T) {
+	pod := &v1.Pod{}
+	tests := []struct {
+		name   string
+		mounts []v1.VolumeMount
+		want   []string
+	}{
+		{"separate", []v1.VolumeMount{{Name: "a", MountPath: "/a"}, {Name: "b", MountPath: "/b"}}, []string{}},
+		{"same path", []v1.VolumeMount{{Name: "a", MountPath: "/data"}, {Name: "b", MountPath: "/data"}}, []string{"volumes a and b are both mounted at /data"}},
+		{"nested", []v1.VolumeMount{{Name: "inner", MountPath: "/etc/app/certs"}, {Name: "outer", MountPath: "/etc/app"}}, []string{"volume inner at /etc/app/certs hides part of volume outer at /etc/app"}},
+		{"subPath file into a directory", []v1.VolumeMount{{Name: "config", MountPath: "/etc/app"}, {Name: "extra", MountPath: "/etc/app/extra.yaml", SubPath: "extra.yaml"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := getMountConflicts(pod, nil, v1.Container{Name: "app", VolumeMounts: tt.mounts})
+			if len(conflicts) != len(tt.want) {
+				t.Fatalf("getMountConflicts() = %+v, want %v", conflicts, tt.want)
+			}
+			for i, c := range conflicts {
+				if c.detail != tt.want[i] {
+					t.Errorf("getMountConflicts()[%d].detail = %q, want %q", i, c.detail, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetPortConflicts(t *testing.T) {
+	container := func(name string, ports ...v1.ContainerPort) v1.Container {
+		return v1.Container{Name: name, Ports: ports}
+	}
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{
+		container("app", v1.ContainerPort{ContainerPort: 8080}, v1.ContainerPort{ContainerPort: 53, Protocol: v1.ProtocolUDP}),
+		container("envoy", v1.ContainerPort{ContainerPort: 8080, Protocol: v1.ProtocolTCP}, v1.ContainerPort{ContainerPort: 53, Protocol: v1.ProtocolTCP}),
+	}}}
+	template := &v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{
+		container("app", v1.ContainerPort{ContainerPort: 8080}, v1.ContainerPort{ContainerPort: 53, Protocol: v1.ProtocolUDP}),
+	}}}
+
+	conflicts := getPortConflicts(pod, template)
+	if len(conflicts) != 1 {
+		t.Fatalf("getPortConflicts() = %+v, want one conflict on 8080/TCP", conflicts)
+	}
+	if c := conflicts[0]; c.name != "8080/TCP" || c.containers != "app, envoy" || c.injectedBy != "AWS App Mesh" {
+		t.Errorf("getPortConflicts()[0] = %+v, want 8080/TCP between app and envoy, injected by AWS App Mesh", c)
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchesInjectorName(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		name     string
+		want     bool
+	}{
+		{[]string{"istio-proxy", "istio-init"}, "istio-proxy", true},
+		{[]string{"istio-proxy"}, "istio-proxy-2", false},
+		{[]string{"linkerd-*"}, "linkerd-identity-end-entity", true},
+		{[]string{"linkerd-*"}, "linkerd", false},
+		{[]string{"datadog*"}, "datadog", true},
+		{nil, "anything", false},
+	}
+	for _, tt := range tests {
+		if got := matchesInjectorName(tt.patterns, tt.name); got != tt.want {
+			t.Errorf("matchesInjectorName(%v, %q) = %v, want %v", tt.patterns, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAttributeInjection(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		item        injectedItem
+		want        string
+	}{
+		{"sidecar", nil, injectedItem{kind: "container", name: "istio-proxy"}, "Istio"},
+		{"init container", nil, injectedItem{kind: "init container", name: "linkerd-init"}, "Linkerd"},
+		{"volume prefix", nil, injectedItem{kind: "volume", name: "vault-secrets"}, "Vault Agent Injector"},
+		{"volume mount", nil, injectedItem{kind: "volume mount", name: "aws-iam-token"}, "EKS Pod Identity Webhook"},
+		{"env", nil, injectedItem{kind: "env", name: "OTEL_EXPORTER_OTLP_ENDPOINT"}, "OpenTelemetry Operator"},
+		{"container name isn't an env name", nil, injectedItem{kind: "env", name: "istio-proxy"}, "unknown webhook"},
+		{"unknown", nil, injectedItem{kind: "container", name: "my-sidecar"}, "unknown webhook"},
+		{"generic name", nil, injectedItem{kind: "container", name: "envoy"}, "AWS App Mesh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got := attributeInjection(pod, tt.item); got != tt.want {
+				t.Errorf("attributeInjection(%+v) = %q, want %q", tt.item, got, tt.want)
+			}
+		})
+	}
+}
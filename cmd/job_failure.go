@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the Job controller's default spec.backoffLimit
+const defaultBackoffLimit = 6
+
+// jobPodFailurePolicy is the part of a Job we need to explain what happened to a failed pod:
+// its spec.podFailurePolicy and backoffLimit, and how many failures it has counted so far.
+type jobPodFailurePolicy struct {
+	Spec struct {
+		BackoffLimit     *int32 `json:"backoffLimit"`
+		PodFailurePolicy *struct {
+			Rules []podFailurePolicyRule `json:"rules"`
+		} `json:"podFailurePolicy"`
+	} `json:"spec"`
+	Status struct {
+		Failed int32 `json:"failed"`
+	} `json:"status"`
+}
+
+type podFailurePolicyRule struct {
+	Action      string `json:"action"`
+	OnExitCodes *struct {
+		ContainerName *string `json:"containerName"`
+		Operator      string  `json:"operator"`
+		Values        []int32 `json:"values"`
+	} `json:"onExitCodes"`
+	OnPodConditions []struct {
+		Type   string `json:"type"`
+		Status string `json:"status"`
+	} `json:"onPodConditions"`
+}
+
+// describeFailurePolicyRule renders a rule the way it reads in the Job spec.
+func describeFailurePolicyRule(rule podFailurePolicyRule) string {
+	parts := []string{}
+	if e := rule.OnExitCodes; e != nil {
+		values := []string{}
+		for _, v := range e.Values {
+			values = append(values, fmt.Sprintf("%d", v))
+		}
+		s := fmt.Sprintf("exit code %s [%s]", e.Operator, strings.Join(values, ","))
+		if e.ContainerName != nil {
+			s = fmt.Sprintf("container %s %s", *e.ContainerName, s)
+		}
+		parts = append(parts, s)
+	}
+	for _, c := range rule.OnPodConditions {
+		status := c.Status
+		if status == "" {
+			status = string(v1.ConditionTrue)
+		}
+		parts = append(parts, fmt.Sprintf("condition %s=%s", c.Type, status))
+	}
+	return strings.Join(parts, " or ")
+}
+
+// matchFailurePolicyRule reports whether a rule matches a failed pod, and what it matched: the
+// container and exit code, or the pod condition.  As in the Job controller, exit code rules only
+// look at containers that terminated with a non-zero exit code.
+func matchFailurePolicyRule(rule podFailurePolicyRule, pod *v1.Pod) (string, bool) {
+	if e := rule.OnExitCodes; e != nil {
+		for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			t := cs.State.Terminated
+			if t == nil || t.ExitCode == 0 {
+				continue
+			}
+			if e.ContainerName != nil && *e.ContainerName != cs.Name {
+				continue
+			}
+
+			in := false
+			for _, v := range e.Values {
+				if v == t.ExitCode {
+					in = true
+				}
+			}
+			if in == (e.Operator == "In") {
+				return fmt.Sprintf("container %s exited with %d", cs.Name, t.ExitCode), true
+			}
+		}
+	}
+
+	for _, rc := range rule.OnPodConditions {
+		status := rc.Status
+		if status == "" {
+			status = string(v1.ConditionTrue)
+		}
+		for _, c := range pod.Status.Conditions {
+			if string(c.Type) == rc.Type && string(c.Status) == status {
+				return fmt.Sprintf("pod condition %s is %s", c.Type, c.Status), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// describeFailurePolicyAction explains what a podFailurePolicy action did with a pod's failure.
+func describeFailurePolicyAction(action string, failed, backoffLimit int32) string {
+	switch action {
+	case "FailJob":
+		return fmt.Sprintf("%s  the Job was failed outright, without waiting for backoffLimit", aurora.Red("✖").String())
+	case "FailIndex":
+		return fmt.Sprintf("%s  the pod's index was failed, without retrying it", aurora.Red("✖").String())
+	case "Ignore":
+		return fmt.Sprintf("%s  the failure was ignored; it doesn't count against backoffLimit, and the pod is replaced", aurora.Green("✔").String())
+	}
+	return describeBackoffCount(failed, backoffLimit)
+}
+
+// describeBackoffCount explains a failure that counts against the Job's backoffLimit.
+func describeBackoffCount(failed, backoffLimit int32) string {
+	if failed > backoffLimit {
+		return fmt.Sprintf("%s  the failure counted against backoffLimit, which is used up (%d failed, limit %d)", aurora.Red("✖").String(), failed, backoffLimit)
+	}
+	return fmt.Sprintf("%s  the failure counted against backoffLimit (%d failed, limit %d)", aurora.Yellow("…").String(), failed, backoffLimit)
+}
+
+// getJobFailurePolicy explains, for a pod of a Job, how the Job's podFailurePolicy treats the
+// pod's failure: the rules in order, which of them matched the pod's exit codes or conditions,
+// and whether the failure counted against backoffLimit, was ignored, or failed the Job.  The
+// first matching rule wins; a failure that matches none is counted.
+func (dp *podInspectCommand) getJobFailurePolicy(pod *v1.Pod) (string, error) {
+	ref := getControllerOf(pod.ObjectMeta)
+	if ref == nil || ref.Kind != "Job" {
+		return "", nil
+	}
+
+	job, err := dp.getOwner(ref.Kind, ref.Name)
+	if err != nil || job == nil {
+		return "", err
+	}
+
+	obj := jobPodFailurePolicy{}
+	if err := json.Unmarshal(job.Raw, &obj); err != nil {
+		return "", err
+	}
+
+	backoffLimit := int32(defaultBackoffLimit)
+	if obj.Spec.BackoffLimit != nil {
+		backoffLimit = *obj.Spec.BackoffLimit
+	}
+
+	failed := pod.Status.Phase == v1.PodFailed
+	if obj.Spec.PodFailurePolicy == nil {
+		if !failed {
+			return "", nil
+		}
+		retval := aurora.Cyan("Job Failure Policy:\n\n").String()
+		retval += fmt.Sprintf("Job %s has no podFailurePolicy\n", job.Name)
+		retval += describeBackoffCount(obj.Status.Failed, backoffLimit) + "\n"
+		return retval, nil
+	}
+
+	retval := aurora.Cyan("Job Failure Policy:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Rule").String(),
+		aurora.Yellow("Action").String(),
+		aurora.Yellow("When").String(),
+		aurora.Yellow("Matched").String(),
+	})
+
+	action := ""
+	for i, rule := range obj.Spec.PodFailurePolicy.Rules {
+		matched := ""
+		if failed && action == "" {
+			if what, ok := matchFailurePolicyRule(rule, pod); ok {
+				action = rule.Action
+				matched = fmt.Sprintf("%s  %s", aurora.Red("✖").String(), what)
+			}
+		}
+		tw.Append([]string{
+			fmt.Sprintf("%d", i+1),
+			rule.Action,
+			describeFailurePolicyRule(rule),
+			matched,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if !failed {
+		return retval, nil
+	}
+
+	retval += "\n"
+	if action == "" {
+		retval += "no rule matched the pod's failure\n"
+	}
+	retval += describeFailurePolicyAction(action, obj.Status.Failed, backoffLimit) + "\n"
+
+	return retval, nil
+}
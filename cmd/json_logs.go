@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the keys structured loggers (zap, logrus, slog, bunyan, ECS, ...) put the time, the level and
+// the message under, in order of preference
+var (
+	jsonLogTimeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t"}
+	jsonLogLevelKeys   = []string{"level", "lvl", "severity", "log.level"}
+	jsonLogMessageKeys = []string{"msg", "message", "@message"}
+)
+
+// takeJSONLogField removes the first of the keys that is present from a log entry and returns
+// its value.
+func takeJSONLogField(entry map[string]interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		if value, ok := entry[key]; ok {
+			delete(entry, key)
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// formatJSONLogValue renders a field of a log entry: strings as they are, anything else as JSON.
+func formatJSONLogValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// formatJSONLogLine renders a structured log line as "time LEVEL message key=value ...", with
+// the remaining fields sorted by key.  Returns false for a line that isn't a JSON object, or has
+// no message, which is left as it is.
+func formatJSONLogLine(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return line, false
+	}
+
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(trimmed), &entry); err != nil {
+		return line, false
+	}
+	msg, ok := takeJSONLogField(entry, jsonLogMessageKeys)
+	if !ok {
+		return line, false
+	}
+
+	parts := []string{}
+	if t, ok := takeJSONLogField(entry, jsonLogTimeKeys); ok {
+		// zap writes seconds since the epoch by default
+		if seconds, isNumber := t.(float64); isNumber {
+			t = time.Unix(0, int64(seconds*float64(time.Second))).UTC().Format(time.RFC3339Nano)
+		}
+		parts = append(parts, formatJSONLogValue(t))
+	}
+	if level, ok := takeJSONLogField(entry, jsonLogLevelKeys); ok {
+		parts = append(parts, fmt.Sprintf("%-5s", strings.ToUpper(formatJSONLogValue(level))))
+	}
+	parts = append(parts, formatJSONLogValue(msg))
+
+	keys := make([]string, 0, len(entry))
+	for key := range entry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%s", key, formatJSONLogValue(entry[key])))
+	}
+
+	// the JSON may have escaped control characters that decoding has turned back into real ones
+	return sanitizeLogLine(strings.Join(parts, " ")), true
+}
+
+// formatJSONLogs pretty-prints the structured (JSON) lines of a log, unless --raw-logs; other
+// lines are left as they are.  With --log-timestamps, the kubelet's timestamp in front of each
+// line is kept.
+func (dp *podInspectCommand) formatJSONLogs(logs string) string {
+	if dp.rawLogs || logs == "" {
+		return logs
+	}
+
+	lines := strings.Split(strings.TrimSuffix(logs, "\n"), "\n")
+	for i, line := range lines {
+		prefix := ""
+		if dp.logTimestamps {
+			if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+				prefix, line = parts[0]+" ", parts[1]
+			}
+		}
+		if formatted, ok := formatJSONLogLine(line); ok {
+			lines[i] = prefix + formatted
+		}
+	}
+	return joinLogLines(lines)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Karpenter's Nominated event names the NodeClaim it is launching for the pod, e.g.
+// "Pod should schedule on: nodeclaim/default-x7k2p"
+var karpenterNominationRegexp = regexp.MustCompile(`nodeclaim/([a-z0-9.-]+)`)
+
+// the API versions NodeClaims have been served under, newest first
+var nodeClaimAPIVersions = []string{"v1", "v1beta1"}
+
+// nodeClaimStatus is the part of a Karpenter NodeClaim we report on; NodeClaims are a CRD, so
+// they're fetched as raw JSON.
+type nodeClaimStatus struct {
+	Status struct {
+		NodeName   string `json:"nodeName"`
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func isKarpenterEvent(event v1.Event) bool {
+	return event.Source.Component == "karpenter" || event.ReportingController == "karpenter"
+}
+
+// getKarpenterStatus shows, for a pod that hasn't been scheduled, what Karpenter is doing about
+// it: which NodeClaim it is launching for the pod and how far along that is, or why none of its
+// NodePools can satisfy the pod's requirements.
+func (dp *podInspectCommand) getKarpenterStatus(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if pod.Spec.NodeName != "" {
+		return "", nil
+	}
+
+	podEvents, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+
+	events := []v1.Event{}
+	for _, event := range podEvents {
+		if isKarpenterEvent(event) {
+			events = append(events, event)
+		}
+	}
+
+	// no sign of Karpenter in this cluster, or it hasn't looked at the pod yet
+	if len(events) == 0 {
+		return "", nil
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		a, b := getEventTimestamp(events[i]), getEventTimestamp(events[j])
+		return a.Before(&b)
+	})
+
+	nodeClaim := ""
+	for _, event := range events {
+		if m := karpenterNominationRegexp.FindStringSubmatch(event.Message); m != nil {
+			nodeClaim = m[1]
+		}
+	}
+
+	retval += aurora.Cyan("Karpenter:\n\n").String()
+
+	latest := events[len(events)-1]
+	if latest.Type == v1.EventTypeWarning {
+		retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("%s: %s", latest.Reason, latest.Message)))
+	} else if nodeClaim != "" {
+		retval += fmt.Sprintf("%s  provisioning NodeClaim %s for this pod\n", aurora.Yellow("…").String(), nodeClaim)
+	} else {
+		retval += fmt.Sprintf("%s  %s: %s\n", aurora.Yellow("…").String(), latest.Reason, latest.Message)
+	}
+
+	if nodeClaim != "" {
+		claim, err := dp.getNodeClaim(nodeClaim)
+		if err != nil {
+			return "", err
+		}
+		if claim == nil {
+			retval += fmt.Sprintf("%s  NodeClaim %s no longer exists; it may have failed to launch\n", aurora.Yellow("…").String(), nodeClaim)
+		} else {
+			conditions := []string{}
+			for _, condition := range claim.Status.Conditions {
+				conditions = append(conditions, fmt.Sprintf("%s=%s", condition.Type, condition.Status))
+				if condition.Status == string(v1.ConditionFalse) && condition.Message != "" {
+					retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("NodeClaim %s %s: %s", nodeClaim, condition.Type, condition.Message)))
+				}
+			}
+			if len(conditions) > 0 {
+				retval += fmt.Sprintf("NodeClaim %s: %s\n", nodeClaim, strings.Join(conditions, ", "))
+			}
+		}
+
+		nodeClaimEvents, err := dp.getNodeClaimEvents(nodeClaim)
+		if err != nil {
+			return "", err
+		}
+		events = append(events, nodeClaimEvents...)
+		sort.SliceStable(events, func(i, j int) bool {
+			a, b := getEventTimestamp(events[i]), getEventTimestamp(events[j])
+			return a.Before(&b)
+		})
+	}
+
+	if dp.numEvents > 0 && len(events) > dp.numEvents {
+		events = events[len(events)-dp.numEvents:]
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Last Seen").String(),
+		aurora.Yellow("Object").String(),
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, event := range events {
+		timestamp := getEventTimestamp(event)
+		tw.Append([]string{
+			dp.formatTimestamp(timestamp),
+			fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name),
+			event.Type,
+			event.Reason,
+			event.Message,
+		})
+	}
+	tw.Render()
+
+	retval += "\n" + sb.String()
+
+	return retval, nil
+}
+
+// getNodeClaim fetches a (cluster-scoped) Karpenter NodeClaim, trying each API version it has
+// been served under.  Returns nil if it doesn't exist.
+func (dp *podInspectCommand) getNodeClaim(name string) (*nodeClaimStatus, error) {
+	for _, version := range nodeClaimAPIVersions {
+		raw, err := dp.clientset.CoreV1().RESTClient().Get().AbsPath("/apis/karpenter.sh", version, "nodeclaims", name).Do(context.Background()).Raw()
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		claim := &nodeClaimStatus{}
+		if err := json.Unmarshal(raw, claim); err != nil {
+			return nil, err
+		}
+		return claim, nil
+	}
+
+	return nil, nil
+}
+
+// getNodeClaimEvents fetches the events recorded against a NodeClaim, which is where launch
+// failures (insufficient capacity, bad instance profiles, ...) show up.
+func (dp *podInspectCommand) getNodeClaimEvents(name string) ([]v1.Event, error) {
+	field := fmt.Sprintf("involvedObject.kind=NodeClaim,involvedObject.name=%s", name)
+	eventList, err := dp.clientset.CoreV1().Events("").List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return nil, err
+	}
+
+	return eventList.Items, nil
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// lintRules flag risky patterns in the pod spec.  None of them is wrong as such, which is why
+// they're recommendations, and why each can be switched off with --lint-rules.
+var lintRules = []rule{
+	{
+		Name:        "no-probes",
+		Description: "containers without a readiness or liveness probe",
+		Check:       lintNoProbes,
+	},
+	{
+		Name:        "no-limits",
+		Description: "containers without a memory limit",
+		Check:       lintNoLimits,
+	},
+	{
+		Name:        "latest-tag",
+		Description: "images using the latest tag, or no tag",
+		Check:       lintLatestTag,
+	},
+	{
+		Name:        "single-replica-pdb",
+		Description: "single-replica workloads whose disruption budget allows no disruptions",
+		Check:       lintSingleReplicaPDB,
+	},
+	{
+		Name:        "host-path",
+		Description: "volumes mounted from the node's filesystem",
+		Check:       lintHostPath,
+	},
+}
+
+func lintNoProbes(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	// batch containers run to completion; there's nothing to probe
+	if ref := getControllerOf(pod.ObjectMeta); ref != nil && ref.Kind == "Job" {
+		return nil, nil
+	}
+
+	findings := []string{}
+	for _, c := range pod.Spec.Containers {
+		if c.ReadinessProbe == nil && c.LivenessProbe == nil {
+			findings = append(findings, fmt.Sprintf("container %s has no readiness or liveness probe; it gets traffic as soon as it starts, and is never restarted if it hangs", c.Name))
+		}
+	}
+	return findings, nil
+}
+
+func lintNoLimits(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if _, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
+			continue
+		}
+		if _, ok := c.Resources.Limits[v1.ResourceCPU]; ok {
+			findings = append(findings, fmt.Sprintf("container %s has no memory limit; it can use up the node's memory and get other pods evicted", c.Name))
+		} else {
+			findings = append(findings, fmt.Sprintf("container %s has no resource limits; it can use up the node's memory and CPU, and get other pods evicted or starved", c.Name))
+		}
+	}
+	return findings, nil
+}
+
+func lintLatestTag(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		ref, err := parseImageReference(c.Image)
+		if err != nil || ref.Reference != "latest" {
+			continue
+		}
+		findings = append(findings, fmt.Sprintf("container %s runs %s, which uses the latest tag; replicas can end up on different versions, and there's no going back to the previous one", c.Name, c.Image))
+	}
+	return findings, nil
+}
+
+// podDisruptionBudget is the part of a PodDisruptionBudget the lint rules need.
+type podDisruptionBudget struct {
+	Metadata metav1.ObjectMeta `json:"metadata"`
+	Spec     struct {
+		MinAvailable   *intstr.IntOrString   `json:"minAvailable"`
+		MaxUnavailable *intstr.IntOrString   `json:"maxUnavailable"`
+		Selector       *metav1.LabelSelector `json:"selector"`
+	} `json:"spec"`
+}
+
+// getPodDisruptionBudgets lists the disruption budgets that cover the pod.  Budgets are fetched as
+// raw JSON, like controllers; see ownerObject.
+func (dp *podInspectCommand) getPodDisruptionBudgets(pod *v1.Pod) ([]podDisruptionBudget, error) {
+	raw, err := dp.clientset.PolicyV1beta1().RESTClient().Get().Namespace(pod.Namespace).Resource("poddisruptionbudgets").Do(context.Background()).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	list := struct {
+		Items []podDisruptionBudget `json:"items"`
+	}{}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+
+	pdbs := []podDisruptionBudget{}
+	for _, pdb := range list.Items {
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pdbs = append(pdbs, pdb)
+		}
+	}
+	return pdbs, nil
+}
+
+// allowsNoDisruption reports whether a budget forbids evicting the only replica of a workload:
+// minAvailable of 1 or more (or any percentage, which rounds up), or maxUnavailable of 0.
+func allowsNoDisruption(pdb podDisruptionBudget) bool {
+	value := func(v *intstr.IntOrString) int {
+		if v.Type == intstr.Int {
+			return int(v.IntVal)
+		}
+		n, _ := strconv.Atoi(strings.TrimSuffix(v.StrVal, "%"))
+		return n
+	}
+
+	if v := pdb.Spec.MaxUnavailable; v != nil {
+		return value(v) == 0
+	}
+	if v := pdb.Spec.MinAvailable; v != nil {
+		return value(v) > 0
+	}
+	return false
+}
+
+func lintSingleReplicaPDB(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil || len(chain) == 0 {
+		return nil, err
+	}
+	workload := chain[len(chain)-1]
+	switch workload.Kind {
+	case "Deployment", "StatefulSet", "ReplicaSet", "ReplicationController":
+	default:
+		return nil, nil
+	}
+
+	obj := struct {
+		Spec struct {
+			Replicas *int32 `json:"replicas"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(workload.Raw, &obj); err != nil {
+		return nil, err
+	}
+	if obj.Spec.Replicas != nil && *obj.Spec.Replicas != 1 {
+		return nil, nil
+	}
+
+	pdbs, err := dp.getPodDisruptionBudgets(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []string{}
+	for _, pdb := range pdbs {
+		if allowsNoDisruption(pdb) {
+			findings = append(findings, fmt.Sprintf("%s %s has a single replica, and PodDisruptionBudget %s allows no disruptions; draining the node blocks on this pod", workload.Kind, workload.Name, pdb.Metadata.Name))
+		}
+	}
+	return findings, nil
+}
+
+func lintHostPath(dp *podInspectCommand, pod *v1.Pod) ([]string, error) {
+	findings := []string{}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.HostPath != nil {
+			findings = append(findings, fmt.Sprintf("volume %s mounts %s from the node; the pod depends on what's on the node it lands on, and can change the node's files", vol.Name, vol.HostPath.Path))
+		}
+	}
+	return findings, nil
+}
+
+// getRecommendations runs the lint rules selected with --lint-rules against the pod spec.
+func (dp *podInspectCommand) getRecommendations(pod *v1.Pod) (string, error) {
+	if !dp.lint {
+		return "", nil
+	}
+
+	rules, err := selectRules(lintRules, dp.lintRules)
+	if err != nil {
+		return "", err
+	}
+
+	rows, err := dp.runRules(rules, pod)
+	if err != nil {
+		return "", err
+	}
+
+	retval := aurora.Cyan("Recommendations:\n\n").String()
+
+	if len(rows) == 0 {
+		retval += fmt.Sprintf("%s  nothing to recommend\n", aurora.Green("✔").String())
+		return retval, nil
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Rule").String(),
+		aurora.Yellow("Recommendation").String(),
+	})
+	for _, row := range rows {
+		tw.Append(row)
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+)
+
+// log levels, as written by the common logging libraries: a level= field (logfmt, logrus), an
+// upper-case token (log4j, python logging, and our own JSON layout), or the letter klog starts
+// its lines with (E0102 15:04:05.000000 ...)
+var (
+	logLevelFieldRegexp = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)=["']?([a-z]+)`)
+	logLevelTokenRegexp = regexp.MustCompile(`\b(FATAL|PANIC|CRITICAL|ERROR|ERR|WARNING|WARN)\b`)
+	klogLevelRegexp     = regexp.MustCompile(`^([EFW])\d{4} \d{2}:\d{2}:\d{2}`)
+)
+
+// the levels worth coloring: errors red and warnings yellow; lines at other levels are left alone
+var errorLogLevels = map[string]bool{"FATAL": true, "PANIC": true, "CRITICAL": true, "ERROR": true, "ERR": true, "E": true, "F": true}
+var warningLogLevels = map[string]bool{"WARNING": true, "WARN": true, "W": true}
+
+// getLogLevel finds the level a log line was written at, upper-cased, or "" if it has none that
+// we recognize.
+func getLogLevel(line string) string {
+	line = ansiEscapeRegexp.ReplaceAllString(line, "")
+	if m := logLevelFieldRegexp.FindStringSubmatch(line); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	if m := klogLevelRegexp.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	if m := logLevelTokenRegexp.FindStringSubmatch(line); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// colorizeLogLevels colors error lines red and warnings yellow, so they stand out from the rest
+// of a log the way a failed container does in the container table.  With --log-timestamps, the
+// kubelet's timestamp in front of each line is skipped.
+func (dp *podInspectCommand) colorizeLogLevels(logs string) string {
+	if logs == "" {
+		return logs
+	}
+
+	lines := strings.Split(strings.TrimSuffix(logs, "\n"), "\n")
+	for i, line := range lines {
+		prefix := ""
+		if dp.logTimestamps {
+			if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+				prefix, line = parts[0]+" ", parts[1]
+			}
+		}
+		level := getLogLevel(line)
+		var color colorFunc
+		switch {
+		case errorLogLevels[level]:
+			color = aurora.Red
+		case warningLogLevels[level]:
+			color = aurora.Yellow
+		default:
+			continue
+		}
+		lines[i] = prefix + color(line).String()
+	}
+	return joinLogLines(lines)
+}
@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// upper bound on the log output kept per container.  With --max-num-log-lines 0 the entire
+	// log is streamed from the kubelet, which can be gigabytes; only the most recent output that
+	// fits in this budget is kept.
+	maxLogBytes = 1024 * 1024
+
+	// individual lines longer than this are cut short
+	maxLogLineBytes = 16 * 1024
+)
+
+// logRequest identifies a container whose logs should be shown.
+type logRequest struct {
+	Status v1.ContainerStatus
+	Init   bool
+}
+
+// logResult is the outcome of fetching one container's logs.
+type logResult struct {
+	logs *containerLogs
+	err  error
+}
+
+// fetchContainerLogs starts fetching the logs of several of a pod's containers concurrently, each
+// with its own --log-timeout; a pod with many crashlooping sidecars would otherwise have to wait
+// on each slow log request in turn.  It returns right away with one channel per request, so the
+// rest of the report can be rendered while the logs are in flight.
+func (dp *podInspectCommand) fetchContainerLogs(podName string, requests []logRequest) []<-chan logResult {
+	results := make([]<-chan logResult, len(requests))
+	for i, req := range requests {
+		// buffered, so that the fetch can finish and exit even if nobody receives the result
+		result := make(chan logResult, 1)
+		results[i] = result
+		go func(req logRequest) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if dp.logTimeout > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), dp.logTimeout)
+			}
+			defer cancel()
+
+			logs, err := dp.getContainerLogs(ctx, podName, req.Status, req.Init)
+			if note := describeForbidden(err); note != "" {
+				logs, err = &containerLogs{ContainerName: req.Status.Name, Init: req.Init, Denied: note}, nil
+			}
+			result <- logResult{logs: logs, err: err}
+		}(req)
+	}
+
+	return results
+}
+
+// receiveContainerLogs calls fn with each container's logs, in the order they were requested, as
+// soon as they (and those before them) have arrived.  Containers that had no logs are skipped.
+func receiveContainerLogs(results []<-chan logResult, fn func(cl containerLogs) error) error {
+	for _, result := range results {
+		r := <-result
+		if r.err != nil {
+			return r.err
+		}
+		if r.logs == nil {
+			continue
+		}
+		if err := fn(*r.logs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getContainerLogsParallel fetches the logs of several containers concurrently and waits for all
+// of them, for callers that need the logs at once.
+func (dp *podInspectCommand) getContainerLogsParallel(podName string, requests []logRequest) ([]containerLogs, error) {
+	logs := []containerLogs{}
+	err := receiveContainerLogs(dp.fetchContainerLogs(podName, requests), func(cl containerLogs) error {
+		logs = append(logs, cl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// getContainerLogs fetches the logs for a container that isn't healthy.  A container that is
+// waiting to be restarted (CrashLoopBackOff, or an init container being retried) has no current
+// logs, so in that case we fall back to the logs of the instance that last terminated, which is
+// the one that actually failed.  If the context expires part way through, whatever was read is
+// kept and the result is marked as timed out; if the stream fails part way through, whatever was
+// read is kept along with the error, so that one broken stream doesn't cost the whole report.
+// Returns nil if there are no logs to show.
+func (dp *podInspectCommand) getContainerLogs(ctx context.Context, podName string, cs v1.ContainerStatus, init bool) (*containerLogs, error) {
+	readErr := ""
+	logs, dropped, err := dp.getPodLogs(ctx, podName, cs.Name, false)
+	if err != nil && ctx.Err() == nil {
+		if describeForbidden(err) != "" {
+			return nil, err
+		}
+		readErr = err.Error()
+	}
+
+	previous := false
+	if logs == "" && readErr == "" && ctx.Err() == nil && cs.State.Running == nil && cs.LastTerminationState.Terminated != nil {
+		logs, dropped, err = dp.getPodLogs(ctx, podName, cs.Name, true)
+		if err != nil && ctx.Err() == nil {
+			if describeForbidden(err) != "" {
+				return nil, err
+			}
+			readErr = err.Error()
+		}
+		previous = true
+	}
+
+	timedOut := ctx.Err() != nil
+	if logs == "" && !timedOut && readErr == "" {
+		return nil, nil
+	}
+
+	return &containerLogs{
+		ContainerName: cs.Name,
+		Init:          init,
+		Previous:      previous,
+		Logs:          logs,
+		Dropped:       dropped,
+		TimedOut:      timedOut,
+		Error:         readErr,
+	}, nil
+}
+
+// readLogTail streams a log line by line, keeping only the most recent lines that fit within
+// maxBytes.  It returns the retained output and the number of earlier lines that were dropped;
+// if reading fails part way, the output read so far is returned along with the error.
+func readLogTail(r io.Reader, maxBytes int) (string, int, error) {
+	reader := bufio.NewReader(r)
+
+	lines := []string{}
+	size := 0
+	dropped := 0
+
+	for {
+		line, err := readLogLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return joinLogLines(lines), dropped, err
+		}
+
+		line = sanitizeLogLine(line)
+		lines = append(lines, line)
+		size += len(line) + 1
+
+		for size > maxBytes && len(lines) > 1 {
+			size -= len(lines[0]) + 1
+			lines = lines[1:]
+			dropped++
+		}
+	}
+
+	return joinLogLines(lines), dropped, nil
+}
+
+func joinLogLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// readLogLine reads a single line, truncating it at maxLogLineBytes rather than buffering an
+// arbitrarily long line in memory.
+func readLogLine(reader *bufio.Reader) (string, error) {
+	line := []byte{}
+	truncated := false
+
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		if remaining := maxLogLineBytes - len(line); remaining > 0 {
+			if len(chunk) > remaining {
+				chunk = chunk[:remaining]
+				truncated = true
+			}
+			line = append(line, chunk...)
+		} else {
+			truncated = true
+		}
+
+		if !isPrefix {
+			break
+		}
+	}
+
+	if truncated {
+		line = append(line, " [truncated]"...)
+	}
+
+	return string(line), nil
+}
+
+// matches ANSI escape sequences: CSI sequences (colors, cursor movement, screen clearing), OSC
+// sequences (window titles, hyperlinks), and the remaining two-character escapes
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)?|\x1b[@-Z\\-_]`)
+
+// sanitizeLogLine strips ANSI escape sequences from a line of container output and makes any
+// other control characters visible, so that application output can't recolor the report, move
+// the cursor, or otherwise mess with the user's terminal.
+func sanitizeLogLine(line string) string {
+	line = ansiEscapeRegexp.ReplaceAllString(line, "")
+
+	sb := &strings.Builder{}
+	for _, r := range line {
+		switch {
+		case r == '\t':
+			sb.WriteRune(r)
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			sb.WriteString(fmt.Sprintf("\\x%02x", r))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// grepLogs keeps the captured log lines that match --log-grep, with --log-grep-context lines
+// around each, and highlights the matches; with --log-grep-highlight, every line is kept and the
+// matches are only highlighted.  Returns the lines and the number of matching lines.
+func (dp *podInspectCommand) grepLogs(logs string) (string, int) {
+	if dp.logGrepRegexp == nil || logs == "" {
+		return logs, 0
+	}
+
+	lines := strings.Split(strings.TrimSuffix(logs, "\n"), "\n")
+	keep := make([]bool, len(lines))
+	matches := 0
+	for i, line := range lines {
+		if !dp.logGrepRegexp.MatchString(line) {
+			continue
+		}
+		matches++
+		lines[i] = dp.logGrepRegexp.ReplaceAllStringFunc(line, func(m string) string {
+			return aurora.Bold(aurora.Red(m)).String()
+		})
+		for j := i - dp.logGrepContext; j <= i+dp.logGrepContext; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+
+	if dp.logGrepHighlight {
+		return joinLogLines(lines), matches
+	}
+
+	// separate the groups of lines that aren't next to each other, as grep does
+	kept := []string{}
+	for i, line := range lines {
+		if !keep[i] {
+			continue
+		}
+		if len(kept) > 0 && !keep[i-1] {
+			kept = append(kept, aurora.Cyan("--").String())
+		}
+		kept = append(kept, line)
+	}
+	return joinLogLines(kept), matches
+}
+
+// formatLogTimestamps renders the timestamps the kubelet prefixes log lines with for
+// --log-timestamps the way event times are shown, so that the two can be lined up.
+func (dp *podInspectCommand) formatLogTimestamps(logs string) string {
+	if !dp.logTimestamps || logs == "" {
+		return logs
+	}
+
+	lines := strings.Split(strings.TrimSuffix(logs, "\n"), "\n")
+	for i, line := range lines {
+		parts := strings.SplitN(line, " ", 2)
+		t, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			// an omission notice, or a line cut short
+			continue
+		}
+		rest := ""
+		if len(parts) == 2 {
+			rest = parts[1]
+		}
+		lines[i] = fmt.Sprintf("%s %s", aurora.Cyan(dp.formatTimestamp(metav1.NewTime(t))), rest)
+	}
+	return joinLogLines(lines)
+}
+
+// compileLogGrep compiles --log-grep and checks the flags that go with it.
+func (dp *podInspectCommand) compileLogGrep() error {
+	if dp.logGrep == "" {
+		if dp.logGrepHighlight || dp.logGrepContext != 0 {
+			return fmt.Errorf("--log-grep-highlight and --log-grep-context can only be used with --log-grep")
+		}
+		return nil
+	}
+	if dp.logGrepContext < 0 {
+		return fmt.Errorf("--log-grep-context can't be negative")
+	}
+
+	re, err := regexp.Compile(dp.logGrep)
+	if err != nil {
+		return fmt.Errorf("invalid --log-grep '%s': %v", dp.logGrep, err)
+	}
+	dp.logGrepRegexp = re
+	return nil
+}
+
+// validateLogWindow checks --since and --since-time, which narrow the captured logs down to a
+// time window.
+func validateLogWindow(since time.Duration, sinceTime string) error {
+	if since != 0 && sinceTime != "" {
+		return fmt.Errorf("only one of --since and --since-time can be used")
+	}
+	if since < 0 {
+		return fmt.Errorf("--since must be greater than 0")
+	}
+	// the API server takes the window in whole seconds, and a zero one means no window at all
+	if since > 0 && since < time.Second {
+		return fmt.Errorf("--since must be at least 1s")
+	}
+	if sinceTime != "" {
+		if _, err := time.Parse(time.RFC3339, sinceTime); err != nil {
+			return fmt.Errorf("invalid --since-time '%s'; expected an RFC3339 time, e.g. 2024-01-02T15:04:05Z", sinceTime)
+		}
+	}
+	return nil
+}
+
+// sanitizeText applies sanitizeLogLine to each line of a multi-line string.
+func sanitizeText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = sanitizeLogLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
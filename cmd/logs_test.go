@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSanitizeLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"plain", "GET /healthz 200", "GET /healthz 200"},
+		{"tab kept", "key\tvalue", "key\tvalue"},
+		{"color", "\x1b[31mERROR\x1b[0m failed", "ERROR failed"},
+		{"cursor movement", "\x1b[2J\x1b[1;1Hcleared", "cleared"},
+		{"window title", "\x1b]0;owned\x07after", "after"},
+		{"hyperlink", "\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"two-character escape", "\x1bMup", "up"},
+		{"other escape made visible", "\x1bcreset", "\\x1bcreset"},
+		{"carriage return", "progress\r100%", "progress\\x0d100%"},
+		{"bell", "ding\x07", "ding\\x07"},
+		{"delete", "a\x7fb", "a\\x7fb"},
+		{"C1 control", "a\u009bb", "a\\x9bb"},
+		{"unicode kept", "héllo wörld ✔", "héllo wörld ✔"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeLogLine(tt.line); got != tt.want {
+				t.Errorf("sanitizeLogLine(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingReader returns its data and then an error instead of io.EOF.
+type failingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, f.err
+	}
+	return n, err
+}
+
+func TestReadLogTail(t *testing.T) {
+	longLine := strings.Repeat("x", maxLogLineBytes+10)
+
+	tests := []struct {
+		name        string
+		input       string
+		maxBytes    int
+		want        string
+		wantDropped int
+	}{
+		{"empty", "", 100, "", 0},
+		{"fits", "one\ntwo\n", 100, "one\ntwo\n", 0},
+		{"no trailing newline", "one\ntwo", 100, "one\ntwo\n", 0},
+		{"earliest lines dropped", "one\ntwo\nthree\n", 10, "two\nthree\n", 1},
+		{"last line kept even if too long", "one\ntwelve chars\n", 5, "twelve chars\n", 1},
+		{"sanitized", "\x1b[32mok\x1b[0m\n", 100, "ok\n", 0},
+		{"long line truncated", longLine + "\n", 2 * maxLogLineBytes, strings.Repeat("x", maxLogLineBytes) + " [truncated]\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped, err := readLogTail(strings.NewReader(tt.input), tt.maxBytes)
+			if err != nil {
+				t.Fatalf("readLogTail() error = %v", err)
+			}
+			if got != tt.want || dropped != tt.wantDropped {
+				t.Errorf("readLogTail() = %q, %d, want %q, %d", got, dropped, tt.want, tt.wantDropped)
+			}
+		})
+	}
+
+	t.Run("read error keeps the output so far", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		got, _, err := readLogTail(&failingReader{r: strings.NewReader("one\ntwo\n"), err: readErr}, 100)
+		if !errors.Is(err, readErr) {
+			t.Errorf("readLogTail() error = %v, want %v", err, readErr)
+		}
+		if got != "one\ntwo\n" {
+			t.Errorf("readLogTail() = %q, want %q", got, "one\ntwo\n")
+		}
+	})
+}
+
+func TestSplitLogTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantTime string
+		wantRest string
+	}{
+		{"timestamped", "2020-10-01T12:00:00.123456789Z GET /healthz 200", "2020-10-01T12:00:00.123456789Z", "GET /healthz 200"},
+		{"empty line", "2020-10-01T12:00:00Z", "2020-10-01T12:00:00Z", ""},
+		{"no timestamp", "GET /healthz 200", "", "GET /healthz 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest := splitLogTimestamp(tt.line)
+			gotTime := ""
+			if !got.IsZero() {
+				gotTime = got.Format(time.RFC3339Nano)
+			}
+			if gotTime != tt.wantTime || rest != tt.wantRest {
+				t.Errorf("splitLogTimestamp(%q) = %q, %q, want %q, %q", tt.line, gotTime, rest, tt.wantTime, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestValidateLogWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		since     time.Duration
+		sinceTime string
+		wantErr   bool
+	}{
+		{"none", 0, "", false},
+		{"since", 10 * time.Minute, "", false},
+		{"one second", time.Second, "", false},
+		{"under a second", 500 * time.Millisecond, "", true},
+		{"negative", -time.Minute, "", true},
+		{"since time", 0, "2024-01-02T15:04:05Z", false},
+		{"invalid since time", 0, "yesterday", true},
+		{"both", time.Minute, "2024-01-02T15:04:05Z", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateLogWindow(tt.since, tt.sinceTime); (err != nil) != tt.wantErr {
+				t.Errorf("validateLogWindow(%v, %q) error = %v, wantErr %v", tt.since, tt.sinceTime, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the container fields whose managers are reported; these are the ones that usually matter when
+// asking "who changed this?"
+var attributedContainerFields = []string{"image", "resources", "env"}
+
+// fieldManager is one manager's claim on a container field.
+type fieldManager struct {
+	object    string
+	container string
+	field     string
+	manager   string
+	operation metav1.ManagedFieldsOperationType
+	time      metav1.Time
+}
+
+// getFieldManagers summarizes the managedFields of the pod and its controllers to show who last
+// set each container's image, resources, and env, answering "who changed this pod's image?".
+// The time shown is when the manager last touched the object, which isn't necessarily when it
+// changed that particular field.
+func (dp *podInspectCommand) getFieldManagers(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	managers := getContainerFieldManagers("Pod/"+pod.Name, pod.ManagedFields, []string{"f:spec"})
+
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil {
+		return "", err
+	}
+	for _, owner := range chain {
+		// CronJobs nest the pod template one level further down, inside the job template
+		specPath := []string{"f:spec", "f:template", "f:spec"}
+		if owner.Kind == "CronJob" {
+			specPath = []string{"f:spec", "f:jobTemplate", "f:spec", "f:template", "f:spec"}
+		}
+		managers = append(managers, getContainerFieldManagers(owner.Kind+"/"+owner.Name, owner.Metadata.ManagedFields, specPath)...)
+	}
+
+	if len(managers) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Field Managers:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Object").String(),
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Field").String(),
+		aurora.Yellow("Manager").String(),
+		aurora.Yellow("Operation").String(),
+		aurora.Yellow("Last Update").String(),
+	})
+
+	for _, m := range managers {
+		tw.Append([]string{
+			m.object,
+			m.container,
+			m.field,
+			m.manager,
+			string(m.operation),
+			formatAge(m.time),
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+// getContainerFieldManagers finds the managers of the attributed container fields in an object's
+// managedFields; specPath leads to the pod spec within the object's fieldsV1 tree.
+func getContainerFieldManagers(object string, entries []metav1.ManagedFieldsEntry, specPath []string) []fieldManager {
+	managers := []fieldManager{}
+
+	for _, entry := range entries {
+		if entry.FieldsV1 == nil {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+
+		spec := lookupFieldSet(fields, specPath)
+		if spec == nil {
+			continue
+		}
+
+		updated := metav1.Time{}
+		if entry.Time != nil {
+			updated = *entry.Time
+		}
+
+		for _, list := range []string{"f:initContainers", "f:containers"} {
+			containers, _ := spec[list].(map[string]interface{})
+			for key, value := range containers {
+				container := parseFieldSetItemName(key)
+				containerFields, _ := value.(map[string]interface{})
+				for _, field := range attributedContainerFields {
+					if _, ok := containerFields["f:"+field]; !ok {
+						continue
+					}
+					managers = append(managers, fieldManager{
+						object:    object,
+						container: container,
+						field:     field,
+						manager:   entry.Manager,
+						operation: entry.Operation,
+						time:      updated,
+					})
+				}
+			}
+		}
+	}
+
+	// most recent first within each field, so the likeliest culprit is at the top
+	sort.SliceStable(managers, func(i, j int) bool {
+		a, b := managers[i], managers[j]
+		if a.container != b.container {
+			return a.container < b.container
+		}
+		if a.field != b.field {
+			return a.field < b.field
+		}
+		return b.time.Before(&a.time)
+	})
+
+	return managers
+}
+
+// lookupFieldSet follows a path of "f:" keys down a fieldsV1 tree.
+func lookupFieldSet(fields map[string]interface{}, path []string) map[string]interface{} {
+	for _, key := range path {
+		next, ok := fields[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields = next
+	}
+	return fields
+}
+
+// parseFieldSetItemName extracts the name from a list item key such as `k:{"name":"app"}`.
+func parseFieldSetItemName(key string) string {
+	item := struct {
+		Name string `json:"name"`
+	}{}
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &item); err != nil || item.Name == "" {
+		return key
+	}
+	return item.Name
+}
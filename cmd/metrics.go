@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podMetrics is a pod's current resource usage, as reported by metrics-server.
+type podMetrics struct {
+	Metadata   metav1.ObjectMeta `json:"metadata"`
+	Timestamp  metav1.Time       `json:"timestamp"`
+	Window     string            `json:"window"`
+	Containers []struct {
+		Name  string          `json:"name"`
+		Usage v1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// getContainerUsage returns a container's usage of a resource, if there is a sample for it.
+func (m *podMetrics) getContainerUsage(container string, name v1.ResourceName) (resource.Quantity, bool) {
+	if m == nil {
+		return resource.Quantity{}, false
+	}
+	for _, c := range m.Containers {
+		if c.Name == container {
+			if q, ok := c.Usage[name]; ok {
+				return q, true
+			}
+		}
+	}
+	return resource.Quantity{}, false
+}
+
+// podMetricsList is the current resource usage of the pods in a namespace.
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+// getPodMetrics fetches the pod's current resource usage from the metrics API.  Returns nil if
+// metrics-server isn't installed, or has no sample of the pod yet.  When sweeping over many pods,
+// the usage of the whole namespace is listed once instead, like its events.
+func (dp *podInspectCommand) getPodMetrics(pod *v1.Pod) (*podMetrics, error) {
+	key := pod.Namespace + "/" + pod.Name
+	if metrics, ok := dp.podMetrics[key]; ok {
+		return metrics, nil
+	}
+	if dp.podMetrics == nil {
+		dp.podMetrics = map[string]*podMetrics{}
+	}
+
+	if dp.batchEvents {
+		namespace := pod.Namespace
+		if dp.allNamespaces {
+			namespace = ""
+		}
+		if err := dp.listPodMetrics(namespace); err != nil {
+			return nil, err
+		}
+		return dp.podMetrics[key], nil
+	}
+
+	raw, err := dp.clientset.CoreV1().RESTClient().Get().AbsPath("/apis/metrics.k8s.io/v1beta1", "namespaces", pod.Namespace, "pods", pod.Name).Do(context.Background()).Raw()
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			dp.podMetrics[key] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	metrics := &podMetrics{}
+	if err := json.Unmarshal(raw, metrics); err != nil {
+		return nil, err
+	}
+	dp.podMetrics[key] = metrics
+	return metrics, nil
+}
+
+// listPodMetrics fetches the usage of every pod in the namespace ("" for all of them) into the
+// cache, once.
+func (dp *podInspectCommand) listPodMetrics(namespace string) error {
+	if dp.podMetricsListed[namespace] {
+		return nil
+	}
+
+	path := []string{"/apis/metrics.k8s.io/v1beta1", "pods"}
+	if namespace != "" {
+		path = []string{"/apis/metrics.k8s.io/v1beta1", "namespaces", namespace, "pods"}
+	}
+	raw, err := dp.clientset.CoreV1().RESTClient().Get().AbsPath(path...).Do(context.Background()).Raw()
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsServiceUnavailable(err) {
+		return err
+	}
+
+	if err == nil {
+		list := &podMetricsList{}
+		if err := json.Unmarshal(raw, list); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			m := &list.Items[i]
+			dp.podMetrics[m.Metadata.Namespace+"/"+m.Metadata.Name] = m
+		}
+	}
+
+	if dp.podMetricsListed == nil {
+		dp.podMetricsListed = map[string]bool{}
+	}
+	dp.podMetricsListed[namespace] = true
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getNode fetches a node by name.  Nodes are cached for the lifetime of the command, since
+// sweeps over a namespace will usually find many pods scheduled on the same few nodes.
+func (dp *podInspectCommand) getNode(nodeName string) (*v1.Node, error) {
+	if node, ok := dp.nodes[nodeName]; ok {
+		return node, nil
+	}
+
+	node, err := dp.clientset.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.nodes == nil {
+		dp.nodes = map[string]*v1.Node{}
+	}
+	dp.nodes[nodeName] = node
+
+	return node, nil
+}
+
+// getScheduledNode fetches the node the pod is scheduled on.  Returns nil if the pod hasn't been
+// scheduled, or if its node has since been deleted (as happens to pods left behind by a scale
+// down or a spot reclaim).
+func (dp *podInspectCommand) getScheduledNode(pod *v1.Pod) (*v1.Node, error) {
+	if pod.Spec.NodeName == "" {
+		return nil, nil
+	}
+
+	node, err := dp.getNode(pod.Spec.NodeName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return node, nil
+}
+
+// listNodes lists the cluster's nodes.  Like single nodes, the list is cached for the lifetime of
+// the command, since every unscheduled pod of a sweep needs it.
+func (dp *podInspectCommand) listNodes() ([]v1.Node, error) {
+	if dp.nodeList != nil {
+		return dp.nodeList, nil
+	}
+
+	nodeList, err := dp.clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	dp.nodeList = append([]v1.Node{}, nodeList.Items...)
+	return dp.nodeList, nil
+}
+
+// getNodeEvents fetches the events recorded against a node.  Node events are cluster-scoped
+// (they may be recorded in any namespace), so this needs to list events across namespaces.  Like
+// nodes, they are cached for the lifetime of the command.
+func (dp *podInspectCommand) getNodeEvents(nodeName string) ([]v1.Event, error) {
+	if events, ok := dp.nodeEvents[nodeName]; ok {
+		return events, nil
+	}
+
+	field := fmt.Sprintf("involvedObject.kind=Node,involvedObject.name=%s", nodeName)
+	eventList, err := dp.clientset.CoreV1().Events("").List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.nodeEvents == nil {
+		dp.nodeEvents = map[string][]v1.Event{}
+	}
+	dp.nodeEvents[nodeName] = eventList.Items
+
+	return eventList.Items, nil
+}
+
+// getNodePods lists the pods that currently hold resources on a node, i.e. those that haven't run
+// to completion.  Like nodes, they are cached for the lifetime of the command.
+func (dp *podInspectCommand) getNodePods(nodeName string) ([]v1.Pod, error) {
+	if pods, ok := dp.nodePods[nodeName]; ok {
+		return pods, nil
+	}
+
+	field := fmt.Sprintf("spec.nodeName=%s,status.phase!=%s,status.phase!=%s", nodeName, v1.PodSucceeded, v1.PodFailed)
+	podList, err := dp.clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return nil, err
+	}
+
+	if dp.nodePods == nil {
+		dp.nodePods = map[string][]v1.Pod{}
+	}
+	dp.nodePods[nodeName] = podList.Items
+
+	return podList.Items, nil
+}
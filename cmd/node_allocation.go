@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// once the limits on a node add up to more than this share of its allocatable, pods bursting
+// together can run it out of memory
+const nodeLimitOvercommitWarnPercent = 150
+
+// the node conditions that mean the kubelet is about to evict pods, or already is
+var nodePressureConditions = []v1.NodeConditionType{v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure}
+
+// formatAllocationShare renders a quantity as a share of the node's allocatable, e.g. "3 (75%)".
+func formatAllocationShare(name v1.ResourceName, q, allocatable resource.Quantity) string {
+	if allocatable.MilliValue() <= 0 {
+		return formatHeadroomQuantity(name, q)
+	}
+	return fmt.Sprintf("%s (%d%%)", formatHeadroomQuantity(name, q), q.MilliValue()*100/allocatable.MilliValue())
+}
+
+// getNodeAllocation compares, with --node-allocation, what the pod requests against its node's
+// allocatable resources and what the other pods on the node have already committed: their
+// requests, which the scheduler reserves, and their limits, which they can burst to.  A pod that
+// fails on a node whose requests are full, or whose limits add up to far more than it has, is
+// failing because of its neighbors, which `kubectl describe node` would otherwise be needed for.
+func (dp *podInspectCommand) getNodeAllocation(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if !dp.nodeAllocation {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil || node == nil {
+		return "", err
+	}
+
+	nodePods, err := dp.getNodePods(node.Name)
+	if err != nil {
+		return "", err
+	}
+
+	otherRequests, nodeLimits := v1.ResourceList{}, v1.ResourceList{}
+	others := 0
+	for i := range nodePods {
+		limits, _ := getPodEffectiveLimits(&nodePods[i])
+		for name, q := range limits {
+			total := nodeLimits[name]
+			total.Add(q)
+			nodeLimits[name] = total
+		}
+		if nodePods[i].UID == pod.UID {
+			continue
+		}
+		others++
+		for name, q := range getPodEffectiveRequests(&nodePods[i]) {
+			total := otherRequests[name]
+			total.Add(q)
+			otherRequests[name] = total
+		}
+	}
+
+	podRequests := getPodEffectiveRequests(pod)
+
+	// cpu and memory always, and whatever else the pod requests, e.g. GPUs or ephemeral-storage
+	names := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+	extra := []string{}
+	for name := range podRequests {
+		if name != v1.ResourceCPU && name != v1.ResourceMemory {
+			extra = append(extra, string(name))
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		names = append(names, v1.ResourceName(name))
+	}
+
+	retval += aurora.Cyan(fmt.Sprintf("Node Allocation (%s, %d other pods):\n\n", node.Name, others)).String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Capacity").String(),
+		aurora.Yellow("Allocatable").String(),
+		aurora.Yellow("Other Pods' Requests").String(),
+		aurora.Yellow("This Pod").String(),
+		aurora.Yellow("Free").String(),
+		aurora.Yellow("All Limits").String(),
+	})
+
+	notes := []string{}
+	for _, name := range names {
+		capacity := node.Status.Capacity[name]
+		allocatable := node.Status.Allocatable[name]
+		requested := otherRequests[name]
+		podRequest := podRequests[name]
+
+		free := allocatable.DeepCopy()
+		free.Sub(requested)
+		free.Sub(podRequest)
+
+		freeCell := formatHeadroomQuantity(name, free)
+		if free.Sign() < 0 {
+			freeCell = aurora.Red(freeCell).String()
+			notes = append(notes, fmt.Sprintf("%s  the node's %s requests add up to more than it has allocatable; it is overcommitted", aurora.Red("✖").String(), name))
+		}
+
+		limitsCell := "-"
+		if limits, ok := nodeLimits[name]; ok {
+			limitsCell = formatAllocationShare(name, limits, allocatable)
+			if name == v1.ResourceMemory && allocatable.MilliValue() > 0 {
+				if percent := limits.MilliValue() * 100 / allocatable.MilliValue(); percent >= nodeLimitOvercommitWarnPercent {
+					limitsCell = aurora.Yellow(limitsCell).String()
+					notes = append(notes, fmt.Sprintf("%s  the memory limits on the node add up to %d%% of what it has allocatable; if its pods burst together, the kubelet evicts them", aurora.Yellow("…").String(), percent))
+				}
+			}
+		}
+
+		tw.Append([]string{
+			string(name),
+			formatHeadroomQuantity(name, capacity),
+			formatHeadroomQuantity(name, allocatable),
+			formatAllocationShare(name, requested, allocatable),
+			formatAllocationShare(name, podRequest, allocatable),
+			freeCell,
+			limitsCell,
+		})
+	}
+
+	if maxPods, ok := node.Status.Allocatable[v1.ResourcePods]; ok {
+		tw.Append([]string{
+			string(v1.ResourcePods),
+			formatHeadroomQuantity(v1.ResourcePods, node.Status.Capacity[v1.ResourcePods]),
+			maxPods.String(),
+			fmt.Sprintf("%d", others),
+			"1",
+			fmt.Sprintf("%d", maxPods.Value()-int64(others)-1),
+			"-",
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	for _, condition := range node.Status.Conditions {
+		for _, pressure := range nodePressureConditions {
+			if condition.Type == pressure && condition.Status == v1.ConditionTrue {
+				notes = append(notes, fmt.Sprintf("%s  the node reports %s: %s", aurora.Red("✖").String(), condition.Type, condition.Message))
+			}
+		}
+	}
+
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
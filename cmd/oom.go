@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// once a restarted container is back above this share of its memory limit, it's likely to be
+// OOMKilled again
+const oomUsageWarnPercent = 90
+
+// isOOMKill reports whether a container instance was killed for using too much memory: the
+// kernel's OOM killer, as reported by the runtime, or an exit code of 137 (SIGKILL), which is how
+// an OOM kill looks when the runtime doesn't say.
+func isOOMKill(t *v1.ContainerStateTerminated) bool {
+	return t != nil && (t.Reason == "OOMKilled" || t.ExitCode == 137)
+}
+
+// getOOMKill returns the container's instance that was OOMKilled, current or last, or nil.
+func getOOMKill(cs v1.ContainerStatus) *v1.ContainerStateTerminated {
+	for _, t := range []*v1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+		if isOOMKill(t) {
+			return t
+		}
+	}
+	return nil
+}
+
+// getOOMKills calls out the containers that were OOMKilled, with their memory request and limit
+// and, if metrics-server is installed, how much memory the running instance is using now, so the
+// kill can be weighed against what the container was given.
+func (dp *podInspectCommand) getOOMKills(pod *v1.Pod) (string, error) {
+	killed := []containerWithStatus{}
+	for _, c := range getContainersWithStatus(pod) {
+		if getOOMKill(c.Status) != nil {
+			killed = append(killed, c)
+		}
+	}
+	if len(killed) == 0 {
+		return "", nil
+	}
+
+	metrics, err := dp.getPodMetrics(pod)
+	if note := describeForbidden(err); note != "" {
+		metrics = nil
+	} else if err != nil {
+		return "", err
+	}
+
+	retval := aurora.Cyan("OOM Kills:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Killed").String(),
+		aurora.Yellow("Restarts").String(),
+		aurora.Yellow("Mem Request").String(),
+		aurora.Yellow("Mem Limit").String(),
+		aurora.Yellow("Usage Now").String(),
+	})
+
+	notes := []string{}
+	for _, c := range killed {
+		t := getOOMKill(c.Status)
+
+		request, limit := "none", "none"
+		if q, ok := c.Container.Resources.Requests[v1.ResourceMemory]; ok {
+			request = q.String()
+		}
+		q, hasLimit := c.Container.Resources.Limits[v1.ResourceMemory]
+		if hasLimit {
+			limit = q.String()
+		}
+
+		usage := "n/a"
+		if metrics == nil {
+			usage = "n/a (no metrics-server)"
+		}
+		if u, ok := metrics.getContainerUsage(c.Container.Name, v1.ResourceMemory); ok && c.Status.State.Running != nil {
+			usage = u.String()
+			if hasLimit && q.Value() > 0 {
+				percent := u.Value() * 100 / q.Value()
+				usage = fmt.Sprintf("%s (%d%% of limit)", u.String(), percent)
+				if percent >= oomUsageWarnPercent {
+					usage = aurora.Red(usage).String()
+					notes = append(notes, fmt.Sprintf("%s  container %s is back at %d%% of its memory limit, and likely to be OOMKilled again", aurora.Red("✖").String(), c.Container.Name, percent))
+				}
+			}
+		}
+
+		reason := t.Reason
+		if reason == "" {
+			reason = "exit code 137"
+		}
+		tw.Append([]string{
+			c.Container.Name,
+			aurora.Red(reason).String(),
+			dp.formatTimestamp(t.FinishedAt),
+			fmt.Sprintf("%d", c.Status.RestartCount),
+			request,
+			limit,
+			usage,
+		})
+
+		switch {
+		case t.Reason != "OOMKilled":
+			notes = append(notes, fmt.Sprintf("%s  container %s was killed with SIGKILL (exit code 137) but not reported as OOMKilled: a process in it may have been OOM killed, or the kubelet killed it (a failed liveness probe, or the grace period running out)", aurora.Yellow("…").String(), c.Container.Name))
+		case !hasLimit:
+			notes = append(notes, fmt.Sprintf("%s  container %s has no memory limit, so it was killed because its node ran out of memory; set a memory request that covers what it uses, so the scheduler leaves room for it", aurora.Yellow("…").String(), c.Container.Name))
+		default:
+			notes = append(notes, fmt.Sprintf("%s  container %s used up its memory limit of %s; raise the limit, or find out what's using the memory", aurora.Red("✖").String(), c.Container.Name, limit))
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// getPodOverhead shows the pod overhead that the pod's RuntimeClass adds (kata, gVisor, and other
+// sandboxed runtimes) next to the container requests.  The scheduler and the kubelet account for
+// the overhead too, so the container requests alone understate the pod's real footprint.
+func (dp *podInspectCommand) getPodOverhead(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if len(pod.Spec.Overhead) == 0 {
+		return "", nil
+	}
+
+	runtimeClass := "n/a"
+	if pod.Spec.RuntimeClassName != nil {
+		runtimeClass = *pod.Spec.RuntimeClassName
+	}
+
+	retval += aurora.Cyan(fmt.Sprintf("Pod Overhead (RuntimeClass %s):\n\n", runtimeClass)).String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		"",
+		aurora.Yellow("CPU Request").String(),
+		aurora.Yellow("Memory Request").String(),
+	})
+
+	formatRequest := func(resources v1.ResourceList, name v1.ResourceName) string {
+		q, ok := resources[name]
+		return formatOptionalQuantity(q, ok)
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		tw.Append([]string{
+			fmt.Sprintf("init container %s", c.Name),
+			formatRequest(c.Resources.Requests, v1.ResourceCPU),
+			formatRequest(c.Resources.Requests, v1.ResourceMemory),
+		})
+	}
+	for _, c := range pod.Spec.Containers {
+		tw.Append([]string{
+			fmt.Sprintf("container %s", c.Name),
+			formatRequest(c.Resources.Requests, v1.ResourceCPU),
+			formatRequest(c.Resources.Requests, v1.ResourceMemory),
+		})
+	}
+
+	tw.Append([]string{
+		aurora.Yellow("pod overhead").String(),
+		aurora.Yellow(formatRequest(pod.Spec.Overhead, v1.ResourceCPU)).String(),
+		aurora.Yellow(formatRequest(pod.Spec.Overhead, v1.ResourceMemory)).String(),
+	})
+
+	total := getPodEffectiveRequests(pod)
+	tw.Append([]string{
+		"scheduling footprint",
+		formatRequest(total, v1.ResourceCPU),
+		formatHeadroomQuantity(v1.ResourceMemory, total[v1.ResourceMemory]),
+	})
+	tw.Render()
+	retval += sb.String()
+
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+		overhead := pod.Spec.Overhead[name]
+		containers := total[name].DeepCopy()
+		containers.Sub(overhead)
+		if !overhead.IsZero() && overhead.Cmp(containers) > 0 {
+			retval += fmt.Sprintf("%s  the %s overhead is larger than the containers' own requests; size nodes for the footprint, not the requests\n", aurora.Yellow("…").String(), name)
+		}
+	}
+
+	return retval, nil
+}
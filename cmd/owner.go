@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+)
+
+// ownerObject is one of the controllers that a pod belongs to (its ReplicaSet, that ReplicaSet's
+// Deployment, ...).  Controllers are fetched as raw JSON: the sections that use them mostly want
+// the object as it was written, and it avoids depending on a typed client per kind.
+type ownerObject struct {
+	Kind     string
+	Name     string
+	Metadata metav1.ObjectMeta
+	Raw      []byte
+}
+
+// the controller kinds we know how to fetch, by API group and resource
+var ownerResources = map[string]struct {
+	group    string
+	resource string
+}{
+	"ReplicaSet":            {"apps", "replicasets"},
+	"Deployment":            {"apps", "deployments"},
+	"StatefulSet":           {"apps", "statefulsets"},
+	"DaemonSet":             {"apps", "daemonsets"},
+	"Job":                   {"batch", "jobs"},
+	"CronJob":               {"batch", "cronjobs"},
+	"ReplicationController": {"", "replicationcontrollers"},
+}
+
+// getControllerOf returns the owner reference that marks the object's managing controller.
+func getControllerOf(meta metav1.ObjectMeta) *metav1.OwnerReference {
+	for i, ref := range meta.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return &meta.OwnerReferences[i]
+		}
+	}
+	return nil
+}
+
+// getControllerChain walks the controller owner references up from the given object, returning
+// the controllers nearest first (e.g. ReplicaSet, then Deployment).  The walk stops at the first
+// controller of a kind we don't know how to fetch, or that no longer exists.  Static pods have no
+// controllers; they are owned by their node.
+func (dp *podInspectCommand) getControllerChain(meta metav1.ObjectMeta) ([]*ownerObject, error) {
+	chain := []*ownerObject{}
+
+	if isStaticPod(meta) {
+		return chain, nil
+	}
+
+	for ref := getControllerOf(meta); ref != nil; ref = getControllerOf(meta) {
+		owner, err := dp.getOwner(ref.Kind, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if owner == nil {
+			break
+		}
+
+		chain = append(chain, owner)
+		meta = owner.Metadata
+	}
+
+	return chain, nil
+}
+
+// getOwner fetches (and caches) a controller in the current namespace.  Returns nil if the kind
+// isn't one we know, or the object is gone.
+func (dp *podInspectCommand) getOwner(kind, name string) (*ownerObject, error) {
+	key := fmt.Sprintf("%s/%s/%s", dp.namespace, kind, name)
+	if owner, ok := dp.owners[key]; ok {
+		return owner, nil
+	}
+
+	r, ok := ownerResources[kind]
+	if !ok {
+		return nil, nil
+	}
+
+	if dp.owners == nil {
+		dp.owners = map[string]*ownerObject{}
+	}
+
+	raw, err := dp.restClientFor(kind).Get().Namespace(dp.namespace).Resource(r.resource).Name(name).Do(context.Background()).Raw()
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			dp.owners[key] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	obj := struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+
+	owner := &ownerObject{Kind: kind, Name: name, Metadata: obj.Metadata, Raw: raw}
+	dp.owners[key] = owner
+
+	return owner, nil
+}
+
+// getSelector returns the controller's pod selector, as a label selector string.  Returns "" for
+// controllers without one (CronJobs select through their Jobs).
+func (o *ownerObject) getSelector() (string, error) {
+	obj := struct {
+		Spec struct {
+			Selector json.RawMessage `json:"selector"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(o.Raw, &obj); err != nil {
+		return "", err
+	}
+	if len(obj.Spec.Selector) == 0 {
+		return "", nil
+	}
+
+	// a ReplicationController's selector is a plain map of labels
+	if o.Kind == "ReplicationController" {
+		set := labels.Set{}
+		if err := json.Unmarshal(obj.Spec.Selector, &set); err != nil {
+			return "", err
+		}
+		return labels.SelectorFromSet(set).String(), nil
+	}
+
+	selector := &metav1.LabelSelector{}
+	if err := json.Unmarshal(obj.Spec.Selector, selector); err != nil {
+		return "", err
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+// restClientFor returns the REST client for the API group and version that serves the kind.
+func (dp *podInspectCommand) restClientFor(kind string) rest.Interface {
+	switch ownerResources[kind].group {
+	case "apps":
+		return dp.clientset.AppsV1().RESTClient()
+	case "batch":
+		// CronJob is only served from batch/v1beta1 by the API versions we build against
+		if kind == "CronJob" {
+			return dp.clientset.BatchV1beta1().RESTClient()
+		}
+		return dp.clientset.BatchV1().RESTClient()
+	default:
+		return dp.clientset.CoreV1().RESTClient()
+	}
+}
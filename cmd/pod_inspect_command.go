@@ -1,13 +1,14 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	// Initialize all known client auth plugins.
 	"k8s.io/client-go/kubernetes"
@@ -15,24 +16,43 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 
-	"github.com/logrusorgru/aurora"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
 )
 
+type containerLogs struct {
+	ContainerName string
+	Init          bool
+	Previous      bool
+	Logs          string
+	Dropped       int
+	TimedOut      bool
+	Denied        string
+	Error         string
+}
+
 type containerInfo struct {
 	TypeCode     string
 	Name         string
 	Image        string
 	State        string
 	StateMessage string
+	StateSince   metav1.Time
+	Stuck        bool
 	RestartCount int32
+	ExitCode     string
 	Ready        bool
 	ReadyIcon    string
+	Status       int
 }
 
 const PODINSPECT_STATUS_WAITING = 0
@@ -41,12 +61,89 @@ const PODINSPECT_STATUS_OK = 2
 const PODINSPECT_STATUS_UNKNOWN = 3
 
 type podInspectCommand struct {
-	out         io.Writer
-	f           cmdutil.Factory
-	clientset   *kubernetes.Clientset
-	namespace   string
-	numLogLines int
-	numEvents   int
+	out                   io.Writer
+	f                     cmdutil.Factory
+	clientset             *kubernetes.Clientset
+	namespace             string
+	numLogLines           int
+	allLogs               bool
+	followLogs            bool
+	logsSince             time.Duration
+	logsSinceTime         string
+	logTimestamps         bool
+	rawLogs               bool
+	logGrep               string
+	logGrepRegexp         *regexp.Regexp
+	logGrepHighlight      bool
+	logGrepContext        int
+	numEvents             int
+	output                string
+	alsoOutput            []string
+	outputSinks           []outputSink
+	sinkReport            *report.Report
+	capturedLogs          map[types.UID][]containerLogs
+	dryRun                bool
+	watch                 bool
+	watchInterval         time.Duration
+	changesOnly           bool
+	includePriorEvents    bool
+	postMortem            bool
+	logTimeout            time.Duration
+	phases                []string
+	problemsOnly          bool
+	showSpec              string
+	showFieldManagers     bool
+	showCommand           bool
+	compare               string
+	podIP                 string
+	uid                   string
+	warnStale             bool
+	eventsSince           time.Duration
+	selector              string
+	fieldSelector         string
+	namePattern           string
+	nameRegex             string
+	nameRegexp            *regexp.Regexp
+	owner                 string
+	ownerWorkload         *ownerObject
+	allNamespaces         bool
+	node                  string
+	color                 string
+	timeFormat            string
+	width                 int
+	connectivity          []string
+	connectivityContainer string
+	lint                  bool
+	lintRules             []string
+	diagnose              bool
+	sections              []string
+	fsUsage               bool
+	imageSignatures       bool
+	validatePullSecrets   bool
+	diagnosisRules        []string
+	cost                  bool
+	nodeAllocation        bool
+	psaDryRun             string
+	historyDB             string
+	showVolumes           bool
+	costPrices            map[string]string
+	conditionSeverities   map[string]string
+	nodes                 map[string]*v1.Node
+	nodeList              []v1.Node
+	nodeEvents            map[string][]v1.Event
+	nodePods              map[string][]v1.Pod
+	owners                map[string]*ownerObject
+	deploymentRevisions   map[types.UID][]deploymentRevision
+	workloadPods          map[types.UID][]v1.Pod
+	podEvents             map[types.UID][]v1.Event
+	batchEvents           bool
+	podEventIndexes       map[string]*podEventIndex
+	informers             *watchInformers
+	podMetrics            map[string]*podMetrics
+	podMetricsListed      map[string]bool
+	imagePlatforms        map[string]imagePlatforms
+	cosignArtifacts       map[string]cosignArtifacts
+	deniedNotes           map[string]bool
 }
 
 // NewPodInspectCommand creates the command for rendering the Kubernetes server version.
@@ -56,11 +153,27 @@ func NewPodInspectCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	}
 
 	ccmd := &cobra.Command{
-		Use:          "kubectl pod-inspect <podname>",
+		Use:          "kubectl pod-inspect [<podname> | '<pattern>' | <kind>/<name>]",
 		Short:        "Inspects a pod",
 		Long:         "Provides detailed information about a pod, including its containers' statuses, pod events, and logs from non-ready containers.",
 		SilenceUsage: true,
-		Args:         cobra.MaximumNArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if err := checkOptionalValueArgs(cmd.Flags(), args, map[string][]string{"show-spec": specExcerpts, "compare": compareModes}); err != nil {
+				return err
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
+		// the config file and --color apply to the subcommands too
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyConfigDefaults(cmd.Flags(), cmd.Root().Flags()); err != nil {
+				return err
+			}
+			if err := validateColorMode(dpcmd.color); err != nil {
+				return err
+			}
+			setColorMode(dpcmd.color)
+			return nil
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return dpcmd.run(args)
 		},
@@ -76,10 +189,69 @@ func NewPodInspectCommand(streams genericclioptions.IOStreams) *cobra.Command {
 
 	ccmd.SetUsageTemplate(strings.Replace(ccmd.UsageTemplate(), oldLine, newLine, 1))
 
-	ccmd.Flags().IntVarP(&dpcmd.numEvents, "max-num-events", "e", 10, "Maximum number of events to display; 0 means display all")
-	ccmd.Flags().IntVarP(&dpcmd.numLogLines, "max-num-log-lines", "l", 5, "Maximum number of log lines to display; 0 means display all")
+	ccmd.Flags().IntVarP(&dpcmd.numEvents, "max-num-events", "e", 10, "Maximum number of events to display, keeping warnings over older normal events; 0 means display all")
+	ccmd.Flags().DurationVar(&dpcmd.eventsSince, "events-since", 0, "Only display events seen within this long (e.g. 1h); 0 means no time limit")
+	ccmd.Flags().IntVar(&dpcmd.numLogLines, "max-num-log-lines", 5, "Maximum number of log lines to display; 0 means display all.  No longer shortened to -l, which now means --selector")
+	ccmd.Flags().BoolVar(&dpcmd.allLogs, "all-logs", false, "Display the logs of every container, not only those that are not ok")
+	ccmd.Flags().BoolVar(&dpcmd.followLogs, "follow-logs", false, "After the report, stream the logs of the containers that aren't ready until interrupted")
+	ccmd.Flags().DurationVar(&dpcmd.logsSince, "since", 0, "Only display log lines newer than this (e.g. 10m); combines with --max-num-log-lines")
+	ccmd.Flags().StringVar(&dpcmd.logsSinceTime, "since-time", "", "Only display log lines written after this time (RFC3339, e.g. 2024-01-02T15:04:05Z)")
+	ccmd.Flags().BoolVar(&dpcmd.logTimestamps, "log-timestamps", false, "Prefix each log line with the time the kubelet received it, formatted per --time-format")
+	ccmd.Flags().BoolVar(&dpcmd.rawLogs, "raw-logs", false, "Display JSON (structured) log lines as they are, instead of laid out as time, level, message, and fields")
+	ccmd.Flags().StringVar(&dpcmd.logGrep, "log-grep", "", "Only display the captured log lines matching this regular expression (e.g. 'ERROR|panic'), with the matches highlighted")
+	ccmd.Flags().BoolVar(&dpcmd.logGrepHighlight, "log-grep-highlight", false, "With --log-grep, display every captured log line, only highlighting the matches")
+	ccmd.Flags().IntVar(&dpcmd.logGrepContext, "log-grep-context", 0, "With --log-grep, also display this many lines before and after each match")
+	ccmd.Flags().DurationVar(&dpcmd.logTimeout, "log-timeout", 10*time.Second, "Timeout for fetching each container's logs; 0 means no timeout")
+	ccmd.Flags().StringVar(&dpcmd.podIP, "pod-ip", "", "Inspect the pod that has this IP address, instead of naming it")
+	ccmd.Flags().StringVar(&dpcmd.uid, "uid", "", "Inspect the pod that has this UID, instead of naming it; shows the events of a pod that has since been deleted or recreated")
+	ccmd.Flags().StringVarP(&dpcmd.output, "output", "o", "", "Output format; one of: json, yaml, go-template=..., jsonpath=..., or diff for drift and --compare results as unified diffs.  Defaults to the human-readable report")
+	ccmd.Flags().BoolVar(&dpcmd.postMortem, "post-mortem", false, "If the named pod doesn't exist any more, show what its events and its controllers' say happened to it (evicted, OOM-killed, completed, ...)")
+	ccmd.Flags().StringSliceVar(&dpcmd.alsoOutput, "also-output", nil, "Also write the structured report to a file or POST it to an http(s) URL, as <format>=<destination> (e.g. json=report.json); repeatable")
+	ccmd.Flags().BoolVar(&dpcmd.includePriorEvents, "include-prior-events", false, "Include events from earlier pods that had the same name as the inspected pod")
+	ccmd.Flags().StringVarP(&dpcmd.selector, "selector", "l", "", "Only inspect the pods matching this label selector (e.g. -l app=myapp), instead of the whole namespace")
+	ccmd.Flags().StringVar(&dpcmd.fieldSelector, "field-selector", "", "Only inspect the pods matching this field selector (e.g. --field-selector status.phase!=Running), applied by the API server")
+	ccmd.Flags().StringVar(&dpcmd.nameRegex, "name-regex", "", "Only inspect the pods whose names match this regular expression (e.g. '^api-server-')")
+	ccmd.Flags().StringVar(&dpcmd.owner, "owner", "", "Inspect the pods controlled by this workload, e.g. --owner statefulset/db; the same as giving it in place of the pod name")
+	ccmd.Flags().BoolVarP(&dpcmd.allNamespaces, "all-namespaces", "A", false, "Inspect the pods in every namespace, instead of only the current one")
+	ccmd.Flags().StringVar(&dpcmd.node, "node", "", "Inspect the pods scheduled on this node, in every namespace")
+	ccmd.Flags().StringSliceVar(&dpcmd.phases, "phase", nil, "When inspecting the whole namespace, only include pods in these phases (comma-separated)")
+	ccmd.Flags().BoolVar(&dpcmd.problemsOnly, "problems-only", false, "When inspecting the whole namespace, only include pods that have a problem")
+	ccmd.Flags().StringVar(&dpcmd.showSpec, "show-spec", "", "Also print part of the pod spec: --show-spec=containers, --show-spec=volumes, or --show-spec for the full spec")
+	ccmd.Flags().Lookup("show-spec").NoOptDefVal = "full"
+	ccmd.Flags().BoolVar(&dpcmd.showFieldManagers, "show-field-managers", false, "Show which managers (controllers, users, tools) last set each container's image, resources, and env")
+	ccmd.Flags().BoolVar(&dpcmd.showVolumes, "show-volumes", false, "Show each volume, its source and where the containers mount it, with the status of its PersistentVolumeClaims")
+	ccmd.Flags().BoolVar(&dpcmd.showCommand, "show-command", false, "Show each container's command, args, and working directory")
+	ccmd.Flags().StringVar(&dpcmd.compare, "compare", "", "Show the env vars and volume mounts that differ between the pod's containers (--compare=containers) or between the replicas of its workload (--compare=replicas)")
+	ccmd.Flags().Lookup("compare").NoOptDefVal = "containers"
+	ccmd.Flags().BoolVar(&dpcmd.warnStale, "warn-stale", false, "Warn about pods created from an older template than their Deployment's or StatefulSet's current one")
+	ccmd.Flags().StringSliceVar(&dpcmd.connectivity, "connectivity", nil, "Check from inside the pod that these targets (host:port, or http(s) URLs; comma-separated) can be reached")
+	ccmd.Flags().StringVar(&dpcmd.connectivityContainer, "connectivity-container", "", "The container to run the --connectivity checks in; defaults to the first running one")
+	ccmd.Flags().StringSliceVar(&dpcmd.sections, "sections", nil, fmt.Sprintf("Only display these sections of the report (comma-separated); sections: %s", strings.Join(getSectionNames(), ", ")))
+	ccmd.Flags().BoolVar(&dpcmd.diagnose, "diagnose", true, "Interpret the pod's problems in a Diagnosis section, with their likely causes and suggested fixes")
+	ccmd.Flags().StringSliceVar(&dpcmd.diagnosisRules, "diagnosis-rules", nil, fmt.Sprintf("Only run these diagnosis rules, or, prefixed with -, all but these (comma-separated); rules: %s", ruleNames(diagnosisRules)))
+	ccmd.Flags().BoolVar(&dpcmd.fsUsage, "fs-usage", false, "Run df in each running container to report how full its writable layer and emptyDir volumes are")
+	ccmd.Flags().BoolVar(&dpcmd.imageSignatures, "image-signatures", false, "Look up the cosign signatures, attestations and SBOMs of the image digests the containers are running, and report unsigned images; anonymously, unless --validate-pull-secrets")
+	ccmd.Flags().BoolVar(&dpcmd.validatePullSecrets, "validate-pull-secrets", false, "Send the pod's image pull secret credentials to its images' registries: to check them for containers that can't pull their image, and to look up image platforms and --image-signatures with them.  The pull secrets themselves are always read, to report missing ones and ones that don't cover the registry")
+	ccmd.Flags().BoolVar(&dpcmd.lint, "lint", false, "Flag risky patterns in the pod spec, e.g. missing probes or limits, in a Recommendations section")
+	ccmd.Flags().StringSliceVar(&dpcmd.lintRules, "lint-rules", nil, fmt.Sprintf("Only run these lint rules, or, prefixed with -, all but these (comma-separated); rules: %s", ruleNames(lintRules)))
+	ccmd.Flags().BoolVar(&dpcmd.nodeAllocation, "node-allocation", false, "Compare the pod's requests with its node's allocatable resources and what the other pods on it have committed")
+	ccmd.Flags().StringVar(&dpcmd.psaDryRun, "psa-dry-run", "", fmt.Sprintf("Check whether the pod would be admitted under this Pod Security level, listing the fields that violate it; one of: %s", strings.Join(podSecurityLevels, ", ")))
+	ccmd.Flags().BoolVar(&dpcmd.cost, "cost", false, "Estimate the pod's hourly cost from its resource requests")
+	ccmd.Flags().StringToStringVar(&dpcmd.conditionSeverities, "condition-severities", nil, "Severities of custom pod conditions that aren't True, by type or pattern: error, warning, or info (e.g. karpenter.sh/*=info); readiness gates default to error, others to warning")
+	ccmd.Flags().StringToStringVar(&dpcmd.costPrices, "cost-prices", nil, "Hourly prices for --cost, overriding the defaults; per core for cpu, per GiB for memory, per unit otherwise (e.g. cpu=0.04,memory=0.005)")
+	ccmd.PersistentFlags().StringVar(&dpcmd.color, "color", "auto", "Color the output: auto (only when writing to a terminal), always, or never")
+	ccmd.Flags().IntVar(&dpcmd.width, "width", 0, "Fit the report in this many columns, wrapping long lines; 0 means don't wrap")
+	ccmd.Flags().StringVar(&dpcmd.timeFormat, "time-format", "absolute", "How to show event times: absolute, or relative (e.g. 5m ago)")
+	ccmd.Flags().BoolVarP(&dpcmd.watch, "watch", "w", false, "Keep the report up to date, re-running the inspection every --watch-interval until interrupted")
+	ccmd.Flags().DurationVar(&dpcmd.watchInterval, "watch-interval", 5*time.Second, "How often --watch refreshes the report")
+	ccmd.Flags().BoolVar(&dpcmd.changesOnly, "changes-only", false, "With --watch, print a line for each container state change and new warning event, instead of redrawing the report")
+	ccmd.Flags().StringVar(&dpcmd.historyDB, "history-db", "", "Append a row per pod to this SQLite file on each sweep (and each --watch refresh of one), for the query subcommand")
+	ccmd.Flags().BoolVar(&dpcmd.dryRun, "dry-run", false, "Print the API requests that would be made, without making them")
 
 	ccmd.AddCommand(newVersionCmd(streams.Out))
+	ccmd.AddCommand(newCanICmd(dpcmd))
+	ccmd.AddCommand(newWorkloadCmd(dpcmd))
+	ccmd.AddCommand(newQueryCmd(dpcmd))
 
 	fsets := ccmd.PersistentFlags()
 	cfgFlags := genericclioptions.NewConfigFlags(true)
@@ -92,7 +264,8 @@ func NewPodInspectCommand(streams genericclioptions.IOStreams) *cobra.Command {
 	return ccmd
 }
 
-func (dp *podInspectCommand) run(args []string) error {
+// complete sets up the API client and resolves the namespace to work in.
+func (dp *podInspectCommand) complete() error {
 	clientset, err := dp.f.KubernetesClientSet()
 	if err != nil {
 		return err
@@ -107,37 +280,362 @@ func (dp *podInspectCommand) run(args []string) error {
 	}
 	dp.namespace = ns
 
+	return nil
+}
+
+func (dp *podInspectCommand) run(args []string) error {
+	if err := validateOutputFormat(dp.output); err != nil {
+		return err
+	}
+	if err := validatePhaseFilter(dp.phases); err != nil {
+		return err
+	}
+	if err := validateSelector(dp.selector); err != nil {
+		return err
+	}
+	if err := validateSpecExcerpt(dp.showSpec); err != nil {
+		return err
+	}
+	if err := validateCompareMode(dp.compare); err != nil {
+		return err
+	}
+	if err := validatePodIP(dp.podIP); err != nil {
+		return err
+	}
+	if err := validateTimeFormat(dp.timeFormat); err != nil {
+		return err
+	}
+	if err := validateWidth(dp.width); err != nil {
+		return err
+	}
+	// -o output is left alone: folding it would break the JSON or YAML
+	if dp.width > 0 && dp.output == "" {
+		w := newWidthWriter(dp.out, dp.width)
+		defer w.Flush()
+		dp.out = w
+	}
+	if err := validateLogWindow(dp.logsSince, dp.logsSinceTime); err != nil {
+		return err
+	}
+	if err := dp.compileLogGrep(); err != nil {
+		return err
+	}
+	if err := validateConnectivityTargets(dp.connectivity); err != nil {
+		return err
+	}
+	if _, err := selectRules(lintRules, dp.lintRules); err != nil {
+		return err
+	}
+	if _, err := selectRules(diagnosisRules, dp.diagnosisRules); err != nil {
+		return err
+	}
+	if err := validateSections(dp.sections); err != nil {
+		return err
+	}
+	if _, err := parseResourcePrices(dp.costPrices); err != nil {
+		return err
+	}
+	if err := validateConditionSeverities(dp.conditionSeverities); err != nil {
+		return err
+	}
+	if err := validatePodSecurityLevel(dp.psaDryRun); err != nil {
+		return err
+	}
+	for _, spec := range dp.alsoOutput {
+		sink, err := parseOutputSink(spec)
+		if err != nil {
+			return err
+		}
+		dp.outputSinks = append(dp.outputSinks, sink)
+	}
+
+	// a glob pattern in place of the pod name inspects every pod it matches, and a workload
+	// (deploy/my-api) the pods it controls
+	if len(args) == 1 && isPodNamePattern(args[0]) {
+		dp.namePattern = args[0]
+		args = nil
+	}
+	if len(args) == 1 && strings.Contains(args[0], "/") {
+		parts := strings.SplitN(args[0], "/", 2)
+		switch strings.ToLower(parts[0]) {
+		case "pod", "pods", "po":
+			args = []string{parts[1]}
+		default:
+			if dp.owner != "" {
+				return fmt.Errorf("--owner can't be used with a workload argument")
+			}
+			dp.owner = args[0]
+			args = nil
+		}
+	}
+	if dp.owner != "" {
+		if _, _, err := parseWorkloadName(dp.owner); err != nil {
+			return err
+		}
+	}
+	if err := dp.compileNameFilters(); err != nil {
+		return err
+	}
+
+	if err := dp.complete(); err != nil {
+		return err
+	}
+
+	if dp.podIP != "" && len(args) > 0 {
+		return fmt.Errorf("--pod-ip can't be used with a pod name")
+	}
+	if dp.uid != "" && (len(args) > 0 || dp.podIP != "" || dp.owner != "" || dp.node != "" || dp.namePattern != "" || dp.nameRegex != "" || dp.selector != "" || dp.fieldSelector != "") {
+		return fmt.Errorf("--uid can't be used with a pod name, --pod-ip, --node, a workload, or a selector")
+	}
+	if dp.selector != "" && (len(args) > 0 || dp.podIP != "") {
+		return fmt.Errorf("--selector can't be used with a pod name or --pod-ip")
+	}
+	if dp.fieldSelector != "" && (len(args) > 0 || dp.podIP != "") {
+		return fmt.Errorf("--field-selector can't be used with a pod name or --pod-ip")
+	}
+	if dp.nameRegex != "" && (len(args) > 0 || dp.podIP != "") {
+		return fmt.Errorf("--name-regex can't be used with a pod name or --pod-ip")
+	}
+	if dp.namePattern != "" && dp.podIP != "" {
+		return fmt.Errorf("--pod-ip can't be used with a pod name pattern")
+	}
+	if dp.node != "" && (len(args) > 0 || dp.podIP != "" || dp.owner != "") {
+		return fmt.Errorf("--node can't be used with a pod name, --pod-ip, or a workload")
+	}
+	// a node's pods come from every namespace
+	if dp.node != "" {
+		dp.allNamespaces = true
+	}
+	if dp.owner != "" && (len(args) > 0 || dp.podIP != "" || dp.allNamespaces) {
+		return fmt.Errorf("a workload can't be used with a pod name, --pod-ip, or --all-namespaces")
+	}
+	if dp.allNamespaces && len(args) > 0 {
+		return fmt.Errorf("a pod name can't be used with --all-namespaces")
+	}
+	if dp.watch && dp.output != "" {
+		return fmt.Errorf("--watch can't be used with --output")
+	}
+	if dp.watch && len(dp.outputSinks) > 0 {
+		return fmt.Errorf("--watch can't be used with --also-output")
+	}
+	if dp.changesOnly && !dp.watch {
+		return fmt.Errorf("--changes-only can only be used with --watch")
+	}
+	if dp.historyDB != "" && (len(args) > 0 || dp.podIP != "" || dp.uid != "") {
+		return fmt.Errorf("--history-db records sweeps; it can't be used with a pod name, --pod-ip or --uid")
+	}
+	if dp.output == diffOutputFormat && (len(dp.outputSinks) > 0 || dp.historyDB != "") {
+		return fmt.Errorf("-o diff can't be used with --also-output or --history-db")
+	}
+	if dp.watch && dp.watchInterval <= 0 {
+		return fmt.Errorf("--watch-interval must be greater than 0")
+	}
+	if dp.followLogs && ((len(args) != 1 && dp.podIP == "" && dp.uid == "") || dp.watch || dp.output != "") {
+		return fmt.Errorf("--follow-logs needs a single pod, and can't be used with --watch or --output")
+	}
+
+	if dp.dryRun {
+		return dp.printDryRun(args)
+	}
+
+	if dp.owner != "" {
+		kind, name, _ := parseWorkloadName(dp.owner)
+		workload, err := dp.getOwner(kind, name)
+		if err != nil {
+			return err
+		}
+		if workload == nil {
+			return fmt.Errorf("%s %s not found in namespace %s", kind, name, dp.namespace)
+		}
+		dp.ownerWorkload = workload
+	}
+
+	if dp.podIP != "" {
+		podName, err := dp.resolvePodIP(dp.podIP)
+		if err != nil {
+			return err
+		}
+		args = []string{podName}
+	}
+	if dp.uid != "" {
+		pod, found, err := dp.resolvePodUID(dp.uid)
+		if err != nil {
+			return err
+		}
+		if !found {
+			if dp.output != "" {
+				return fmt.Errorf("pod %s/%s with UID %s has been deleted", pod.Namespace, pod.Name, dp.uid)
+			}
+			return dp.displayDeletedPod(pod)
+		}
+		args = []string{pod.Name}
+	}
+
+	if len(dp.outputSinks) > 0 && dp.output == "" {
+		dp.sinkReport = report.New()
+	}
+
+	if dp.watch && dp.changesOnly {
+		return dp.runWatchChanges(args)
+	}
+	if dp.watch {
+		return dp.runWatch(args)
+	}
+	if dp.followLogs {
+		// a line logged while the report fetches the tails may show up twice, but none goes missing
+		since := metav1.Now()
+		if err := dp.inspect(args); err != nil {
+			return err
+		}
+		return dp.followContainerLogs(args[0], since)
+	}
+	return dp.inspect(args)
+}
+
+// inspect prints the report on the named pod or, without a name, on every pod that passes the
+// filters.
+func (dp *podInspectCommand) inspect(args []string) error {
+	if dp.output == diffOutputFormat {
+		dp.batchEvents = true
+		return dp.printDiffs(args)
+	}
+
 	if len(args) == 1 {
+		if dp.output != "" {
+			return dp.printReport([]types.NamespacedName{{Namespace: dp.namespace, Name: args[0]}}, true)
+		}
+
 		err := dp.displayPod(args[0])
-		return err
+		if dp.postMortem && apierrors.IsNotFound(err) {
+			return dp.displayPostMortem(args[0], err)
+		}
+		if err != nil || dp.sinkReport == nil {
+			return err
+		}
+		return dp.writeSinks(dp.sinkReport)
 	}
 
-	pods, err := dp.clientset.CoreV1().Pods(dp.namespace).List(context.Background(), metav1.ListOptions{})
+	if dp.output != "" {
+		pods := []types.NamespacedName{}
+		err := dp.forEachPod(func(pod *v1.Pod) error {
+			pods = append(pods, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		dp.batchEvents = true
+		return dp.printReport(pods, false)
+	}
+
+	dp.batchEvents = true
+	summary := newScanSummary()
+	err := dp.forEachPod(func(pod *v1.Pod) error {
+		if err := dp.displayPod(pod.Name); err != nil {
+			summary.addError(types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, err)
+			return nil
+		}
+		summary.addPod(dp, pod, getPodStatusVerdict(pod))
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	scanSummary := summary.finish()
+	dp.printScanSummary(scanSummary)
+	if err := dp.recordScan(summary); err != nil {
+		return err
+	}
+	if dp.sinkReport == nil {
+		return nil
+	}
+	dp.sinkReport.Summary = scanSummary
+	return dp.writeSinks(dp.sinkReport)
+}
+
+// number of pods fetched per list request when inspecting a whole namespace
+const podListPageSize = 100
 
-	for _, pod := range pods.Items {
-		dp.displayPod(pod.Name)
+// listNamespace returns the namespace to list pods in: all of them, for --all-namespaces.
+func (dp *podInspectCommand) listNamespace() string {
+	if dp.allNamespaces {
+		return ""
 	}
+	return dp.namespace
+}
 
-	return nil
+// forEachPod calls fn for every pod in the namespace that passes the workload, --selector,
+// --field-selector, name, --phase and --problems-only filters, listing them a page at a time so that each page can be displayed
+// before the next is fetched, rather than holding the whole namespace in memory first.
+//
+// With --all-namespaces, the namespace the rest of the command works in is switched to each
+// pod's own before fn is called.
+func (dp *podInspectCommand) forEachPod(fn func(pod *v1.Pod) error) error {
+	visit := func(pod *v1.Pod) error {
+		if !dp.includePod(pod) {
+			return nil
+		}
+		if dp.ownerWorkload != nil {
+			if ok, err := dp.isWorkloadPod(pod, dp.ownerWorkload); err != nil || !ok {
+				return err
+			}
+		}
+		dp.namespace = pod.Namespace
+		return fn(pod)
+	}
+
+	// when watching, the pods informer has already listed them with the same selectors
+	if dp.informers != nil {
+		pods, err := dp.informers.listPods()
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			if err := visit(pod); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	namespace := dp.listNamespace()
+	opts := metav1.ListOptions{Limit: podListPageSize, LabelSelector: dp.selector, FieldSelector: dp.podListFieldSelector()}
+	for {
+		pods, err := dp.clientset.CoreV1().Pods(namespace).List(context.Background(), opts)
+		if err != nil {
+			return err
+		}
+
+		for i := range pods.Items {
+			if err := visit(&pods.Items[i]); err != nil {
+				return err
+			}
+		}
+
+		if pods.Continue == "" {
+			return nil
+		}
+		opts.Continue = pods.Continue
+	}
 }
 
 func (dp *podInspectCommand) displayPod(podName string) error {
-	pod, err := dp.clientset.CoreV1().Pods(dp.namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	pod, rawPod, err := dp.getPod(podName)
 	if err != nil {
 		return err
 	}
 
 	cinfo := map[string]*containerInfo{}
-	podLogs := map[string]string{}
-
-	for _, c := range pod.Spec.InitContainers {
-		// prefix with "0-" to ensure init containers show up first in the sorted list
-		key := fmt.Sprintf("0-%s", c.Name)
+	logRequests := []logRequest{}
+
+	initKeys := map[string]string{}
+	for i, c := range pod.Spec.InitContainers {
+		// prefix with "0-" to ensure init containers show up first in the sorted list, followed
+		// by the spec index so that they're listed in the order in which they execute
+		key := fmt.Sprintf("0-%03d-%s", i, c.Name)
+		initKeys[c.Name] = key
 		if _, ok := cinfo[key]; !ok {
-			cinfo[key] = &containerInfo{}
+			cinfo[key] = &containerInfo{Status: PODINSPECT_STATUS_UNKNOWN}
 		}
 
 		cinfo[key].TypeCode = "IC"
@@ -146,8 +644,8 @@ func (dp *podInspectCommand) displayPod(podName string) error {
 	}
 
 	for _, cs := range pod.Status.InitContainerStatuses {
-		key := fmt.Sprintf("0-%s", cs.Name)
-		if _, ok := cinfo[key]; !ok {
+		key, ok := initKeys[cs.Name]
+		if !ok {
 			return fmt.Errorf("status found for init container '%s'; no corresponding container in spec", cs.Name)
 		}
 
@@ -155,19 +653,16 @@ func (dp *podInspectCommand) displayPod(podName string) error {
 
 		cinfo[key].State = cstate
 		cinfo[key].StateMessage = cmsg
+		cinfo[key].StateSince = getContainerStateSince(pod, cs, true)
+		cinfo[key].Stuck = isContainerStuck(cs, cinfo[key].StateSince)
 		cinfo[key].RestartCount = cs.RestartCount
+		cinfo[key].ExitCode = getContainerExitCode(cs)
 		cinfo[key].Ready = cs.Ready
 		cinfo[key].ReadyIcon = creadyicon
+		cinfo[key].Status = podInspectStatus
 
-		if podInspectStatus != PODINSPECT_STATUS_OK {
-			logs, err := dp.getPodLogs(podName, cinfo[key].Name)
-			if err != nil {
-				return err
-			}
-
-			if logs != "" {
-				podLogs[cinfo[key].Name] = logs
-			}
+		if podInspectStatus != PODINSPECT_STATUS_OK || dp.allLogs {
+			logRequests = append(logRequests, logRequest{Status: cs, Init: true})
 		}
 	}
 
@@ -175,7 +670,7 @@ func (dp *podInspectCommand) displayPod(podName string) error {
 		// prefix with "1-" to ensure regular containers show up second in the sorted list
 		key := fmt.Sprintf("1-%s", c.Name)
 		if _, ok := cinfo[key]; !ok {
-			cinfo[key] = &containerInfo{}
+			cinfo[key] = &containerInfo{Status: PODINSPECT_STATUS_UNKNOWN}
 		}
 
 		cinfo[key].Name = c.Name
@@ -183,17 +678,6 @@ func (dp *podInspectCommand) displayPod(podName string) error {
 		cinfo[key].Image = c.Image
 	}
 
-	fmt.Printf("%s%s / %s\n", aurora.Cyan("Pod:  "), pod.Namespace, pod.Name)
-	fmt.Printf("%s%s\n\n", aurora.Cyan("Node: "), pod.Spec.NodeName)
-
-	// handle complete pod failure
-	if len(pod.Status.ContainerStatuses) == 0 {
-		fmt.Printf("Phase:     %s\n", pod.Status.Phase)
-		fmt.Printf("Reason:    %s\n", pod.Status.Reason)
-		fmt.Printf("Message:   %s\n", pod.Status.Message)
-		return nil
-	}
-
 	for _, cs := range pod.Status.ContainerStatuses {
 		key := fmt.Sprintf("1-%s", cs.Name)
 		if _, ok := cinfo[key]; !ok {
@@ -204,190 +688,440 @@ func (dp *podInspectCommand) displayPod(podName string) error {
 
 		cinfo[key].State = cstate
 		cinfo[key].StateMessage = cmsg
+		cinfo[key].StateSince = getContainerStateSince(pod, cs, false)
+		cinfo[key].Stuck = isContainerStuck(cs, cinfo[key].StateSince)
 		cinfo[key].RestartCount = cs.RestartCount
+		cinfo[key].ExitCode = getContainerExitCode(cs)
 		cinfo[key].Ready = cs.Ready
 		cinfo[key].ReadyIcon = creadyicon
+		cinfo[key].Status = podInspectStatus
 
-		if podInspectStatus != PODINSPECT_STATUS_OK {
-			logs, err := dp.getPodLogs(podName, cinfo[key].Name)
-			if err != nil {
-				return err
-			}
+		if podInspectStatus != PODINSPECT_STATUS_OK || dp.allLogs {
+			logRequests = append(logRequests, logRequest{Status: cs})
+		}
+	}
 
-			if logs != "" {
-				podLogs[cinfo[key].Name] = logs
-			}
+	dp.deniedNotes = map[string]bool{}
+
+	if dp.includeSection(headerSection) {
+		if err := dp.printPodHeader(pod); err != nil {
+			return err
 		}
 	}
 
-	keys := make([]string, 0, len(cinfo))
-	for k := range cinfo {
-		keys = append(keys, k)
+	if dp.includeSection(containersSection) {
+		dp.printContainerTable(pod, cinfo)
 	}
-	sort.Strings(keys)
 
-	fmt.Printf("%s\n\n", aurora.Cyan("Containers: "))
+	// get the logs going now; they're shown last, once the other sections have been rendered
+	var podLogs []<-chan logResult
+	if dp.includeSection(logsSection) {
+		podLogs = dp.fetchContainerLogs(podName, logRequests)
+	}
 
-	tw := dp.newTablewriter(dp.out)
+	if err := dp.printReportSections(pod, rawPod); err != nil {
+		return err
+	}
 
-	tw.Append([]string{
-		aurora.Yellow("Type").String(),
-		aurora.Yellow("Name").String(),
-		aurora.Yellow("State").String(),
-		aurora.Yellow("RC").String(),
-		aurora.Yellow("Ready").String(),
-		aurora.Yellow("Image").String(),
-	})
-	for _, key := range keys {
-		ci := cinfo[key]
-		restartCount := fmt.Sprintf("%d", ci.RestartCount)
-		if ci.RestartCount > 0 {
-			restartCount = aurora.Yellow(fmt.Sprintf(" %s", restartCount)).String()
+	captured := []containerLogs{}
+	err = receiveContainerLogs(podLogs, func(cl containerLogs) error {
+		captured = append(captured, cl)
+		qualifiers := []string{}
+		if dp.numLogLines == 1 {
+			qualifiers = append(qualifiers, "last line")
+		} else if dp.numLogLines > 0 {
+			qualifiers = append(qualifiers, fmt.Sprintf("last %d lines", dp.numLogLines))
+		}
+		if dp.logsSince > 0 {
+			qualifiers = append(qualifiers, fmt.Sprintf("within the last %s", duration.HumanDuration(dp.logsSince)))
+		} else if dp.logsSinceTime != "" {
+			qualifiers = append(qualifiers, fmt.Sprintf("since %s", dp.logsSinceTime))
+		}
+		if cl.Previous {
+			qualifiers = append(qualifiers, "previous instance")
+		}
+		logs, matches := dp.grepLogs(dp.formatJSONLogs(cl.Logs))
+		if dp.logGrepRegexp != nil {
+			qualifiers = append(qualifiers, fmt.Sprintf("%d matching /%s/", matches, dp.logGrep))
 		}
 
-		tw.Append([]string{
-			ci.TypeCode,
-			ci.Name,
-			ci.State,
-			restartCount,
-			ci.ReadyIcon,
-			ci.Image,
-		})
-		if ci.StateMessage != "" {
-			tw.Append([]string{"", "", "", "", "", ci.StateMessage})
+		logHeader := "logs:"
+		if len(qualifiers) > 0 {
+			logHeader = fmt.Sprintf("logs (%s):", strings.Join(qualifiers, ", "))
 		}
-	}
-	tw.Render()
 
-	podFailures, err := dp.getPodFailures(pod)
+		containerLabel := "Container"
+		if cl.Init {
+			containerLabel = "Init Container"
+		}
+		fmt.Fprintf(dp.out, "\n%s %s %s\n\n", aurora.Cyan(containerLabel), cl.ContainerName, aurora.Cyan(logHeader))
+		if cl.Denied != "" {
+			fmt.Fprintf(dp.out, "%s  %s\n", aurora.Yellow("…").String(), aurora.Yellow(cl.Denied))
+			return nil
+		}
+		if cl.TimedOut {
+			notice := fmt.Sprintf("[timed out after %s fetching logs; output may be incomplete]", dp.logTimeout)
+			fmt.Fprintf(dp.out, "%s\n", aurora.Yellow(notice))
+		}
+		if cl.Error != "" {
+			notice := fmt.Sprintf("[reading the logs failed: %s; output may be incomplete]", sanitizeLogLine(cl.Error))
+			fmt.Fprintf(dp.out, "%s\n", aurora.Yellow(notice))
+		}
+		if cl.Dropped > 0 {
+			notice := fmt.Sprintf("[%d earlier lines omitted; log output is capped at %d KiB per container]", cl.Dropped, maxLogBytes/1024)
+			fmt.Fprintf(dp.out, "%s\n", aurora.Yellow(notice))
+		}
+		fmt.Fprintf(dp.out, "%s", dp.formatLogTimestamps(dp.colorizeLogLevels(logs)))
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if podFailures != "" {
-		fmt.Printf("\n")
-		fmt.Printf("%s", podFailures)
+	fmt.Fprintf(dp.out, "\n")
+
+	if dp.sinkReport != nil {
+		return dp.addToSinkReport(pod, captured)
 	}
 
-	podEvents, err := dp.getPodEvents(pod)
+	return nil
+}
+
+// addToSinkReport adds the pod to the structured report for --also-output, reusing the logs
+// the human-readable report has fetched, rather than inspecting the pod a second time.
+func (dp *podInspectCommand) addToSinkReport(pod *v1.Pod, logs []containerLogs) error {
+	if dp.includeSection(logsSection) {
+		if dp.capturedLogs == nil {
+			dp.capturedLogs = map[types.UID][]containerLogs{}
+		}
+		dp.capturedLogs[pod.UID] = logs
+	}
+
+	podReport, err := dp.buildPodReport(pod)
 	if err != nil {
 		return err
 	}
+	delete(dp.capturedLogs, pod.UID)
+	dp.sinkReport.Pods = append(dp.sinkReport.Pods, *podReport)
+	return nil
+}
 
-	if podEvents != "" {
-		fmt.Printf("\n")
-		fmt.Printf("%s", podEvents)
+// printPodHeader prints the report's header: the pod, its node and zone, and its age.
+func (dp *podInspectCommand) printPodHeader(pod *v1.Pod) error {
+	node, err := dp.getScheduledNode(pod)
+	nodeDenied := describeForbidden(err)
+	if err != nil && nodeDenied == "" {
+		return err
 	}
 
-	for containerName, logs := range podLogs {
-		logHeader := "logs:"
-		if dp.numLogLines > 0 {
-			if dp.numLogLines == 1 {
-				logHeader = "logs (last line):"
+	fmt.Fprintf(dp.out, "%s%s / %s\n", aurora.Cyan("Pod:  "), pod.Namespace, pod.Name)
+	if nodeDenied != "" {
+		fmt.Fprintf(dp.out, "%s%s %s\n", aurora.Cyan("Node: "), pod.Spec.NodeName, aurora.Yellow(fmt.Sprintf("(%s)", nodeDenied)))
+		dp.deniedNotes[nodeDenied] = true
+	} else if pod.Spec.NodeName != "" && node == nil {
+		fmt.Fprintf(dp.out, "%s%s %s\n", aurora.Cyan("Node: "), pod.Spec.NodeName, aurora.Red("(node no longer exists)"))
+	} else {
+		fmt.Fprintf(dp.out, "%s%s\n", aurora.Cyan("Node: "), pod.Spec.NodeName)
+	}
+	// zonal outages and AZ skew are much easier to spot with the zone right in the header
+	if node != nil {
+		if zone, region := getNodeTopology(node); zone != "" || region != "" {
+			fmt.Fprintf(dp.out, "%s%s\n", aurora.Cyan("Zone: "), formatTopology(zone, region))
+		}
+	}
+	fmt.Fprintf(dp.out, "%s%s\n", aurora.Cyan("QoS:  "), formatQOSClass(getPodQOSClass(pod)))
+	fmt.Fprintf(dp.out, "%s%s\n", aurora.Cyan("Age:  "), formatAge(pod.CreationTimestamp))
+	fmt.Fprintf(dp.out, "\n")
+
+	return nil
+}
+
+// printContainerTable prints the table of the pod's containers and their states, with the events
+// about each container under its row.
+func (dp *podInspectCommand) printContainerTable(pod *v1.Pod, cinfo map[string]*containerInfo) {
+	// handle complete pod failure; there is no container table to show, but the
+	// remaining sections (conditions, events, ...) usually explain why
+	if len(pod.Status.ContainerStatuses) == 0 {
+		fmt.Fprintf(dp.out, "Phase:     %s\n", pod.Status.Phase)
+		fmt.Fprintf(dp.out, "Reason:    %s\n", pod.Status.Reason)
+		fmt.Fprintf(dp.out, "Message:   %s\n", pod.Status.Message)
+	} else {
+		keys := make([]string, 0, len(cinfo))
+		for k := range cinfo {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		// events about a particular container are shown under its row; a denied or failed list
+		// is reported by the events section
+		events, _, _ := dp.getPodEventList(pod)
+		containerEvents, _ := groupContainerEvents(events, pod)
+
+		fmt.Fprintf(dp.out, "%s\n\n", aurora.Cyan("Containers: "))
+
+		tw := dp.newTablewriter(dp.out)
+
+		tw.Append([]string{
+			aurora.Yellow("Type").String(),
+			aurora.Yellow("Name").String(),
+			aurora.Yellow("State").String(),
+			aurora.Yellow("For").String(),
+			aurora.Yellow("RC").String(),
+			aurora.Yellow("Exit").String(),
+			aurora.Yellow("Ready").String(),
+			aurora.Yellow("Image").String(),
+		})
+		highlighted := false
+		for _, key := range keys {
+			ci := cinfo[key]
+
+			// a container that has been waiting too long to start is stuck, however its row is colored
+			since := formatAge(ci.StateSince)
+			if ci.Stuck {
+				since = aurora.Red(fmt.Sprintf("%s, stuck", since)).String()
+			}
+
+			// rows for containers that need attention are colored as a whole, so that they stand
+			// out in a long table; the other rows only highlight the cells that matter
+			rowColor := getContainerRowColor(ci, pod)
+			if rowColor != nil {
+				highlighted = true
+				if !ci.Stuck {
+					since = rowColor(since).String()
+				}
+				tw.Append([]string{
+					rowColor(ci.TypeCode).String(),
+					rowColor(ci.Name).String(),
+					rowColor(ci.State).String(),
+					since,
+					rowColor(fmt.Sprintf("%d", ci.RestartCount)).String(),
+					rowColor(ci.ExitCode).String(),
+					ci.ReadyIcon,
+					rowColor(ci.Image).String(),
+				})
+				if ci.StateMessage != "" {
+					tw.Append([]string{"", "", "", "", "", "", "", rowColor(ci.StateMessage).String()})
+				}
 			} else {
-				logHeader = fmt.Sprintf("logs (last %d lines):", dp.numLogLines)
+				restartCount := fmt.Sprintf("%d", ci.RestartCount)
+				if ci.RestartCount > 0 {
+					restartCount = aurora.Yellow(fmt.Sprintf(" %s", restartCount)).String()
+				}
+
+				exitCode := ci.ExitCode
+				if exitCode != "" && exitCode != "0" {
+					exitCode = aurora.Red(exitCode).String()
+				}
+
+				tw.Append([]string{
+					ci.TypeCode,
+					ci.Name,
+					ci.State,
+					since,
+					restartCount,
+					exitCode,
+					ci.ReadyIcon,
+					ci.Image,
+				})
+				if ci.StateMessage != "" {
+					tw.Append([]string{"", "", "", "", "", "", "", ci.StateMessage})
+				}
 			}
+			for _, event := range containerEvents[ci.Name] {
+				tw.Append([]string{"", "", "", "", "", "", "", dp.formatContainerEvent(event)})
+			}
+		}
+		tw.Render()
+
+		if highlighted {
+			fmt.Fprintf(dp.out, "\n%s  %s  %s\n", aurora.Red("✖ failed").String(), aurora.Yellow("… waiting or not ready").String(), aurora.Green("✔ ok").String())
 		}
-		fmt.Printf("\n%s %s %s\n\n%s", aurora.Cyan("Container"), containerName, aurora.Cyan(logHeader), logs)
 	}
 
-	fmt.Printf("\n")
+	if blockingInit := getBlockingInitContainer(pod); blockingInit != "" {
+		fmt.Fprintf(dp.out, "\n%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("init container '%s' failed; pod initialization is blocked", blockingInit)))
+	}
+}
 
-	return nil
+// getPod fetches a pod, returning the raw JSON alongside the decoded object; the raw form lets us
+// read fields that are newer than the API types the plugin is built against.
+func (dp *podInspectCommand) getPod(podName string) (*v1.Pod, []byte, error) {
+	if dp.informers != nil {
+		return dp.informers.getPod(dp.namespace, podName)
+	}
+
+	rawPod, err := dp.clientset.CoreV1().RESTClient().Get().Namespace(dp.namespace).Resource("pods").Name(podName).Do(context.Background()).Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(rawPod, pod); err != nil {
+		return nil, nil, err
+	}
+
+	return pod, rawPod, nil
 }
 
-func (dp *podInspectCommand) getPodLogs(podName, containerName string) (string, error) {
+// getPodLogs fetches the tail of a container's logs; it also returns the number of lines that
+// were dropped to stay within maxLogBytes.
+func (dp *podInspectCommand) getPodLogs(ctx context.Context, podName, containerName string, previous bool) (string, int, error) {
 
 	var tailLines int64
 	tailLines = int64(dp.numLogLines)
 
-	logOptions := v1.PodLogOptions{Container: containerName}
+	logOptions := v1.PodLogOptions{Container: containerName, Previous: previous}
 
 	if tailLines > 0 {
 		logOptions.TailLines = &tailLines
 	}
+	logOptions.Timestamps = dp.logTimestamps
+	if dp.logsSince > 0 {
+		sinceSeconds := int64(dp.logsSince.Seconds())
+		logOptions.SinceSeconds = &sinceSeconds
+	} else if dp.logsSinceTime != "" {
+		// validated in run
+		t, _ := time.Parse(time.RFC3339, dp.logsSinceTime)
+		sinceTime := metav1.NewTime(t)
+		logOptions.SinceTime = &sinceTime
+	}
 
 	req := dp.clientset.CoreV1().Pods(dp.namespace).GetLogs(podName, &logOptions)
-	podLogs, err := req.Stream(context.Background())
+	podLogs, err := req.Stream(ctx)
 	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return "", 0, err
+		}
 		// ignore this error -- it could be that the container is in ImagePullBackoff, for example, and has no logs
-		return "", nil
+		return "", 0, nil
 	}
 	defer podLogs.Close()
 
-	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, podLogs)
-	if err != nil {
-		return "", err
-	}
+	return readLogTail(podLogs, maxLogBytes)
+}
 
-	return buf.String(), nil
+// isFailedPodCondition reports whether a condition that isn't True means something is wrong.  A
+// completed pod's Ready and ContainersReady are False with reason PodCompleted, which is how it
+// should be, and custom conditions mapped to info are expected not to be True.
+func (dp *podInspectCommand) isFailedPodCondition(pod *v1.Pod, condition v1.PodCondition) bool {
+	if condition.Status == v1.ConditionTrue || condition.Reason == "PodCompleted" {
+		return false
+	}
+	return builtinPodConditions[condition.Type] || dp.getConditionSeverity(pod, condition.Type) != "info"
 }
 
-func (dp *podInspectCommand) getPodFailures(pod *v1.Pod) (string, error) {
-	retval := ""
+// listPodEvents fetches all of the pod's events.  They are cached, since several sections look
+// for particular events (autoscaler decisions, preemptions, ...) among them.  When sweeping over
+// many pods, they come from an index of the namespace's events, listed once, and when watching,
+// from the events informer.
+func (dp *podInspectCommand) listPodEvents(pod *v1.Pod) ([]v1.Event, error) {
+	if events, ok := dp.podEvents[pod.UID]; ok {
+		return events, nil
+	}
 
-	failedPodConditions := []v1.PodCondition{}
+	if dp.informers != nil {
+		return dp.informers.getPodEvents(pod, dp.includePriorEvents)
+	}
 
-	for _, condition := range pod.Status.Conditions {
-		if condition.Status != v1.ConditionTrue && condition.Reason != "PodCompleted" {
-			failedPodConditions = append(failedPodConditions, condition)
+	if dp.batchEvents {
+		index, err := dp.getPodEventIndex(pod.Namespace)
+		if err != nil {
+			return nil, err
 		}
+		return index.getPodEvents(pod, dp.includePriorEvents), nil
 	}
 
-	if len(failedPodConditions) != 0 {
-		retval += aurora.Cyan(fmt.Sprintf("Failed Pod Conditions:\n\n")).String()
+	// match on kind so that events for other objects with the same name (e.g. a Deployment named
+	// like the pod) are left out, and on UID so that events from an earlier pod with the same
+	// name (StatefulSets recreate pods with identical names) are too, unless asked for
+	field := fmt.Sprintf("involvedObject.kind=Pod,involvedObject.name=%s", pod.Name)
+	if !dp.includePriorEvents {
+		field += fmt.Sprintf(",involvedObject.uid=%s", pod.UID)
+	}
+	eventList, err := dp.clientset.CoreV1().Events(dp.namespace).List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return nil, err
+	}
 
-		sb := &strings.Builder{}
-		tw := dp.newTablewriter(sb)
+	if dp.podEvents == nil {
+		dp.podEvents = map[types.UID][]v1.Event{}
+	}
+	dp.podEvents[pod.UID] = eventList.Items
 
-		tw.Append([]string{
-			aurora.Yellow("Condition").String(),
-			aurora.Yellow("Reason").String(),
-			aurora.Yellow("Message").String(),
-		})
+	return eventList.Items, nil
+}
 
-		for _, condition := range failedPodConditions {
-			tw.Append([]string{
-				string(condition.Type),
-				condition.Reason,
-				condition.Message,
-			})
+// truncateEvents keeps n of the events: every Warning event, as far as they fit, newest first,
+// and the newest Normal events in the room that's left, so that the one warning that matters
+// isn't pushed out by a run of routine events.  The events kept stay in their original order.
+func truncateEvents(events []v1.Event, n int) []v1.Event {
+	keep := make([]bool, len(events))
+	kept := 0
+	for _, warnings := range []bool{true, false} {
+		for i := len(events) - 1; i >= 0 && kept < n; i-- {
+			if !keep[i] && (events[i].Type == v1.EventTypeWarning) == warnings {
+				keep[i] = true
+				kept++
+			}
 		}
+	}
 
-		tw.Render()
-		retval += sb.String()
+	truncated := []v1.Event{}
+	for i, event := range events {
+		if keep[i] {
+			truncated = append(truncated, event)
+		}
 	}
+	return truncated
+}
 
-	return retval, nil
+// getPodEventList fetches the pod's events seen within --events-since, limited to --max-num-events
+// of them, warnings first; the boolean return reports whether any were dropped by the limit.
+func (dp *podInspectCommand) getPodEventList(pod *v1.Pod) ([]v1.Event, bool, error) {
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if dp.eventsSince > 0 {
+		cutoff := time.Now().Add(-dp.eventsSince)
+		recent := []v1.Event{}
+		for _, event := range events {
+			if getEventTimestamp(event).Time.After(cutoff) {
+				recent = append(recent, event)
+			}
+		}
+		events = recent
+	}
+
+	eventsTruncated := false
+	if dp.numEvents > 0 {
+		if len(events) > dp.numEvents {
+			events = truncateEvents(events, dp.numEvents)
+			eventsTruncated = true
+		}
+	}
+
+	return events, eventsTruncated, nil
 }
 
 func (dp *podInspectCommand) getPodEvents(pod *v1.Pod) (string, error) {
 	retval := ""
 
-	field := fmt.Sprintf("involvedObject.name=%s", pod.Name)
-	eventList, err := dp.clientset.CoreV1().Events(dp.namespace).List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	events, eventsTruncated, err := dp.getPodEventList(pod)
 	if err != nil {
 		return "", err
 	}
 
-	events := eventList.Items
+	// the events about a container are listed under it in the container table
+	if len(pod.Status.ContainerStatuses) > 0 {
+		_, events = groupContainerEvents(events, pod)
+	}
 
 	if len(events) == 0 {
 		return "", nil
 	}
 
-	eventsTruncated := false
-	if dp.numEvents > 0 {
-		if len(events) > dp.numEvents {
-			idxLast := len(events)
-			idxFirst := idxLast - dp.numEvents
-
-			events = events[idxFirst:idxLast]
-			eventsTruncated = true
-		}
-	}
-
 	sb := &strings.Builder{}
 	tw := dp.newTablewriter(sb)
 
@@ -399,12 +1133,9 @@ func (dp *podInspectCommand) getPodEvents(pod *v1.Pod) (string, error) {
 	})
 
 	for _, event := range events {
-		timestamp := event.LastTimestamp
-		if timestamp.IsZero() {
-			timestamp = event.CreationTimestamp
-		}
+		timestamp := getEventTimestamp(event)
 		tw.Append([]string{
-			timestamp.String(),
+			dp.formatTimestamp(timestamp),
 			event.Type,
 			event.Reason,
 			event.Message,
@@ -420,8 +1151,10 @@ func (dp *podInspectCommand) getPodEvents(pod *v1.Pod) (string, error) {
 		if len(events) == 1 {
 			retval += aurora.Cyan(fmt.Sprintf("Last pod event:\n\n")).String()
 		} else {
-			retval += aurora.Cyan(fmt.Sprintf("Last %d pod events:\n\n", len(events))).String()
+			retval += aurora.Cyan(fmt.Sprintf("Last %d pod events (warnings kept first):\n\n", len(events))).String()
 		}
+	} else if dp.eventsSince > 0 {
+		retval += aurora.Cyan(fmt.Sprintf("Pod events (last %s):\n\n", duration.HumanDuration(dp.eventsSince))).String()
 	} else {
 		retval += aurora.Cyan(fmt.Sprintf("Pod events:\n\n")).String()
 	}
@@ -430,11 +1163,35 @@ func (dp *podInspectCommand) getPodEvents(pod *v1.Pod) (string, error) {
 	return retval, nil
 }
 
-func getContainerStateInfo(status v1.ContainerStatus) (string, string, int, string) {
+// getEventTimestamp returns the time an event was last seen, falling back to its creation time
+// for events that don't record one.
+func getEventTimestamp(event v1.Event) metav1.Time {
+	timestamp := event.LastTimestamp
+	if timestamp.IsZero() {
+		timestamp = event.CreationTimestamp
+	}
+	return timestamp
+}
+
+// getContainerExitCode returns the exit code of the container's current termination or, failing
+// that, of its last one; it is empty for containers that have never terminated.
+func getContainerExitCode(status v1.ContainerStatus) string {
+	if status.State.Terminated != nil {
+		return fmt.Sprintf("%d", status.State.Terminated.ExitCode)
+	}
+	if status.LastTerminationState.Terminated != nil {
+		return fmt.Sprintf("%d", status.LastTerminationState.Terminated.ExitCode)
+	}
+	return ""
+}
+
+// classifyContainerState breaks a container's status down into a state code ("R", "T", "W", or
+// "n/a"), the reason and message for that state, and our interpretation of whether the
+// container is ok.
+func classifyContainerState(status v1.ContainerStatus) (string, string, string, int) {
 	stateCode := ""
 	reason := ""
 	message := ""
-	readyicon := ""
 
 	state := status.State
 
@@ -464,7 +1221,8 @@ func getContainerStateInfo(status v1.ContainerStatus) (string, string, int, stri
 	} else if state.Terminated != nil {
 		stateCode = "T"
 		reason = state.Terminated.Reason
-		message = state.Terminated.Message
+		// the termination message is written by the container itself, so treat it like log output
+		message = sanitizeText(state.Terminated.Message)
 		if reason != "Completed" {
 			podInspectStatus = PODINSPECT_STATUS_FAILED
 		}
@@ -484,24 +1242,34 @@ func getContainerStateInfo(status v1.ContainerStatus) (string, string, int, stri
 		}
 
 	} else {
-		return "n/a", "", PODINSPECT_STATUS_UNKNOWN, "?"
+		return "n/a", "", "", PODINSPECT_STATUS_UNKNOWN
 	}
 
-	if status.LastTerminationState.Terminated != nil {
-		lts := status.LastTerminationState
+	return stateCode, reason, message, podInspectStatus
+}
 
-		supplementalMessage := fmt.Sprintf("%s  Last Terminated: %s (%d), %s", aurora.Yellow("⚠️").String(), lts.Terminated.Reason, lts.Terminated.ExitCode, lts.Terminated.FinishedAt)
-		if message == "" {
-			message = supplementalMessage
-		} else {
-			message += "\n" + supplementalMessage
-		}
+func getContainerStateInfo(status v1.ContainerStatus) (string, string, int, string) {
+	readyicon := ""
+
+	stateCode, reason, message, podInspectStatus := classifyContainerState(status)
+	if podInspectStatus == PODINSPECT_STATUS_UNKNOWN {
+		return stateCode, "", PODINSPECT_STATUS_UNKNOWN, "?"
 	}
 
 	str1 := stateCode
+	if signal, _ := getTerminationSignal(status.State.Terminated); signal != 0 {
+		reason = strings.TrimPrefix(fmt.Sprintf("%s, %s", reason, getSignalName(signal)), ", ")
+	}
+	// a crashlooping container's table row would otherwise not say that it's running out of memory
+	if lts := status.LastTerminationState.Terminated; status.State.Waiting != nil && lts != nil && lts.Reason == "OOMKilled" {
+		reason = strings.TrimPrefix(fmt.Sprintf("%s, last OOMKilled", reason), ", ")
+	}
 	if reason != "" {
 		str1 = fmt.Sprintf("%s (%s)", stateCode, reason)
 	}
+	if exitMessage := getExitCodeMessage(status); exitMessage != "" {
+		message = strings.TrimPrefix(fmt.Sprintf("%s\n%s", message, exitMessage), "\n")
+	}
 
 	switch podInspectStatus {
 	case PODINSPECT_STATUS_FAILED:
@@ -518,6 +1286,24 @@ func getContainerStateInfo(status v1.ContainerStatus) (string, string, int, stri
 	return str1, message, podInspectStatus, readyicon
 }
 
+// getContainerRowColor returns the color for a container's row in the container table, by how
+// badly the container needs attention: red for a failed container, yellow for one that is
+// waiting, or running but not ready.  Returns nil for a container that is fine.
+func getContainerRowColor(ci *containerInfo, pod *v1.Pod) colorFunc {
+	switch ci.Status {
+	case PODINSPECT_STATUS_FAILED:
+		return aurora.Red
+	case PODINSPECT_STATUS_WAITING:
+		return aurora.Yellow
+	case PODINSPECT_STATUS_OK:
+		// completed init containers are never ready
+		if ci.TypeCode == "C" && !ci.Ready && pod.Status.Phase == v1.PodRunning {
+			return aurora.Yellow
+		}
+	}
+	return nil
+}
+
 func (dp *podInspectCommand) newTablewriter(out io.Writer) *tablewriter.Table {
 	tw := tablewriter.NewWriter(out)
 	tw.SetRowSeparator("")
@@ -527,5 +1313,11 @@ func (dp *podInspectCommand) newTablewriter(out io.Writer) *tablewriter.Table {
 	tw.SetRowLine(false)
 	tw.SetHeaderLine(false)
 	tw.SetAutoWrapText(false)
+	// with a --width, long cells are wrapped at word boundaries, and what still doesn't fit is
+	// folded by the output's widthWriter
+	if dp.width > 0 {
+		tw.SetAutoWrapText(true)
+		tw.SetColWidth(dp.width / 2)
+	}
 	return tw
 }
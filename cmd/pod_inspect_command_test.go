@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestTruncateEvents(t *testing.T) {
+	event := func(reason, eventType string) v1.Event {
+		return v1.Event{Reason: reason, Type: eventType}
+	}
+	reasons := func(events []v1.Event) []string {
+		r := []string{}
+		for _, e := range events {
+			r = append(r, e.Reason)
+		}
+		return r
+	}
+
+	events := []v1.Event{
+		event("Scheduled", v1.EventTypeNormal),
+		event("FailedMount", v1.EventTypeWarning),
+		event("Pulling", v1.EventTypeNormal),
+		event("Pulled", v1.EventTypeNormal),
+		event("BackOff", v1.EventTypeWarning),
+		event("Started", v1.EventTypeNormal),
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{"all fit", 6, []string{"Scheduled", "FailedMount", "Pulling", "Pulled", "BackOff", "Started"}},
+		{"more room than events", 10, []string{"Scheduled", "FailedMount", "Pulling", "Pulled", "BackOff", "Started"}},
+		{"warnings kept over newer normal events", 3, []string{"FailedMount", "BackOff", "Started"}},
+		{"only warnings fit", 2, []string{"FailedMount", "BackOff"}},
+		{"newest warning first", 1, []string{"BackOff"}},
+		{"none", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reasons(truncateEvents(events, tt.n)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("truncateEvents(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
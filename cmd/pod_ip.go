@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func validatePodIP(ip string) error {
+	if ip != "" && net.ParseIP(ip) == nil {
+		return fmt.Errorf("'%s' is not an IP address", ip)
+	}
+	return nil
+}
+
+// resolvePodIP finds the name of the pod that has the given IP.  Pod IPs are reused once a pod
+// has finished, so pods that have run to completion are only considered if nothing else has the
+// IP, and host-network pods (which all share their node's IP) only if no other pod has it.
+func (dp *podInspectCommand) resolvePodIP(ip string) (string, error) {
+	field := fmt.Sprintf("status.podIP=%s", ip)
+	podList, err := dp.clientset.CoreV1().Pods(dp.listNamespace()).List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return "", err
+	}
+	if len(podList.Items) == 0 {
+		if dp.allNamespaces {
+			return "", fmt.Errorf("no pod has IP %s", ip)
+		}
+		return "", fmt.Errorf("no pod in namespace %s has IP %s", dp.namespace, ip)
+	}
+
+	candidates := podList.Items
+	for _, prefer := range []func(p v1.Pod) bool{
+		func(p v1.Pod) bool { return p.Status.Phase != v1.PodSucceeded && p.Status.Phase != v1.PodFailed },
+		func(p v1.Pod) bool { return !p.Spec.HostNetwork },
+	} {
+		preferred := []v1.Pod{}
+		for _, p := range candidates {
+			if prefer(p) {
+				preferred = append(preferred, p)
+			}
+		}
+		if len(preferred) > 0 {
+			candidates = preferred
+		}
+	}
+
+	if len(candidates) > 1 {
+		names := []string{}
+		for _, p := range candidates {
+			names = append(names, fmt.Sprintf("%s/%s", p.Namespace, p.Name))
+		}
+		return "", fmt.Errorf("%d pods have IP %s: %s", len(candidates), ip, strings.Join(names, ", "))
+	}
+
+	// with --all-namespaces, the pod is inspected in the namespace it was found in
+	dp.namespace = candidates[0].Namespace
+
+	return candidates[0].Name, nil
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the Pod Security Standards levels --psa-dry-run can check a pod against, from the least strict
+var podSecurityLevels = []string{"baseline", "restricted"}
+
+func validatePodSecurityLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	for _, l := range podSecurityLevels {
+		if level == l {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported Pod Security level '%s' for --psa-dry-run; must be one of: %s", level, strings.Join(podSecurityLevels, ", "))
+}
+
+// podSecurityViolation is a field of the pod that a Pod Security Standard doesn't allow.
+type podSecurityViolation struct {
+	check  string
+	field  string
+	detail string
+}
+
+// the capabilities the baseline level lets containers add
+var baselineCapabilities = map[v1.Capability]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true, "KILL": true, "MKNOD": true,
+	"NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// the sysctls the baseline level allows, as they're namespaced and can't affect other pods
+var baselineSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.ping_group_range":           true,
+}
+
+// the SELinux types the baseline level allows
+var baselineSELinuxTypes = map[string]bool{"": true, "container_t": true, "container_init_t": true, "container_kvm_t": true}
+
+// the volume types the restricted level allows, by their field in the volume source
+var restrictedVolumeTypes = map[string]bool{
+	"configMap": true, "csi": true, "downwardAPI": true, "emptyDir": true, "ephemeral": true,
+	"persistentVolumeClaim": true, "projected": true, "secret": true,
+}
+
+// the annotation prefix that sets a container's AppArmor profile
+const appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// podContainer is a container with the path of its field in the pod, e.g.
+// spec.initContainers[setup].
+type podContainer struct {
+	field     string
+	container v1.Container
+}
+
+func getPodContainers(pod *v1.Pod) []podContainer {
+	containers := []podContainer{}
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, podContainer{fmt.Sprintf("spec.initContainers[%s]", c.Name), c})
+	}
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, podContainer{fmt.Sprintf("spec.containers[%s]", c.Name), c})
+	}
+	return containers
+}
+
+// getVolumeSourceType names the type of a volume by its field in the volume source, e.g.
+// hostPath, whatever the type.
+func getVolumeSourceType(vol v1.Volume) string {
+	data, err := json.Marshal(vol.VolumeSource)
+	if err != nil {
+		return "unknown"
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "unknown"
+	}
+	for field := range fields {
+		return field
+	}
+	return "unknown"
+}
+
+// podSecurityChecks are the checks of the Pod Security Standards, by the level that introduces
+// them; a level includes the checks of the levels below it.
+var podSecurityChecks = []struct {
+	level string
+	check func(pod *v1.Pod) []podSecurityViolation
+}{
+	{"baseline", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		for field, set := range map[string]bool{"spec.hostNetwork": pod.Spec.HostNetwork, "spec.hostPID": pod.Spec.HostPID, "spec.hostIPC": pod.Spec.HostIPC} {
+			if set {
+				violations = append(violations, podSecurityViolation{"Host Namespaces", field, "must not be true"})
+			}
+		}
+		return violations
+	}},
+	{"baseline", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.HostPath != nil {
+				violations = append(violations, podSecurityViolation{"HostPath Volumes", fmt.Sprintf("spec.volumes[%s].hostPath", vol.Name), fmt.Sprintf("hostPath volumes aren't allowed (%s)", vol.HostPath.Path)})
+			}
+		}
+		return violations
+	}},
+	{"baseline", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		for _, c := range getPodContainers(pod) {
+			sc := c.container.SecurityContext
+			if isPrivilegedContainer(c.container) {
+				violations = append(violations, podSecurityViolation{"Privileged Containers", c.field + ".securityContext.privileged", "must not be true"})
+			}
+			for _, port := range c.container.Ports {
+				if port.HostPort != 0 {
+					violations = append(violations, podSecurityViolation{"Host Ports", fmt.Sprintf("%s.ports[%d].hostPort", c.field, port.ContainerPort), fmt.Sprintf("must be unset, not %d", port.HostPort)})
+				}
+			}
+			if sc == nil {
+				continue
+			}
+			if sc.Capabilities != nil {
+				for _, capability := range sc.Capabilities.Add {
+					if !baselineCapabilities[capability] {
+						violations = append(violations, podSecurityViolation{"Capabilities", c.field + ".securityContext.capabilities.add", fmt.Sprintf("%s can't be added", capability)})
+					}
+				}
+			}
+			if sc.ProcMount != nil && *sc.ProcMount != v1.DefaultProcMount {
+				violations = append(violations, podSecurityViolation{"/proc Mount Type", c.field + ".securityContext.procMount", fmt.Sprintf("must be Default, not %s", *sc.ProcMount)})
+			}
+			if sc.SeccompProfile != nil && sc.SeccompProfile.Type == v1.SeccompProfileTypeUnconfined {
+				violations = append(violations, podSecurityViolation{"Seccomp", c.field + ".securityContext.seccompProfile.type", "must not be Unconfined"})
+			}
+			if opts := sc.SELinuxOptions; opts != nil {
+				violations = append(violations, getSELinuxViolations(c.field+".securityContext.seLinuxOptions", opts)...)
+			}
+		}
+		return violations
+	}},
+	{"baseline", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		if sc := pod.Spec.SecurityContext; sc != nil {
+			if sc.SeccompProfile != nil && sc.SeccompProfile.Type == v1.SeccompProfileTypeUnconfined {
+				violations = append(violations, podSecurityViolation{"Seccomp", "spec.securityContext.seccompProfile.type", "must not be Unconfined"})
+			}
+			if sc.SELinuxOptions != nil {
+				violations = append(violations, getSELinuxViolations("spec.securityContext.seLinuxOptions", sc.SELinuxOptions)...)
+			}
+			for _, sysctl := range sc.Sysctls {
+				if !baselineSysctls[sysctl.Name] {
+					violations = append(violations, podSecurityViolation{"Sysctls", "spec.securityContext.sysctls", fmt.Sprintf("%s isn't one of the safe sysctls", sysctl.Name)})
+				}
+			}
+		}
+		for key, profile := range pod.Annotations {
+			if strings.HasPrefix(key, appArmorAnnotationPrefix) && profile != "runtime/default" && !strings.HasPrefix(profile, "localhost/") {
+				violations = append(violations, podSecurityViolation{"AppArmor", fmt.Sprintf("metadata.annotations[%s]", key), fmt.Sprintf("must be runtime/default or localhost/*, not %s", profile)})
+			}
+		}
+		return violations
+	}},
+	{"restricted", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		for _, vol := range pod.Spec.Volumes {
+			// hostPath volumes are already reported by the baseline check
+			if vol.HostPath != nil {
+				continue
+			}
+			if volumeType := getVolumeSourceType(vol); !restrictedVolumeTypes[volumeType] {
+				violations = append(violations, podSecurityViolation{"Volume Types", fmt.Sprintf("spec.volumes[%s].%s", vol.Name, volumeType), fmt.Sprintf("%s volumes aren't allowed", volumeType)})
+			}
+		}
+		return violations
+	}},
+	{"restricted", func(pod *v1.Pod) []podSecurityViolation {
+		violations := []podSecurityViolation{}
+		podSC := pod.Spec.SecurityContext
+		if podSC == nil {
+			podSC = &v1.PodSecurityContext{}
+		}
+		if podSC.RunAsUser != nil && *podSC.RunAsUser == 0 {
+			violations = append(violations, podSecurityViolation{"Running as Non-root User", "spec.securityContext.runAsUser", "must not be 0"})
+		}
+
+		for _, c := range getPodContainers(pod) {
+			sc := c.container.SecurityContext
+			if sc == nil {
+				sc = &v1.SecurityContext{}
+			}
+
+			if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+				violations = append(violations, podSecurityViolation{"Privilege Escalation", c.field + ".securityContext.allowPrivilegeEscalation", fmt.Sprintf("must be false, not %s", formatOptionalBool(sc.AllowPrivilegeEscalation))})
+			}
+
+			nonRoot := podSC.RunAsNonRoot
+			if sc.RunAsNonRoot != nil {
+				nonRoot = sc.RunAsNonRoot
+			}
+			if nonRoot == nil || !*nonRoot {
+				violations = append(violations, podSecurityViolation{"Running as Non-root", c.field + ".securityContext.runAsNonRoot", fmt.Sprintf("must be true, here or in spec.securityContext, not %s", formatOptionalBool(nonRoot))})
+			}
+			if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+				violations = append(violations, podSecurityViolation{"Running as Non-root User", c.field + ".securityContext.runAsUser", "must not be 0"})
+			}
+
+			seccomp := podSC.SeccompProfile
+			if sc.SeccompProfile != nil {
+				seccomp = sc.SeccompProfile
+			}
+			if seccomp == nil {
+				violations = append(violations, podSecurityViolation{"Seccomp", c.field + ".securityContext.seccompProfile.type", "must be RuntimeDefault or Localhost, here or in spec.securityContext, not unset"})
+			}
+
+			drop := false
+			added := []string{}
+			if sc.Capabilities != nil {
+				for _, capability := range sc.Capabilities.Drop {
+					drop = drop || capability == "ALL"
+				}
+				for _, capability := range sc.Capabilities.Add {
+					if capability != "NET_BIND_SERVICE" {
+						added = append(added, string(capability))
+					}
+				}
+			}
+			if !drop {
+				violations = append(violations, podSecurityViolation{"Capabilities", c.field + ".securityContext.capabilities.drop", "must include ALL"})
+			}
+			if len(added) > 0 {
+				violations = append(violations, podSecurityViolation{"Capabilities", c.field + ".securityContext.capabilities.add", fmt.Sprintf("may only add NET_BIND_SERVICE, not %s", strings.Join(added, ", "))})
+			}
+		}
+		return violations
+	}},
+}
+
+// getSELinuxViolations checks SELinux options against the baseline level, which allows only
+// the container types and no custom user or role.
+func getSELinuxViolations(field string, opts *v1.SELinuxOptions) []podSecurityViolation {
+	violations := []podSecurityViolation{}
+	if !baselineSELinuxTypes[opts.Type] {
+		violations = append(violations, podSecurityViolation{"SELinux", field + ".type", fmt.Sprintf("must be container_t, container_init_t or container_kvm_t, not %s", opts.Type)})
+	}
+	if opts.User != "" {
+		violations = append(violations, podSecurityViolation{"SELinux", field + ".user", "must be unset"})
+	}
+	if opts.Role != "" {
+		violations = append(violations, podSecurityViolation{"SELinux", field + ".role", "must be unset"})
+	}
+	return violations
+}
+
+func formatOptionalBool(b *bool) string {
+	if b == nil {
+		return "unset"
+	}
+	return fmt.Sprintf("%t", *b)
+}
+
+// getPodSecurityViolations checks the pod against the checks of a Pod Security Standards level
+// and those of the levels below it.
+func getPodSecurityViolations(pod *v1.Pod, level string) []podSecurityViolation {
+	violations := []podSecurityViolation{}
+	for _, l := range podSecurityLevels {
+		for _, c := range podSecurityChecks {
+			if c.level == l {
+				violations = append(violations, c.check(pod)...)
+			}
+		}
+		if l == level {
+			break
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].field < violations[j].field })
+	return violations
+}
+
+// getPodSecurityDryRun checks, with --psa-dry-run, whether the running pod would be admitted if
+// its namespace enforced a stricter Pod Security level, and lists the fields that would get it
+// rejected, so that a namespace can be prepared for the label change with the pods it actually
+// runs rather than with their manifests.  The checks are those of the Pod Security Standards,
+// done here rather than by the API server, which can only dry-run a namespace label change.
+func (dp *podInspectCommand) getPodSecurityDryRun(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if dp.psaDryRun == "" {
+		return "", nil
+	}
+
+	retval += aurora.Cyan(fmt.Sprintf("Pod Security Dry Run (%s):\n\n", dp.psaDryRun)).String()
+
+	ns, err := dp.clientset.CoreV1().Namespaces().Get(context.Background(), pod.Namespace, metav1.GetOptions{})
+	if err == nil {
+		current := ns.Labels[podSecurityEnforceLabel]
+		if current == "" {
+			current = "privileged"
+		}
+		retval += fmt.Sprintf("namespace %s enforces the %s level\n\n", pod.Namespace, current)
+	} else if describeForbidden(err) == "" {
+		return "", err
+	}
+
+	violations := getPodSecurityViolations(pod, dp.psaDryRun)
+	if len(violations) == 0 {
+		retval += fmt.Sprintf("%s  the pod would be admitted under the %s level\n", aurora.Green("✔").String(), dp.psaDryRun)
+		return retval, nil
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Check").String(),
+		aurora.Yellow("Field").String(),
+		aurora.Yellow("Violation").String(),
+	})
+	for _, v := range violations {
+		tw.Append([]string{v.check, v.field, v.detail})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	retval += fmt.Sprintf("\n%s  the pod would be rejected under the %s level, with %d violations\n", aurora.Red("✖").String(), dp.psaDryRun, len(violations))
+
+	return retval, nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// resolvePodUID finds the name of the pod with the given UID.  Pods can't be listed by UID, so
+// the namespace's pods (every namespace's, with --all-namespaces) are listed a page at a time
+// and matched here.  If no pod has the UID any more, the pod is looked for in the events about it
+// instead, and the pod that was found is returned with false: it has been deleted, and quite
+// possibly replaced by a pod with the same name, as StatefulSets do.
+func (dp *podInspectCommand) resolvePodUID(uid string) (*v1.Pod, bool, error) {
+	opts := metav1.ListOptions{Limit: podListPageSize}
+	for {
+		pods, err := dp.clientset.CoreV1().Pods(dp.listNamespace()).List(context.Background(), opts)
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range pods.Items {
+			if string(pods.Items[i].UID) == uid {
+				// with --all-namespaces, the pod is inspected in the namespace it was found in
+				dp.namespace = pods.Items[i].Namespace
+				return &pods.Items[i], true, nil
+			}
+		}
+		if pods.Continue == "" {
+			break
+		}
+		opts.Continue = pods.Continue
+	}
+
+	field := fmt.Sprintf("involvedObject.kind=Pod,involvedObject.uid=%s", uid)
+	eventList, err := dp.clientset.CoreV1().Events(dp.listNamespace()).List(context.Background(), metav1.ListOptions{FieldSelector: field, Limit: 1})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(eventList.Items) == 0 {
+		if dp.allNamespaces {
+			return nil, false, fmt.Errorf("no pod has UID %s", uid)
+		}
+		return nil, false, fmt.Errorf("no pod in namespace %s has UID %s", dp.namespace, uid)
+	}
+
+	ref := eventList.Items[0].InvolvedObject
+	dp.namespace = ref.Namespace
+	pod := &v1.Pod{}
+	pod.Name = ref.Name
+	pod.Namespace = ref.Namespace
+	pod.UID = types.UID(uid)
+
+	return pod, false, nil
+}
+
+// displayDeletedPod prints what is left of a pod that no longer exists: its events, which
+// outlive it for the events TTL (an hour, by default), and the pod that has taken its name, if
+// any.
+func (dp *podInspectCommand) displayDeletedPod(pod *v1.Pod) error {
+	fmt.Fprintf(dp.out, "%s%s / %s\n", aurora.Cyan("Pod:  "), pod.Namespace, pod.Name)
+	fmt.Fprintf(dp.out, "%s%s\n\n", aurora.Cyan("UID:  "), pod.UID)
+
+	fmt.Fprintf(dp.out, "%s  the pod with this UID has been deleted; only its events are left\n", aurora.Yellow("…").String())
+	current, _, err := dp.getPod(pod.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if current != nil {
+		fmt.Fprintf(dp.out, "%s  pod %s has since been recreated, with UID %s\n", aurora.Yellow("…").String(), pod.Name, current.UID)
+	}
+
+	// only the deleted pod's own events, whatever --include-prior-events says
+	dp.includePriorEvents = false
+	return dp.printSection(dp.getPodEvents(pod))
+}
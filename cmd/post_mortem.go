@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// what the events in a pod's history say happened to it, by reason; healthy endings are ok
+var postMortemReasons = map[string]struct {
+	what string
+	ok   bool
+}{
+	"Evicted":              {"evicted by the kubelet, which was running short of a resource on the node", false},
+	"Preempted":            {"preempted, to make room for a higher-priority pod", false},
+	"TaintManagerEviction": {"evicted from a node with a NoExecute taint it didn't tolerate", false},
+	"NodeNotReady":         {"its node stopped reporting, and the pod was deleted with it", false},
+	"OOMKilling":           {"a container of it was OOM-killed", false},
+	"BackOff":              {"its containers were crashlooping", false},
+	"DeadlineExceeded":     {"its Job ran past activeDeadlineSeconds", false},
+	"BackoffLimitExceeded": {"its Job failed more times than backoffLimit allows", false},
+	"SuccessfulDelete":     {"deleted by its controller, e.g. on a scale-down or rollout", true},
+	"Completed":            {"its Job completed", true},
+}
+
+// the characters of a pod name, which can't border a mention of it in a message
+var podNameCharRegexp = regexp.MustCompile(`[a-z0-9.-]`)
+
+// mentionsPod reports whether an event message mentions the pod by name, e.g. a ReplicaSet's
+// "Deleted pod: web-7d4b9-x2x8f", without taking pod web-1 to be mentioned by web-10's events.
+func mentionsPod(message, name string) bool {
+	for i := strings.Index(message, name); i >= 0; {
+		end := i + len(name)
+		before := i > 0 && podNameCharRegexp.MatchString(message[i-1:i])
+		after := end < len(message) && podNameCharRegexp.MatchString(message[end:end+1])
+		if !before && !after {
+			return true
+		}
+		next := strings.Index(message[i+1:], name)
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return false
+}
+
+// getPodTraces lists the events left of a pod that no longer exists: its own, which outlive it
+// for the events TTL, and those of its controllers that mention it (creating it, deleting it),
+// along with the Job events that say how a Job pod ended.  They're sorted oldest first.
+func (dp *podInspectCommand) getPodTraces(podName string) ([]v1.Event, error) {
+	traces := []v1.Event{}
+	jobs := map[string]bool{}
+	jobEvents := []v1.Event{}
+
+	opts := metav1.ListOptions{Limit: eventListPageSize}
+	for {
+		eventList, err := dp.clientset.CoreV1().Events(dp.namespace).List(context.Background(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range eventList.Items {
+			obj := event.InvolvedObject
+			switch {
+			case obj.Kind == "Pod" && obj.Name == podName:
+				traces = append(traces, event)
+			case obj.Kind != "Pod" && mentionsPod(event.Message, podName):
+				traces = append(traces, event)
+				if obj.Kind == "Job" {
+					jobs[obj.Name] = true
+				}
+			case obj.Kind == "Job":
+				jobEvents = append(jobEvents, event)
+			}
+		}
+		if eventList.Continue == "" {
+			break
+		}
+		opts.Continue = eventList.Continue
+	}
+
+	for _, event := range jobEvents {
+		if _, ok := postMortemReasons[event.Reason]; ok && jobs[event.InvolvedObject.Name] {
+			traces = append(traces, event)
+		}
+	}
+
+	sort.SliceStable(traces, func(i, j int) bool {
+		return getEventTimestamp(traces[i]).Time.Before(getEventTimestamp(traces[j]).Time)
+	})
+	return traces, nil
+}
+
+// displayPostMortem prints what can still be found out about a pod that doesn't exist any more,
+// from the events about it and its controllers: whether it was evicted, preempted, OOM-killed,
+// or ran to completion.  The pod one is paged about is often gone by the time anyone looks; its
+// controller has replaced it, or its Job has been cleaned up.  The error is returned as it is if
+// nothing is left of the pod either.
+func (dp *podInspectCommand) displayPostMortem(podName string, notFound error) error {
+	traces, err := dp.getPodTraces(podName)
+	if err != nil {
+		return err
+	}
+	if len(traces) == 0 {
+		return fmt.Errorf("%v, and no events mention it; events are kept for an hour by default", notFound)
+	}
+
+	fmt.Fprintf(dp.out, "%s%s / %s\n\n", aurora.Cyan("Pod:  "), dp.namespace, podName)
+	fmt.Fprintf(dp.out, "%s  the pod doesn't exist any more; this is what the events say about it\n", aurora.Yellow("…").String())
+
+	uids := map[types.UID]bool{}
+	for _, event := range traces {
+		if event.InvolvedObject.Kind == "Pod" {
+			uids[event.InvolvedObject.UID] = true
+		}
+	}
+	if len(uids) > 1 {
+		fmt.Fprintf(dp.out, "%s  the events are about %d pods that have had this name\n", aurora.Yellow("…").String(), len(uids))
+	}
+
+	events := traces
+	if dp.numEvents > 0 && len(events) > dp.numEvents {
+		events = truncateEvents(events, dp.numEvents)
+	}
+
+	retval := aurora.Cyan("Post-Mortem:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Last Seen").String(),
+		aurora.Yellow("Object").String(),
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, event := range events {
+		object := "pod"
+		if obj := event.InvolvedObject; obj.Kind != "Pod" {
+			object = fmt.Sprintf("%s/%s", obj.Kind, obj.Name)
+		}
+		tw.Append([]string{
+			dp.formatTimestamp(getEventTimestamp(event)),
+			object,
+			event.Type,
+			event.Reason,
+			event.Message,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	// the last thing known to have happened to the pod is the likeliest reason it's gone
+	for i := len(traces) - 1; i >= 0; i-- {
+		reason, ok := postMortemReasons[traces[i].Reason]
+		if !ok {
+			continue
+		}
+		icon := aurora.Red("✖").String()
+		if reason.ok {
+			icon = aurora.Green("✔").String()
+		}
+		retval += fmt.Sprintf("\n%s  most likely %s: %s\n", icon, reason.what, traces[i].Message)
+		break
+	}
+
+	return dp.printSection(retval, nil)
+}
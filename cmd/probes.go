@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// the kubelet's defaults for a probe's timeoutSeconds and successThreshold
+const (
+	defaultProbeTimeoutSeconds   = 1
+	defaultProbeSuccessThreshold = 1
+)
+
+// what a probe failure message usually means, by what's in it
+var probeFailureHints = []struct {
+	match string
+	hint  string
+}{
+	{"connection refused", "nothing is listening on the probed port; check the port, and that the app listens on all interfaces rather than on localhost"},
+	{"no route to host", "the pod's network isn't up, or a network policy is dropping the kubelet's probes"},
+	{"context deadline exceeded", "the check took longer than timeoutSeconds; the endpoint is slow or blocked, or the timeout is too short"},
+	{"timeout", "the check took longer than timeoutSeconds; the endpoint is slow or blocked, or the timeout is too short"},
+	{"statuscode: 404", "the probed path doesn't exist; check the probe's path"},
+	{"statuscode: 401", "the endpoint wants authentication, which the kubelet's probes don't send"},
+	{"statuscode: 403", "the endpoint wants authentication, which the kubelet's probes don't send"},
+	{"statuscode: 5", "the app reports itself unhealthy"},
+	{"server gave http response to https client", "the probe uses HTTPS against a plain HTTP endpoint; set its scheme to HTTP"},
+	{"executable file not found", "the command of the exec probe isn't in the image"},
+}
+
+// getProbeFailureHint returns what a probe failure message usually means, or "".
+func getProbeFailureHint(message string) string {
+	message = strings.ToLower(message)
+	for _, h := range probeFailureHints {
+		if strings.Contains(message, h.match) {
+			return h.hint
+		}
+	}
+	return ""
+}
+
+// describeProbePort renders a probe's port, resolving a named port against the container's.
+// The boolean result is false if the port isn't one the container declares.
+func describeProbePort(port intstr.IntOrString, c v1.Container) (string, bool) {
+	for _, p := range c.Ports {
+		if port.Type == intstr.String && p.Name == port.StrVal {
+			return fmt.Sprintf("%d (%s)", p.ContainerPort, p.Name), true
+		}
+		if port.Type == intstr.Int && p.ContainerPort == port.IntVal {
+			return fmt.Sprintf("%d", p.ContainerPort), true
+		}
+	}
+	return port.String(), port.Type == intstr.Int && len(c.Ports) == 0
+}
+
+// describeProbeCheck renders what a probe checks, e.g. "HTTP GET http://:8080/healthz", and
+// whether its port is one the container declares.  A named port the container doesn't declare
+// can't be probed at all; a numbered one only matters if the container declares ports, since
+// declaring them is optional.
+func describeProbeCheck(p *v1.Probe, c v1.Container) (string, bool) {
+	switch {
+	case p.HTTPGet != nil:
+		scheme := strings.ToLower(string(p.HTTPGet.Scheme))
+		if scheme == "" {
+			scheme = "http"
+		}
+		port, ok := describeProbePort(p.HTTPGet.Port, c)
+		return fmt.Sprintf("HTTP GET %s://%s:%s%s", scheme, p.HTTPGet.Host, port, p.HTTPGet.Path), ok
+	case p.TCPSocket != nil:
+		port, ok := describeProbePort(p.TCPSocket.Port, c)
+		return fmt.Sprintf("TCP %s:%s", p.TCPSocket.Host, port), ok
+	case p.Exec != nil:
+		return fmt.Sprintf("exec %s", strings.Join(p.Exec.Command, " ")), true
+	}
+	return "(no handler)", false
+}
+
+// describeProbeTiming renders a probe's thresholds, with the kubelet's defaults filled in.
+func describeProbeTiming(p *v1.Probe) string {
+	timeout := p.TimeoutSeconds
+	if timeout <= 0 {
+		timeout = defaultProbeTimeoutSeconds
+	}
+	success := p.SuccessThreshold
+	if success <= 0 {
+		success = defaultProbeSuccessThreshold
+	}
+	failure := p.FailureThreshold
+	if failure <= 0 {
+		failure = defaultProbeFailureThreshold
+	}
+	return fmt.Sprintf("delay %ds, every %s, timeout %ds, %d to fail, %d to succeed", p.InitialDelaySeconds, getProbePeriod(p), timeout, failure, success)
+}
+
+// getProbeFailures returns how many times a probe of the container failed, from its Unhealthy
+// events ("Readiness probe failed: ..."), and the most recent failure message.
+func getProbeFailures(events []v1.Event, probe string) (int32, string) {
+	prefix := strings.Title(probe) + " probe failed"
+	failures := int32(0)
+	last := v1.Event{}
+	for _, event := range events {
+		if event.Reason != "Unhealthy" || !strings.HasPrefix(event.Message, prefix) {
+			continue
+		}
+		if event.Count > 1 {
+			failures += event.Count
+		} else {
+			failures++
+		}
+		if getEventTimestamp(event).After(getEventTimestamp(last).Time) {
+			last = event
+		}
+	}
+	return failures, strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(last.Message, prefix), ":"))
+}
+
+// getProbes lists each container's startup, liveness and readiness probes, what they check and
+// how often, next to how often they have failed recently, by the kubelet's Unhealthy events.  A
+// pod that runs but never becomes ready is, more often than not, a probe on the wrong port or
+// path, or with a timeout too short for the endpoint; the last failure message of each probe is
+// shown with what it usually means.
+func (dp *podInspectCommand) getProbes(pod *v1.Pod) (string, error) {
+	type containerProbe struct {
+		name  string
+		probe *v1.Probe
+	}
+
+	containers := []v1.Container{}
+	for _, c := range pod.Spec.Containers {
+		if c.StartupProbe != nil || c.LivenessProbe != nil || c.ReadinessProbe != nil {
+			containers = append(containers, c)
+		}
+	}
+	if len(containers) == 0 {
+		return "", nil
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+	containerEvents, _ := groupContainerEvents(events, pod)
+
+	retval := aurora.Cyan("Probes:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Probe").String(),
+		aurora.Yellow("Check").String(),
+		aurora.Yellow("Timing").String(),
+		aurora.Yellow("Recent Failures").String(),
+	})
+
+	notes := []string{}
+	for _, c := range containers {
+		for _, p := range []containerProbe{{"startup", c.StartupProbe}, {"liveness", c.LivenessProbe}, {"readiness", c.ReadinessProbe}} {
+			if p.probe == nil {
+				continue
+			}
+
+			check, portOK := describeProbeCheck(p.probe, c)
+			if !portOK {
+				check = fmt.Sprintf("%s  %s", aurora.Yellow("…").String(), check)
+				notes = append(notes, fmt.Sprintf("%s  the %s probe of container %s checks a port the container doesn't declare", aurora.Yellow("…").String(), p.name, c.Name))
+			}
+
+			failures, message := getProbeFailures(containerEvents[c.Name], p.name)
+			recent := "none"
+			if failures > 0 {
+				recent = aurora.Red(fmt.Sprintf("%d", failures)).String()
+				note := fmt.Sprintf("%s  %s probe of container %s: %s", aurora.Red("✖").String(), strings.Title(p.name), c.Name, message)
+				if hint := getProbeFailureHint(message); hint != "" {
+					note += fmt.Sprintf(" — %s", hint)
+				}
+				notes = append(notes, note)
+			}
+
+			tw.Append([]string{c.Name, p.name, check, describeProbeTiming(p.probe), recent})
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
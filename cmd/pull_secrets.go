@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dockerCredential is one registry's entry in a .dockerconfigjson or .dockercfg secret.
+type dockerCredential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+// getDockerCredentials decodes the registry credentials in an image pull secret, keyed by
+// registry as written in the secret.
+func getDockerCredentials(secret *v1.Secret) (map[string]dockerCredential, error) {
+	creds := map[string]dockerCredential{}
+
+	switch secret.Type {
+	case v1.SecretTypeDockerConfigJson:
+		var config struct {
+			Auths map[string]dockerCredential `json:"auths"`
+		}
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+			return nil, fmt.Errorf("can't decode %s: %v", v1.DockerConfigJsonKey, err)
+		}
+		creds = config.Auths
+	case v1.SecretTypeDockercfg:
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigKey], &creds); err != nil {
+			return nil, fmt.Errorf("can't decode %s: %v", v1.DockerConfigKey, err)
+		}
+	default:
+		return nil, fmt.Errorf("secret is of type %s, not %s, so the kubelet ignores it", secret.Type, v1.SecretTypeDockerConfigJson)
+	}
+
+	for key, cred := range creds {
+		if cred.Auth != "" && cred.Username == "" {
+			decoded, err := base64.StdEncoding.DecodeString(cred.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("can't decode the auth for %s: %v", key, err)
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("the auth for %s isn't a username:password pair", key)
+			}
+			cred.Username, cred.Password = parts[0], parts[1]
+			creds[key] = cred
+		}
+	}
+
+	return creds, nil
+}
+
+// normalizeRegistryHost reduces a credential key ("https://index.docker.io/v1/",
+// "registry.example.com/team") to the registry host it applies to.
+func normalizeRegistryHost(key string) string {
+	host := key
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	switch host {
+	case "docker.io", "index.docker.io", "registry.hub.docker.com":
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// findDockerCredential finds the credential the kubelet would use for the registry, allowing
+// for wildcard keys such as *.dkr.ecr.us-east-1.amazonaws.com.  Like the kubelet, a key for the
+// registry itself wins over wildcards, and a more specific wildcard over a broader one.
+func findDockerCredential(creds map[string]dockerCredential, registry string) (string, dockerCredential, bool) {
+	keys := make([]string, 0, len(creds))
+	for key := range creds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	best, bestSpecificity := "", -1
+	for _, key := range keys {
+		host := normalizeRegistryHost(key)
+		if host == registry {
+			return key, creds[key], true
+		}
+		// the more of the pattern that's literal, the more specific it is
+		if matched, _ := path.Match(host, registry); matched {
+			if specificity := len(strings.Replace(host, "*", "", -1)); specificity > bestSpecificity {
+				best, bestSpecificity = key, specificity
+			}
+		}
+	}
+	if bestSpecificity < 0 {
+		return "", dockerCredential{}, false
+	}
+	return best, creds[best], true
+}
+
+// isImagePullFailure reports whether a container is failing to pull its image.
+func isImagePullFailure(cs v1.ContainerStatus) bool {
+	if cs.State.Waiting == nil {
+		return false
+	}
+	switch cs.State.Waiting.Reason {
+	case "ErrImagePull", "ImagePullBackOff":
+		return true
+	}
+	return false
+}
+
+// pullSecret is one of a pod's image pull secrets, decoded.
+type pullSecret struct {
+	name  string
+	creds map[string]dockerCredential
+	err   error
+}
+
+// getPullSecrets fetches and decodes the pod's image pull secrets.  A secret that is missing or
+// can't be decoded is returned with its error, since the kubelet carries on without it.
+func (dp *podInspectCommand) getPullSecrets(pod *v1.Pod) ([]pullSecret, error) {
+	secrets := []pullSecret{}
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		secret, err := dp.clientset.CoreV1().Secrets(pod.Namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				// the kubelet carries on without a missing pull secret, and so do we
+				secrets = append(secrets, pullSecret{name: ref.Name, err: fmt.Errorf("secret does not exist in namespace %s", pod.Namespace)})
+				continue
+			}
+			return nil, err
+		}
+		creds, err := getDockerCredentials(secret)
+		secrets = append(secrets, pullSecret{name: ref.Name, creds: creds, err: err})
+	}
+	return secrets, nil
+}
+
+// newPullSecretRegistryClient returns a registry client for the image's registry, with the
+// first of the pull secrets' credentials that apply to it, and a description of those
+// credentials.
+func newPullSecretRegistryClient(secrets []pullSecret, ref imageReference) (*registryClient, string) {
+	rc := newRegistryClient()
+	for _, s := range secrets {
+		if key, cred, ok := findDockerCredential(s.creds, ref.Registry); ok {
+			rc.username, rc.password = cred.Username, cred.Password
+			return rc, fmt.Sprintf("%s (%s, user %s)", s.name, key, cred.Username)
+		}
+	}
+	return rc, "none (anonymous)"
+}
+
+// describeUncoveredRegistry explains that none of the pod's pull secrets has credentials for the
+// image's registry, naming the registries they do cover, which is usually a typo or a missing
+// port away from the right one.  Returns "" if the pod has no usable pull secrets, as is normal
+// for public images.
+func describeUncoveredRegistry(secrets []pullSecret, registry string) string {
+	covered := []string{}
+	for _, s := range secrets {
+		for key := range s.creds {
+			covered = append(covered, fmt.Sprintf("%s (%s)", key, s.name))
+		}
+	}
+	if len(covered) == 0 {
+		return ""
+	}
+	sort.Strings(covered)
+	return fmt.Sprintf("no pull secret has credentials for registry %s, so the image is pulled anonymously; the pull secrets cover %s", registry, strings.Join(covered, ", "))
+}
+
+// describeRegistryResult explains what a manifest request with the pull credentials tells us
+// about why the pull is failing.
+func describeRegistryResult(err error, authenticated bool) string {
+	if err == nil {
+		return fmt.Sprintf("%s  image exists and the credentials can pull it; the failure is on the node's side (network, proxy, or rate limiting)", aurora.Green("✔").String())
+	}
+
+	if errors.Is(err, errInsecureTokenRealm) {
+		return fmt.Sprintf("%s  %v", aurora.Red("✖").String(), err)
+	}
+
+	rerr, ok := err.(*registryError)
+	if !ok {
+		return fmt.Sprintf("%s  registry not reachable from here: %v", aurora.Yellow("…").String(), err)
+	}
+
+	switch rerr.StatusCode {
+	case http.StatusUnauthorized:
+		if authenticated {
+			return fmt.Sprintf("%s  credentials rejected; they have expired or are invalid", aurora.Red("✖").String())
+		}
+		return fmt.Sprintf("%s  registry requires credentials, and the pod has no pull secret for it", aurora.Red("✖").String())
+	case http.StatusForbidden:
+		return fmt.Sprintf("%s  credentials are valid but aren't allowed to pull this repository", aurora.Red("✖").String())
+	case http.StatusNotFound:
+		return fmt.Sprintf("%s  image not found; check the repository name and tag", aurora.Red("✖").String())
+	case http.StatusTooManyRequests:
+		return fmt.Sprintf("%s  registry is rate limiting pulls", aurora.Red("✖").String())
+	}
+
+	return fmt.Sprintf("%s  %v", aurora.Yellow("…").String(), rerr)
+}
+
+// getPullSecretValidation checks, for containers that can't pull their image, the pod's image
+// pull secrets against the registry: it decodes the credentials that apply to the image's
+// registry and requests the image manifest with them, which tells expired or invalid
+// credentials apart from an image that doesn't exist.  Misconfigurations that need no registry
+// to spot are called out first: an image reference that doesn't parse, a pull secret that is
+// missing or of the wrong type, and pull secrets that don't cover the image's registry.  Sending
+// the credentials to the registry only happens with --validate-pull-secrets.
+func (dp *podInspectCommand) getPullSecretValidation(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	failing := map[string]bool{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if isImagePullFailure(cs) {
+			failing[cs.Name] = true
+		}
+	}
+	if len(failing) == 0 {
+		return "", nil
+	}
+
+	secrets, err := dp.getPullSecrets(pod)
+	if err != nil {
+		return "", err
+	}
+
+	retval += aurora.Cyan("Image Pull Credentials:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Image").String(),
+		aurora.Yellow("Credentials").String(),
+		aurora.Yellow("Result").String(),
+	})
+
+	notes := []string{}
+	for _, s := range secrets {
+		if s.err != nil {
+			tw.Append([]string{"", "", s.name, fmt.Sprintf("%s  %v", aurora.Red("✖").String(), s.err)})
+		}
+	}
+
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		if !failing[c.Name] {
+			continue
+		}
+
+		ref, err := parseImageReference(c.Image)
+		if err == nil {
+			err = validateImageReference(ref)
+		}
+		if err != nil {
+			tw.Append([]string{c.Name, c.Image, "", fmt.Sprintf("%s  %v", aurora.Red("✖").String(), err)})
+			continue
+		}
+
+		rc, credentials := newPullSecretRegistryClient(secrets, ref)
+		if rc.username == "" {
+			if note := describeUncoveredRegistry(secrets, ref.Registry); note != "" {
+				notes = append(notes, fmt.Sprintf("%s  container %s: %s", aurora.Red("✖").String(), c.Name, note))
+			}
+		}
+
+		if !dp.validatePullSecrets {
+			tw.Append([]string{c.Name, c.Image, credentials, fmt.Sprintf("%s  not checked against the registry; pass --validate-pull-secrets to send the credentials to it", aurora.Yellow("…").String())})
+			continue
+		}
+
+		_, _, err = rc.getManifest(context.Background(), ref)
+		tw.Append([]string{
+			c.Name,
+			c.Image,
+			credentials,
+			describeRegistryResult(err, rc.username != ""),
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// newTestSecretsClientset returns a clientset whose API server only knows the given secrets, and
+// fails the test on any other request.
+func newTestSecretsClientset(t *testing.T, secrets ...*v1.Secret) *kubernetes.Clientset {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for _, s := range secrets {
+			if r.URL.Path == "/api/v1/namespaces/"+s.Namespace+"/secrets/"+s.Name {
+				s.APIVersion, s.Kind = "v1", "Secret"
+				_ = json.NewEncoder(w).Encode(s)
+				return
+			}
+		}
+		if !strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/default/secrets/") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		status := apierrors.NewNotFound(v1.Resource("secrets"), r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]).ErrStatus
+		status.APIVersion, status.Kind = "v1", "Status"
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return kubernetes.NewForConfigOrDie(&rest.Config{Host: srv.URL})
+}
+
+func TestGetPullSecretValidationWithoutRegistryCheck(t *testing.T) {
+	regcred := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "regcred", Namespace: "default"},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: []byte(`{"auths":{"registry.example.com":{"username":"ci","password":"secret"}}}`),
+		},
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.PodSpec{
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "missing"}, {Name: "regcred"}},
+			Containers:       []v1.Container{{Name: "app", Image: "quay.io/team/app:1"}},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{
+				Name:  "app",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+		},
+	}
+
+	// the registry isn't asked without --validate-pull-secrets, so the image's registry needn't exist
+	dp := &podInspectCommand{clientset: newTestSecretsClientset(t, regcred)}
+	got, err := dp.getPullSecretValidation(pod)
+	if err != nil {
+		t.Fatalf("getPullSecretValidation() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"secret does not exist in namespace default",
+		"no pull secret has credentials for registry quay.io",
+		"pass --validate-pull-secrets",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("getPullSecretValidation() = %q, want it to contain %q", got, want)
+		}
+	}
+}
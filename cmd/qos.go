@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// getPodQOSClass returns the pod's QoS class, as the API server recorded it, or as it works it
+// out from the containers' requests and limits if it hasn't yet: Guaranteed if every container
+// has CPU and memory limits with requests equal to them, BestEffort if no container has any
+// requests or limits, and Burstable otherwise.
+func getPodQOSClass(pod *v1.Pod) v1.PodQOSClass {
+	if pod.Status.QOSClass != "" {
+		return pod.Status.QOSClass
+	}
+
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	anySet, guaranteed := false, true
+	for _, c := range containers {
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			request, hasRequest := c.Resources.Requests[name]
+			limit, hasLimit := c.Resources.Limits[name]
+			if (hasRequest && !request.IsZero()) || (hasLimit && !limit.IsZero()) {
+				anySet = true
+			}
+			// an unset request defaults to the limit
+			if !hasLimit || (hasRequest && request.Cmp(limit) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case !anySet:
+		return v1.PodQOSBestEffort
+	case guaranteed:
+		return v1.PodQOSGuaranteed
+	}
+	return v1.PodQOSBurstable
+}
+
+// formatQOSClass renders the QoS class for the header, with what it means under node pressure.
+func formatQOSClass(qos v1.PodQOSClass) string {
+	switch qos {
+	case v1.PodQOSBestEffort:
+		return fmt.Sprintf("%s (no requests; first to be evicted or OOM-killed under node pressure)", aurora.Yellow(string(qos)))
+	case v1.PodQOSBurstable:
+		return fmt.Sprintf("%s (evicted under node pressure when using more than its requests)", qos)
+	}
+	return fmt.Sprintf("%s (last to be evicted)", qos)
+}
+
+// isPodEvicted reports whether the kubelet has evicted the pod, or is evicting it, by its status
+// or its events.
+func isPodEvicted(pod *v1.Pod, events []v1.Event) bool {
+	if pod.Status.Reason == "Evicted" {
+		return true
+	}
+	for _, event := range events {
+		if event.Reason == "Evicted" {
+			return true
+		}
+	}
+	return false
+}
+
+// getQOSImplications explains an eviction or an OOM kill by the pod's QoS class.  Under node
+// pressure the kubelet evicts BestEffort pods first and Burstable pods that use more than they
+// request next, and the kernel's OOM killer picks the same order, so a pod that keeps dying while
+// its own limits look fine often only needs requests that reflect what it uses.
+func (dp *podInspectCommand) getQOSImplications(pod *v1.Pod) (string, error) {
+	qos := getPodQOSClass(pod)
+	if qos == v1.PodQOSGuaranteed {
+		return "", nil
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+
+	oomKilled := []string{}
+	for _, c := range getContainersWithStatus(pod) {
+		if getOOMKill(c.Status) != nil {
+			oomKilled = append(oomKilled, c.Container.Name)
+		}
+	}
+	evicted := isPodEvicted(pod, events)
+	if !evicted && len(oomKilled) == 0 {
+		return "", nil
+	}
+
+	retval := aurora.Cyan(fmt.Sprintf("QoS Class %s:\n\n", qos)).String()
+
+	what := "evicted"
+	if len(oomKilled) > 0 {
+		what = fmt.Sprintf("OOM-killed (%s)", strings.Join(oomKilled, ", "))
+		if evicted {
+			what = "evicted and " + what
+		}
+	}
+
+	switch qos {
+	case v1.PodQOSBestEffort:
+		retval += fmt.Sprintf("%s  the pod was %s, and as a BestEffort pod, which requests nothing, it's the first the kubelet evicts and the kernel kills when the node runs short of memory\n", aurora.Red("✖").String(), what)
+		retval += fmt.Sprintf("%s  give its containers memory and CPU requests (and a memory limit) to make it Burstable, or requests equal to limits to make it Guaranteed\n", aurora.Yellow("…").String())
+	case v1.PodQOSBurstable:
+		retval += fmt.Sprintf("%s  the pod was %s; as a Burstable pod it goes before Guaranteed ones when the node runs short, the more so the further its usage is above its requests\n", aurora.Red("✖").String(), what)
+		retval += fmt.Sprintf("%s  raise its memory requests to what it actually uses, or set requests equal to limits to make it Guaranteed\n", aurora.Yellow("…").String())
+	}
+
+	return retval, nil
+}
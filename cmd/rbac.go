@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// the API server explains a denial as e.g. `User "jane" cannot list resource "events" in API
+// group "" in the namespace "default"`
+var forbiddenRegexp = regexp.MustCompile(`cannot (\S+) resource "([^"]+)"(?: in API group "([^"]*)")?`)
+
+// describeForbidden returns a short note on the permission that is missing, e.g. "permission
+// denied for list events", or "" if the error isn't an RBAC denial.
+func describeForbidden(err error) string {
+	if err == nil || !apierrors.IsForbidden(err) {
+		return ""
+	}
+
+	m := forbiddenRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return fmt.Sprintf("permission denied: %s", err.Error())
+	}
+
+	resource := m[2]
+	if m[3] != "" {
+		resource = fmt.Sprintf("%s.%s", m[2], m[3])
+	}
+	return fmt.Sprintf("permission denied for %s %s", m[1], resource)
+}
+
+// printSection prints a section of the report.  If the user isn't allowed to make one of the
+// requests the section needs, a note saying which permission is missing is printed in its place
+// (once per pod, since several sections often need the same one) rather than failing the whole
+// inspection; any other error is returned.
+func (dp *podInspectCommand) printSection(section string, err error) error {
+	if note := describeForbidden(err); note != "" {
+		if !dp.deniedNotes[note] {
+			dp.deniedNotes[note] = true
+			fmt.Fprintf(dp.out, "\n%s  %s\n", aurora.Yellow("…").String(), aurora.Yellow(note))
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if section != "" {
+		fmt.Fprintf(dp.out, "\n%s", section)
+	}
+
+	return nil
+}
@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// registry requests are best effort; a registry that isn't reachable from where the plugin runs
+// (air-gapped clusters, private networks) shouldn't hold up the rest of the report
+const registryTimeout = 5 * time.Second
+
+// manifests larger than this aren't manifests
+const maxManifestBytes = 4 << 20
+
+const (
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestV1   = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// the grammar of the parts of an image reference, from the distribution spec
+var (
+	repositoryComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	tagRegexp                 = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegexp              = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// imageReference is a parsed image name: the registry host to talk to, the repository in that
+// registry, and the tag or digest.
+type imageReference struct {
+	Registry   string
+	Repository string
+	Reference  string
+}
+
+func (r imageReference) String() string {
+	sep := ":"
+	if strings.HasPrefix(r.Reference, "sha256:") {
+		sep = "@"
+	}
+	return fmt.Sprintf("%s/%s%s%s", r.Registry, r.Repository, sep, r.Reference)
+}
+
+// parseImageReference parses an image as it appears in a container spec, or a container status
+// imageID (which may carry a docker-pullable:// prefix), applying the same defaults as the
+// container runtime: Docker Hub, the library/ namespace, and the latest tag.
+func parseImageReference(image string) (imageReference, error) {
+	for _, prefix := range []string{"docker-pullable://", "docker://"} {
+		image = strings.TrimPrefix(image, prefix)
+	}
+	if image == "" || strings.Contains(image, "://") {
+		return imageReference{}, fmt.Errorf("can't parse image reference '%s'", image)
+	}
+
+	ref := imageReference{Registry: "docker.io"}
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Reference = name[i+1:]
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		if ref.Reference == "" {
+			ref.Reference = name[i+1:]
+		}
+		name = name[:i]
+	}
+	if ref.Reference == "" {
+		ref.Reference = "latest"
+	}
+
+	// the first component is a registry host if it looks like one
+	if i := strings.Index(name, "/"); i >= 0 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry = host
+			name = name[i+1:]
+		}
+	}
+
+	if ref.Registry == "docker.io" || ref.Registry == "index.docker.io" {
+		ref.Registry = "registry-1.docker.io"
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+	ref.Repository = name
+
+	return ref, nil
+}
+
+// validateImageReference checks a parsed image reference against the grammar the runtime holds
+// it to, which parseImageReference is more forgiving about, and says what is wrong with it.
+func validateImageReference(ref imageReference) error {
+	for _, component := range strings.Split(ref.Repository, "/") {
+		if !repositoryComponentRegexp.MatchString(component) {
+			if strings.ToLower(component) != component {
+				return fmt.Errorf("repository '%s' has uppercase letters; repositories must be lowercase", ref.Repository)
+			}
+			return fmt.Errorf("repository '%s' isn't valid: '%s' must be lowercase letters and digits, separated by '.', '_', '__' or '-'", ref.Repository, component)
+		}
+	}
+	if strings.Contains(ref.Reference, ":") {
+		if !digestRegexp.MatchString(ref.Reference) {
+			return fmt.Errorf("digest '%s' isn't valid; it must be sha256: followed by 64 lowercase hex digits", ref.Reference)
+		}
+	} else if !tagRegexp.MatchString(ref.Reference) {
+		return fmt.Errorf("tag '%s' isn't valid; a tag is up to 128 letters, digits, '_', '.' and '-', and can't start with '.' or '-'", ref.Reference)
+	}
+	return nil
+}
+
+// registryError is an error response from a registry.
+type registryError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *registryError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("registry returned %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("registry returned %s: %s", http.StatusText(e.StatusCode), e.Message)
+}
+
+// registryClient is just enough of a Docker Registry HTTP API v2 client to read manifests,
+// including the token dance that most registries require even for anonymous pulls.
+type registryClient struct {
+	client *http.Client
+	// username and password to authenticate with, if any
+	username string
+	password string
+	// bearer tokens by registry and scope
+	tokens map[string]string
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{
+		client: &http.Client{Timeout: registryTimeout},
+		tokens: map[string]string{},
+	}
+}
+
+func registryBaseURL(host string) string {
+	// local registries (kind, minikube) are almost never served over TLS
+	if host == "localhost" || strings.HasPrefix(host, "localhost:") || host == "127.0.0.1" || strings.HasPrefix(host, "127.0.0.1:") {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// get makes an authenticated GET request for a path in the repository, answering an
+// authentication challenge once.
+func (rc *registryClient) get(ctx context.Context, ref imageReference, path string, accept []string) (*http.Response, error) {
+	u := fmt.Sprintf("%s/v2/%s/%s", registryBaseURL(ref.Registry), ref.Repository, path)
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+	tokenKey := ref.Registry + " " + scope
+
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range accept {
+			req.Header.Add("Accept", a)
+		}
+		if token, ok := rc.tokens[tokenKey]; ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if rc.username != "" {
+			req.SetBasicAuth(rc.username, rc.password)
+		}
+		return rc.client.Do(req)
+	}
+
+	resp, err := do()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if _, ok := rc.tokens[tokenKey]; ok || !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, &registryError{StatusCode: http.StatusUnauthorized}
+	}
+
+	token, err := rc.getToken(ctx, challenge, scope)
+	if err != nil {
+		return nil, err
+	}
+	rc.tokens[tokenKey] = token
+
+	return do()
+}
+
+// errInsecureTokenRealm is returned when the registry wants credentials sent to a token service
+// that isn't served over HTTPS.
+var errInsecureTokenRealm = errors.New("not sending the pull secret's credentials to a token realm that isn't HTTPS")
+
+// getToken answers a bearer challenge by fetching a token from the registry's token service.
+func (rc *registryClient) getToken(ctx context.Context, challenge, scope string) (string, error) {
+	params := map[string]string{}
+	for _, m := range challengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("registry sent an authentication challenge without a realm")
+	}
+
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+	query.Set("scope", scope)
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", fmt.Errorf("registry sent an invalid token realm %s: %v", params["realm"], err)
+	}
+	// the realm can be any URL the registry names; credentials only go to it over TLS
+	if rc.username != "" && realm.Scheme != "https" {
+		return "", fmt.Errorf("%w: %s", errInsecureTokenRealm, params["realm"])
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if rc.username != "" {
+		req.SetBasicAuth(rc.username, rc.password)
+	}
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", readRegistryError(resp)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// readRegistryError turns an error response into a registryError, with the message from the
+// registry's error body if it sent one.
+func readRegistryError(resp *http.Response) error {
+	rerr := &registryError{StatusCode: resp.StatusCode}
+
+	var body struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+		Details string `json:"details"`
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestBytes))
+	if json.Unmarshal(data, &body) == nil {
+		if len(body.Errors) > 0 {
+			rerr.Message = body.Errors[0].Message
+		} else {
+			rerr.Message = body.Details
+		}
+	}
+
+	return rerr
+}
+
+// getManifest fetches a manifest or manifest list, returning its media type and body.
+func (rc *registryClient) getManifest(ctx context.Context, ref imageReference) (string, []byte, error) {
+	accept := []string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest, mediaTypeDockerManifestV1}
+	resp, err := rc.get(ctx, ref, "manifests/"+ref.Reference, accept)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, readRegistryError(resp)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestBytes))
+	if err != nil {
+		return "", nil, err
+	}
+
+	mediaType := resp.Header.Get("Content-Type")
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+
+	return strings.TrimSpace(mediaType), data, nil
+}
+
+// hasManifest reports whether the repository has a manifest for the reference, tag or digest.
+func (rc *registryClient) hasManifest(ctx context.Context, ref imageReference) (bool, error) {
+	_, _, err := rc.getManifest(ctx, ref)
+	if rerr, ok := err.(*registryError); ok && rerr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// getBlob fetches a (small) blob, such as an image config.
+func (rc *registryClient) getBlob(ctx context.Context, ref imageReference, digest string) ([]byte, error) {
+	resp, err := rc.get(ctx, ref, "blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readRegistryError(resp)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestBytes))
+}
+
+// imagePlatform is an OS and architecture an image is built for.
+type imagePlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p imagePlatform) String() string {
+	s := fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// getImagePlatforms returns the platforms an image is available for: every platform in its
+// manifest list, or for a single-platform image, the platform in its config.
+func (rc *registryClient) getImagePlatforms(ctx context.Context, ref imageReference) ([]imagePlatform, error) {
+	mediaType, data, err := rc.getManifest(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Manifests []struct {
+			Platform *imagePlatform `json:"platform"`
+		} `json:"manifests"`
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		// schema 1 manifests carry the architecture directly
+		Architecture string `json:"architecture"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("can't decode manifest for %s: %v", ref, err)
+	}
+	if mediaType == "" || mediaType == "application/json" {
+		mediaType = manifest.MediaType
+	}
+
+	switch {
+	case mediaType == mediaTypeDockerManifestList || mediaType == mediaTypeOCIIndex || len(manifest.Manifests) > 0:
+		platforms := []imagePlatform{}
+		for _, m := range manifest.Manifests {
+			// buildkit puts attestations in the index as unknown/unknown
+			if m.Platform == nil || m.Platform.Architecture == "unknown" {
+				continue
+			}
+			platforms = append(platforms, *m.Platform)
+		}
+		return platforms, nil
+	case manifest.Config.Digest != "":
+		config, err := rc.getBlob(ctx, ref, manifest.Config.Digest)
+		if err != nil {
+			return nil, err
+		}
+		var platform imagePlatform
+		if err := json.Unmarshal(config, &platform); err != nil {
+			return nil, fmt.Errorf("can't decode image config for %s: %v", ref, err)
+		}
+		return []imagePlatform{platform}, nil
+	case manifest.Architecture != "":
+		return []imagePlatform{{OS: "linux", Architecture: manifest.Architecture}}, nil
+	}
+
+	return nil, fmt.Errorf("unrecognized manifest type '%s' for %s", mediaType, ref)
+}
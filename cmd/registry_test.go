@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image   string
+		want    imageReference
+		wantErr bool
+	}{
+		{image: "nginx", want: imageReference{"registry-1.docker.io", "library/nginx", "latest"}},
+		{image: "nginx:1.19", want: imageReference{"registry-1.docker.io", "library/nginx", "1.19"}},
+		{image: "bitnami/redis:6.0", want: imageReference{"registry-1.docker.io", "bitnami/redis", "6.0"}},
+		{image: "docker.io/nginx", want: imageReference{"registry-1.docker.io", "library/nginx", "latest"}},
+		{image: "index.docker.io/library/nginx:1.19", want: imageReference{"registry-1.docker.io", "library/nginx", "1.19"}},
+		{image: "quay.io/prometheus/node-exporter:v1.0.1", want: imageReference{"quay.io", "prometheus/node-exporter", "v1.0.1"}},
+		{image: "localhost/app", want: imageReference{"localhost", "app", "latest"}},
+		{image: "registry.local:5000/team/app:2", want: imageReference{"registry.local:5000", "team/app", "2"}},
+		{image: "registry.local:5000/app", want: imageReference{"registry.local:5000", "app", "latest"}},
+		{image: "gcr.io/project/app@" + testDigest, want: imageReference{"gcr.io", "project/app", testDigest}},
+		{image: "gcr.io/project/app:1.0@" + testDigest, want: imageReference{"gcr.io", "project/app", testDigest}},
+		{image: "docker-pullable://nginx@" + testDigest, want: imageReference{"registry-1.docker.io", "library/nginx", testDigest}},
+		{image: "docker://quay.io/app:1", want: imageReference{"quay.io", "app", "1"}},
+		{image: "", wantErr: true},
+		{image: "docker-pullable://", wantErr: true},
+		{image: "http://example.com/app", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := parseImageReference(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImageReference(%q) error = %v, wantErr %v", tt.image, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parseImageReference(%q) = %+v, want %+v", tt.image, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageReferenceString(t *testing.T) {
+	tests := []struct {
+		ref  imageReference
+		want string
+	}{
+		{imageReference{"quay.io", "app", "1"}, "quay.io/app:1"},
+		{imageReference{"gcr.io", "project/app", testDigest}, "gcr.io/project/app@" + testDigest},
+	}
+	for _, tt := range tests {
+		if got := tt.ref.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestValidateImageReference(t *testing.T) {
+	tests := []struct {
+		image   string
+		wantErr string
+	}{
+		{image: "nginx:1.19"},
+		{image: "quay.io/prometheus/node-exporter:v1.0.1"},
+		{image: "registry.local:5000/my_team/app__x:latest"},
+		{image: "gcr.io/project/app@" + testDigest},
+		{image: "MyApp:1", wantErr: "uppercase"},
+		{image: "team/app-:1", wantErr: "isn't valid: 'app-'"},
+		{image: "team//app:1", wantErr: "isn't valid: ''"},
+		{image: "app:.hidden", wantErr: "tag '.hidden' isn't valid"},
+		{image: "app:" + strings.Repeat("t", 129), wantErr: "isn't valid"},
+		{image: "app@sha256:ABC", wantErr: "digest 'sha256:ABC' isn't valid"},
+		{image: "app@md5:0123456789abcdef", wantErr: "digest 'md5:0123456789abcdef' isn't valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := parseImageReference(tt.image)
+			if err != nil {
+				t.Fatalf("parseImageReference(%q) error = %v", tt.image, err)
+			}
+			err = validateImageReference(ref)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateImageReference(%+v) error = %v", ref, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateImageReference(%+v) error = %v, want one containing %q", ref, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryBaseURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"localhost", "http://localhost"},
+		{"localhost:5000", "http://localhost:5000"},
+		{"127.0.0.1", "http://127.0.0.1"},
+		{"127.0.0.1:5000", "http://127.0.0.1:5000"},
+		{"127.0.0.1.attacker.example", "https://127.0.0.1.attacker.example"},
+		{"localhost.attacker.example", "https://localhost.attacker.example"},
+		{"quay.io", "https://quay.io"},
+	}
+	for _, tt := range tests {
+		if got := registryBaseURL(tt.host); got != tt.want {
+			t.Errorf("registryBaseURL(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+)
+
+// the structured output formats supported by --output
+var reportOutputFormats = []string{"json", "yaml"}
+
+// the output format that shows drift and --compare results as unified diffs instead of a report
+const diffOutputFormat = "diff"
+
+// the output formats that render the report through a template given with the format, as in
+// -o go-template={{...}}
+var reportTemplateFormats = []string{"go-template", "jsonpath"}
+
+var containerStateNames = map[string]string{
+	"R": "running",
+	"T": "terminated",
+	"W": "waiting",
+}
+
+var containerStatuses = map[int]report.Status{
+	PODINSPECT_STATUS_OK:      report.StatusOK,
+	PODINSPECT_STATUS_WAITING: report.StatusWaiting,
+	PODINSPECT_STATUS_FAILED:  report.StatusFailed,
+	PODINSPECT_STATUS_UNKNOWN: report.StatusUnknown,
+}
+
+// parseOutputFormat splits an --output value into the format and, for the template formats, the
+// template.
+func parseOutputFormat(output string) (string, string) {
+	parts := strings.SplitN(output, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func validateOutputFormat(output string) error {
+	if output == "" {
+		return nil
+	}
+
+	if output == diffOutputFormat {
+		return nil
+	}
+
+	format, text := parseOutputFormat(output)
+	for _, f := range reportOutputFormats {
+		if format == f && text == "" {
+			return nil
+		}
+	}
+	for _, f := range reportTemplateFormats {
+		if format == f {
+			if text == "" {
+				return fmt.Errorf("output format %s needs a template, e.g. -o %s=...", format, format)
+			}
+			// catch template syntax errors before any requests are made
+			_, err := newReportTemplate(format, text)
+			return err
+		}
+	}
+
+	formats := append(append(append([]string{}, reportOutputFormats...), reportTemplateFormats...), diffOutputFormat)
+	return fmt.Errorf("unsupported output format '%s'; must be one of: %s", output, strings.Join(formats, ", "))
+}
+
+// reportTemplate renders a report, decoded from its JSON form, through a template.
+type reportTemplate func(w io.Writer, data interface{}) error
+
+// newReportTemplate parses the template for a template output format.  Both kinds of template
+// are executed against the report's JSON form, so they address fields by their JSON names
+// ({.pods[0].containers[*].restartCount}), as with kubectl's own -o go-template and -o jsonpath.
+func newReportTemplate(format, text string) (reportTemplate, error) {
+	switch format {
+	case "go-template":
+		t, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing go-template: %v", err)
+		}
+		return t.Execute, nil
+	case "jsonpath":
+		// like kubectl, accept expressions without the surrounding braces or the leading dot
+		if !strings.HasPrefix(text, "{") {
+			if !strings.HasPrefix(text, ".") {
+				text = "." + text
+			}
+			text = "{" + text + "}"
+		}
+		j := jsonpath.New("output").AllowMissingKeys(true)
+		if err := j.Parse(text); err != nil {
+			return nil, fmt.Errorf("error parsing jsonpath %s: %v", text, err)
+		}
+		return j.Execute, nil
+	}
+	return nil, fmt.Errorf("unsupported template format '%s'", format)
+}
+
+// printReport inspects the given pods and writes the results as a single structured report.
+// If strict is set, a failure to inspect any pod is returned as an error; otherwise the pod is
+// left out of the report, just as it is left out of a human-readable sweep.
+func (dp *podInspectCommand) printReport(pods []types.NamespacedName, strict bool) error {
+	r := report.New()
+	summary := newScanSummary()
+
+	for _, p := range pods {
+		dp.namespace = p.Namespace
+		pod, _, err := dp.getPod(p.Name)
+		if err != nil {
+			if strict {
+				return err
+			}
+			summary.addError(p, err)
+			continue
+		}
+
+		podReport, err := dp.buildPodReport(pod)
+		if err != nil {
+			if strict {
+				return err
+			}
+			summary.addError(p, err)
+			continue
+		}
+
+		r.Pods = append(r.Pods, *podReport)
+		summary.addPod(dp, pod, podReport.Verdict)
+	}
+
+	if !strict {
+		r.Summary = summary.finish()
+		if err := dp.recordScan(summary); err != nil {
+			return err
+		}
+	}
+
+	return dp.writeReport(r)
+}
+
+func (dp *podInspectCommand) writeReport(r *report.Report) error {
+	format, text := parseOutputFormat(dp.output)
+	if text != "" {
+		if err := dp.writeTemplatedReport(r, format, text); err != nil {
+			return err
+		}
+		return dp.writeSinks(r)
+	}
+
+	data, err := encodeReport(r, format)
+	if err != nil {
+		return err
+	}
+	if _, err := dp.out.Write(data); err != nil {
+		return err
+	}
+	return dp.writeSinks(r)
+}
+
+// encodeReport renders the report as json or yaml.
+func encodeReport(r *report.Report, format string) ([]byte, error) {
+	if format == "yaml" {
+		return yaml.Marshal(r)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func (dp *podInspectCommand) writeTemplatedReport(r *report.Report, format, text string) error {
+	t, err := newReportTemplate(format, text)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	return t(dp.out, obj)
+}
+
+// getPodVerdict derives the overall verdict on a pod from its phase and the classification of
+// its containers.
+func getPodVerdict(pod *v1.Pod, containers []report.Container) report.Verdict {
+	switch pod.Status.Phase {
+	case v1.PodSucceeded:
+		return report.VerdictHealthy
+	case v1.PodFailed:
+		return report.VerdictFailed
+	case v1.PodUnknown:
+		return report.VerdictUnknown
+	}
+
+	failed, unknown, ready, regular := 0, 0, 0, 0
+	for _, c := range containers {
+		switch c.Status {
+		case report.StatusFailed:
+			failed++
+		case report.StatusUnknown:
+			unknown++
+		}
+		if c.Type == report.ContainerTypeRegular {
+			regular++
+			if c.Ready {
+				ready++
+			}
+		}
+	}
+
+	switch {
+	case pod.Status.Phase == v1.PodPending && failed == 0:
+		return report.VerdictPending
+	case failed > 0 && ready == 0:
+		return report.VerdictFailed
+	case failed > 0:
+		return report.VerdictDegraded
+	case ready == regular:
+		return report.VerdictHealthy
+	case unknown > 0:
+		return report.VerdictUnknown
+	case ready == 0:
+		// running, but nothing is ready yet, and nothing has failed
+		return report.VerdictPending
+	}
+	return report.VerdictDegraded
+}
+
+// getPodStatusVerdict derives the verdict on a pod from its status alone, as buildPodReport
+// would, for when the pod is displayed rather than reported on.
+func getPodStatusVerdict(pod *v1.Pod) report.Verdict {
+	podReport := &report.Pod{}
+	for _, containers := range []struct {
+		containers    []v1.Container
+		statuses      []v1.ContainerStatus
+		containerType report.ContainerType
+	}{
+		{pod.Spec.InitContainers, pod.Status.InitContainerStatuses, report.ContainerTypeInit},
+		{pod.Spec.Containers, pod.Status.ContainerStatuses, report.ContainerTypeRegular},
+	} {
+		statuses := map[string]v1.ContainerStatus{}
+		for _, cs := range containers.statuses {
+			statuses[cs.Name] = cs
+		}
+		for _, c := range containers.containers {
+			addContainerReport(podReport, c, statuses, containers.containerType, false)
+		}
+	}
+	return getPodVerdict(pod, podReport.Containers)
+}
+
+func (dp *podInspectCommand) buildPodReport(pod *v1.Pod) (*report.Pod, error) {
+	podReport := &report.Pod{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		UID:        string(pod.UID),
+		Created:    pod.CreationTimestamp,
+		Node:       pod.Spec.NodeName,
+		Phase:      string(pod.Status.Phase),
+		Reason:     pod.Status.Reason,
+		Message:    pod.Status.Message,
+		Static:     isStaticPod(pod.ObjectMeta),
+		QOSClass:   string(getPodQOSClass(pod)),
+		Containers: []report.Container{},
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if note := describeForbidden(err); note != "" {
+		podReport.Warnings = append(podReport.Warnings, note)
+	} else if err != nil {
+		return nil, err
+	}
+	if node != nil {
+		podReport.Zone, podReport.Region = getNodeTopology(node)
+	}
+
+	initStatuses := map[string]v1.ContainerStatus{}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		initStatuses[cs.Name] = cs
+	}
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+
+	logRequests := []logRequest{}
+	for _, c := range pod.Spec.InitContainers {
+		if req := addContainerReport(podReport, c, initStatuses, report.ContainerTypeInit, dp.allLogs); req != nil {
+			logRequests = append(logRequests, *req)
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if req := addContainerReport(podReport, c, statuses, report.ContainerTypeRegular, dp.allLogs); req != nil {
+			logRequests = append(logRequests, *req)
+		}
+	}
+
+	podReport.Verdict = getPodVerdict(pod, podReport.Containers)
+
+	for _, condition := range pod.Status.Conditions {
+		podReport.Conditions = append(podReport.Conditions, report.Condition{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime,
+		})
+	}
+
+	events, _, err := dp.getPodEventList(pod)
+	if note := describeForbidden(err); note != "" {
+		podReport.Warnings = append(podReport.Warnings, note)
+	} else if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		podReport.Events = append(podReport.Events, report.Event{
+			Type:      event.Type,
+			Container: getEventContainer(event, pod),
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+			LastSeen:  getEventTimestamp(event),
+		})
+	}
+
+	// the logs the human-readable report has already fetched, for --also-output
+	podLogs, ok := dp.capturedLogs[pod.UID]
+	if !ok {
+		podLogs, err = dp.getContainerLogsParallel(pod.Name, logRequests)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, logs := range podLogs {
+		containerLogs := report.ContainerLogs{
+			Container:    logs.ContainerName,
+			Previous:     logs.Previous,
+			Lines:        []string{},
+			OmittedLines: logs.Dropped,
+			TimedOut:     logs.TimedOut,
+			Error:        logs.Denied,
+		}
+		if logs.Error != "" {
+			containerLogs.Error = logs.Error
+		}
+		if logs.Logs != "" {
+			containerLogs.Lines = strings.Split(strings.TrimSuffix(logs.Logs, "\n"), "\n")
+		}
+		podReport.Logs = append(podReport.Logs, containerLogs)
+	}
+
+	return podReport, nil
+}
+
+// addContainerReport appends the report for a single container.  If the container isn't ok, it
+// returns the request for the container's logs.
+func addContainerReport(podReport *report.Pod, c v1.Container, statuses map[string]v1.ContainerStatus, containerType report.ContainerType, allLogs bool) *logRequest {
+	containerReport := report.Container{
+		Type:   containerType,
+		Name:   c.Name,
+		Image:  c.Image,
+		State:  "unknown",
+		Status: report.StatusUnknown,
+	}
+
+	cs, ok := statuses[c.Name]
+	if !ok {
+		podReport.Containers = append(podReport.Containers, containerReport)
+		return nil
+	}
+
+	stateCode, reason, message, podInspectStatus := classifyContainerState(cs)
+	if name, ok := containerStateNames[stateCode]; ok {
+		containerReport.State = name
+	}
+	containerReport.Reason = reason
+	containerReport.Message = message
+	containerReport.Status = containerStatuses[podInspectStatus]
+	containerReport.Ready = cs.Ready
+	containerReport.RestartCount = cs.RestartCount
+
+	if cs.State.Terminated != nil {
+		exitCode := cs.State.Terminated.ExitCode
+		containerReport.ExitCode = &exitCode
+		if signal, _ := getTerminationSignal(cs.State.Terminated); signal != 0 {
+			containerReport.SignalName = getSignalName(signal)
+		}
+	}
+
+	if lts := cs.LastTerminationState.Terminated; lts != nil {
+		containerReport.LastTermination = &report.Termination{
+			Reason:     lts.Reason,
+			ExitCode:   lts.ExitCode,
+			Signal:     lts.Signal,
+			Message:    sanitizeText(lts.Message),
+			StartedAt:  lts.StartedAt,
+			FinishedAt: lts.FinishedAt,
+		}
+		if signal, _ := getTerminationSignal(lts); signal != 0 {
+			containerReport.LastTermination.SignalName = getSignalName(signal)
+		}
+	}
+
+	podReport.Containers = append(podReport.Containers, containerReport)
+
+	if podInspectStatus == PODINSPECT_STATUS_OK && !allLogs {
+		return nil
+	}
+
+	return &logRequest{Status: cs, Init: containerType == report.ContainerTypeInit}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+)
+
+func TestGetPodVerdict(t *testing.T) {
+	regular := func(status report.Status, ready bool) report.Container {
+		return report.Container{Type: report.ContainerTypeRegular, Status: status, Ready: ready}
+	}
+	initContainer := func(status report.Status) report.Container {
+		return report.Container{Type: report.ContainerTypeInit, Status: status}
+	}
+
+	tests := []struct {
+		name       string
+		phase      v1.PodPhase
+		containers []report.Container
+		want       report.Verdict
+	}{
+		{"succeeded", v1.PodSucceeded, []report.Container{regular(report.StatusFailed, false)}, report.VerdictHealthy},
+		{"failed", v1.PodFailed, []report.Container{regular(report.StatusOK, true)}, report.VerdictFailed},
+		{"unknown phase", v1.PodUnknown, nil, report.VerdictUnknown},
+		{"pending", v1.PodPending, []report.Container{regular(report.StatusWaiting, false)}, report.VerdictPending},
+		{"pending with a failed init container", v1.PodPending, []report.Container{initContainer(report.StatusFailed), regular(report.StatusWaiting, false)}, report.VerdictFailed},
+		{"all ready", v1.PodRunning, []report.Container{initContainer(report.StatusOK), regular(report.StatusOK, true), regular(report.StatusOK, true)}, report.VerdictHealthy},
+		{"failed and none ready", v1.PodRunning, []report.Container{regular(report.StatusFailed, false)}, report.VerdictFailed},
+		{"failed but some ready", v1.PodRunning, []report.Container{regular(report.StatusFailed, false), regular(report.StatusOK, true)}, report.VerdictDegraded},
+		{"unknown container", v1.PodRunning, []report.Container{regular(report.StatusUnknown, false), regular(report.StatusOK, true)}, report.VerdictUnknown},
+		{"running, nothing ready yet", v1.PodRunning, []report.Container{regular(report.StatusOK, false)}, report.VerdictPending},
+		{"running, some not ready", v1.PodRunning, []report.Container{regular(report.StatusOK, false), regular(report.StatusOK, true)}, report.VerdictDegraded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{Status: v1.PodStatus{Phase: tt.phase}}
+			if got := getPodVerdict(pod, tt.containers); got != tt.want {
+				t.Errorf("getPodVerdict() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// podResizeStatus holds the in-place pod resize fields (InPlacePodVerticalScaling) that are newer
+// than the API types we build against, so they are decoded straight from the raw pod JSON.
+type podResizeStatus struct {
+	Status struct {
+		Resize            string `json:"resize"`
+		ContainerStatuses []struct {
+			Name               string                   `json:"name"`
+			AllocatedResources v1.ResourceList          `json:"allocatedResources"`
+			Resources          *v1.ResourceRequirements `json:"resources"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// pod condition types that newer clusters use to report resize progress in place of status.resize
+const (
+	podResizePending    = "PodResizePending"
+	podResizeInProgress = "PodResizeInProgress"
+)
+
+func (dp *podInspectCommand) getResizeStatus(pod *v1.Pod, rawPod []byte) (string, error) {
+	retval := ""
+
+	resize := podResizeStatus{}
+	if err := json.Unmarshal(rawPod, &resize); err != nil {
+		return "", err
+	}
+
+	podResize := resize.Status.Resize
+	for _, condition := range pod.Status.Conditions {
+		if condition.Status != v1.ConditionTrue {
+			continue
+		}
+		switch string(condition.Type) {
+		case podResizePending:
+			podResize = fmt.Sprintf("Pending (%s)", condition.Reason)
+		case podResizeInProgress:
+			podResize = "InProgress"
+		}
+	}
+
+	desired := map[string]v1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		desired[c.Name] = c.Resources.Requests
+	}
+
+	// on clusters with InPlacePodVerticalScaling every container reports what's allocated and what
+	// it actually has, so only show the section while a resize hasn't been carried out yet
+	resizing := podResize != ""
+	for _, cs := range resize.Status.ContainerStatuses {
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			desiredQuantity := desired[cs.Name][name]
+			allocatedQuantity, hasAllocated := cs.AllocatedResources[name]
+			if hasAllocated && !desiredQuantity.Equal(allocatedQuantity) {
+				resizing = true
+			}
+			if cs.Resources != nil {
+				if actualQuantity, hasActual := cs.Resources.Requests[name]; hasActual && !allocatedQuantity.Equal(actualQuantity) {
+					resizing = true
+				}
+			}
+		}
+	}
+	if !resizing {
+		return "", nil
+	}
+
+	if podResize != "" {
+		retval += aurora.Cyan(fmt.Sprintf("Resize (%s):\n\n", podResize)).String()
+	} else {
+		retval += aurora.Cyan("Resize:\n\n").String()
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Resource").String(),
+		aurora.Yellow("Desired").String(),
+		aurora.Yellow("Allocated").String(),
+		aurora.Yellow("Actual").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	for _, cs := range resize.Status.ContainerStatuses {
+		actual := v1.ResourceList{}
+		if cs.Resources != nil {
+			actual = cs.Resources.Requests
+		}
+
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			desiredQuantity, hasDesired := desired[cs.Name][name]
+			allocatedQuantity, hasAllocated := cs.AllocatedResources[name]
+			actualQuantity, hasActual := actual[name]
+			if !hasDesired && !hasAllocated && !hasActual {
+				continue
+			}
+
+			status := aurora.Green("✔").String()
+			if hasAllocated && !desiredQuantity.Equal(allocatedQuantity) {
+				status = aurora.Yellow("… resize pending").String()
+			} else if hasActual && !allocatedQuantity.Equal(actualQuantity) {
+				status = aurora.Yellow("… resize in progress").String()
+			}
+
+			tw.Append([]string{
+				cs.Name,
+				string(name),
+				formatOptionalQuantity(desiredQuantity, hasDesired),
+				formatOptionalQuantity(allocatedQuantity, hasAllocated),
+				formatOptionalQuantity(actualQuantity, hasActual),
+				status,
+			})
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+func formatOptionalQuantity(q resource.Quantity, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return q.String()
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetResizeStatus(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{
+		Name: "app",
+		Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		}},
+	}}}}
+
+	tests := []struct {
+		name       string
+		conditions []v1.PodCondition
+		rawPod     string
+		want       []string
+	}{
+		{
+			name:   "no resize fields",
+			rawPod: `{"status": {"containerStatuses": [{"name": "app"}]}}`,
+		},
+		{
+			name:   "resized",
+			rawPod: `{"status": {"containerStatuses": [{"name": "app", "allocatedResources": {"cpu": "500m", "memory": "256Mi"}, "resources": {"requests": {"cpu": "0.5", "memory": "256Mi"}}}]}}`,
+		},
+		{
+			name:   "pending",
+			rawPod: `{"status": {"resize": "Proposed", "containerStatuses": [{"name": "app", "allocatedResources": {"cpu": "250m", "memory": "256Mi"}, "resources": {"requests": {"cpu": "250m", "memory": "256Mi"}}}]}}`,
+			want:   []string{"Resize (Proposed):", "250m", "resize pending"},
+		},
+		{
+			name:   "in progress",
+			rawPod: `{"status": {"containerStatuses": [{"name": "app", "allocatedResources": {"cpu": "500m", "memory": "256Mi"}, "resources": {"requests": {"cpu": "250m", "memory": "256Mi"}}}]}}`,
+			want:   []string{"Resize:", "resize in progress"},
+		},
+		{
+			name:       "pending condition",
+			conditions: []v1.PodCondition{{Type: podResizePending, Status: v1.ConditionTrue, Reason: "Infeasible"}},
+			rawPod:     `{"status": {"containerStatuses": [{"name": "app", "allocatedResources": {"cpu": "500m", "memory": "256Mi"}}]}}`,
+			want:       []string{"Resize (Pending (Infeasible)):"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pod.DeepCopy()
+			p.Status.Conditions = tt.conditions
+			got, err := (&podInspectCommand{}).getResizeStatus(p, []byte(tt.rawPod))
+			if err != nil {
+				t.Fatalf("getResizeStatus() error = %v", err)
+			}
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("getResizeStatus() = %q, want no section", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("getResizeStatus() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
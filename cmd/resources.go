@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// getPodEffectiveRequests returns the resources the scheduler reserves for the pod: for each
+// resource, the larger of the sum over the regular containers and the largest single init
+// container, plus the pod overhead.
+func getPodEffectiveRequests(pod *v1.Pod) v1.ResourceList {
+	return sumPodResources(pod, func(c v1.Container) v1.ResourceList { return c.Resources.Requests })
+}
+
+// getPodEffectiveLimits returns the pod's limits, combined the same way as its requests.  The
+// boolean results say, per resource, whether some container has no limit at all, which makes the
+// pod's limit unbounded.
+func getPodEffectiveLimits(pod *v1.Pod) (v1.ResourceList, map[v1.ResourceName]bool) {
+	limits := sumPodResources(pod, func(c v1.Container) v1.ResourceList { return c.Resources.Limits })
+
+	unbounded := map[v1.ResourceName]bool{}
+	for _, c := range pod.Spec.Containers {
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			if _, ok := c.Resources.Limits[name]; !ok {
+				unbounded[name] = true
+			}
+		}
+	}
+
+	return limits, unbounded
+}
+
+func sumPodResources(pod *v1.Pod, get func(c v1.Container) v1.ResourceList) v1.ResourceList {
+	resources := v1.ResourceList{}
+
+	for _, c := range pod.Spec.Containers {
+		for name, q := range get(c) {
+			total := resources[name]
+			total.Add(q)
+			resources[name] = total
+		}
+	}
+
+	for _, c := range pod.Spec.InitContainers {
+		for name, q := range get(c) {
+			if total, ok := resources[name]; !ok || q.Cmp(total) > 0 {
+				resources[name] = q
+			}
+		}
+	}
+
+	for name, q := range pod.Spec.Overhead {
+		total := resources[name]
+		total.Add(q)
+		resources[name] = total
+	}
+
+	return resources
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// the restart history covers this long, in buckets of equal width
+const restartHistoryWindow = 6 * time.Hour
+const restartHistoryBuckets = 24
+
+// sparkline levels, lowest to highest; a bucket without restarts is left blank
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// getContainerStarts estimates when a container was (re)started, from its Started events.  The
+// event recorder folds repeats into one event with a count, first timestamp, and last timestamp,
+// so the starts in between are spread evenly over that span.
+func getContainerStarts(events []v1.Event, cs v1.ContainerStatus) []time.Time {
+	starts := []time.Time{}
+	for _, event := range events {
+		if event.Reason != "Started" {
+			continue
+		}
+
+		last := getEventTimestamp(event).Time
+		first := event.FirstTimestamp.Time
+		count := int(event.Count)
+		if first.IsZero() || count <= 1 {
+			starts = append(starts, last)
+			continue
+		}
+
+		step := last.Sub(first) / time.Duration(count-1)
+		for i := 0; i < count; i++ {
+			starts = append(starts, first.Add(time.Duration(i)*step))
+		}
+	}
+
+	// the container's current run is the one thing we know the start of exactly
+	if cs.State.Running != nil && len(starts) == 0 {
+		starts = append(starts, cs.State.Running.StartedAt.Time)
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	return starts
+}
+
+// bucketRestarts counts the restarts in each bucket of the window that ends now.
+func bucketRestarts(restarts []time.Time, now time.Time) []int {
+	buckets := make([]int, restartHistoryBuckets)
+	for _, t := range restarts {
+		if b, ok := getRestartBucket(t, now); ok {
+			buckets[b]++
+		}
+	}
+	return buckets
+}
+
+// renderSparkline draws the bucket counts as a row of block characters, scaled to the busiest
+// bucket.
+func renderSparkline(buckets []int) string {
+	max := 0
+	for _, n := range buckets {
+		if n > max {
+			max = n
+		}
+	}
+
+	sb := &strings.Builder{}
+	for _, n := range buckets {
+		if n == 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		level := (n*len(sparklineLevels) - 1) / max
+		sb.WriteRune(sparklineLevels[level])
+	}
+	return sb.String()
+}
+
+// restartMarker is a moment worth lining the restarts up against, such as a rollout.
+type restartMarker struct {
+	Time    time.Time
+	Label   string
+	Rollout bool
+}
+
+// getRestartBucket returns the bucket of the window ending now that a time falls into, or false
+// if it's outside the window.
+func getRestartBucket(t time.Time, now time.Time) (int, bool) {
+	begin := now.Add(-restartHistoryWindow)
+	if t.Before(begin) || t.After(now) {
+		return 0, false
+	}
+	b := int(t.Sub(begin) / (restartHistoryWindow / restartHistoryBuckets))
+	if b >= restartHistoryBuckets {
+		b = restartHistoryBuckets - 1
+	}
+	return b, true
+}
+
+// describeRestartPattern sums up the shape of a container's restarts: spread over most of the
+// window, or bunched together, and whether they began right after one of the markers (the pod's
+// creation, a rollout), which points at it as the cause.
+func describeRestartPattern(buckets []int, markers []restartMarker, now time.Time) string {
+	busy := 0
+	firstBusy := -1
+	for i, n := range buckets {
+		if n > 0 {
+			busy++
+			if firstBusy < 0 {
+				firstBusy = i
+			}
+		}
+	}
+
+	pattern := "bursty"
+	if busy*2 >= len(buckets) {
+		pattern = "constant"
+	} else if busy == 1 {
+		pattern = "a single burst"
+	}
+
+	// the markers are in time order; the last one just before the first restart is the suspect,
+	// though a rollout is a better one than the pod's creation, which it most likely caused
+	cause := ""
+	rollout := false
+	for _, m := range markers {
+		if b, ok := getRestartBucket(m.Time, now); ok && firstBusy >= 0 && b >= firstBusy-1 && b <= firstBusy {
+			if m.Rollout || !rollout {
+				cause, rollout = m.Label, m.Rollout
+			}
+		}
+	}
+	if cause != "" {
+		pattern += fmt.Sprintf("; began right after %s", cause)
+	}
+	return pattern
+}
+
+// getRestartHistory charts when each restarting container restarted over the last few hours, as
+// a sparkline, so that it's obvious at a glance whether the crashes are constant, come in bursts,
+// or began with a deploy; the pod's creation and the rollouts of its Deployment are marked under
+// the chart.  The history only goes back as far as the pod's events, which the API server keeps
+// for an hour by default.
+func (dp *podInspectCommand) getRestartHistory(pod *v1.Pod) (string, error) {
+	restarting := []v1.ContainerStatus{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if cs.RestartCount > 1 {
+			restarting = append(restarting, cs)
+		}
+	}
+	if len(restarting) == 0 {
+		return "", nil
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+	containerEvents, _ := groupContainerEvents(events, pod)
+
+	markers, err := dp.getRolloutMarkers(pod)
+	if err != nil {
+		return "", err
+	}
+	markers = append(markers, restartMarker{Time: pod.CreationTimestamp.Time, Label: "the pod was created"})
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].Time.Before(markers[j].Time) })
+
+	now := time.Now()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Restarts").String(),
+		aurora.Yellow(fmt.Sprintf("%s ago%snow", duration.HumanDuration(restartHistoryWindow), strings.Repeat(" ", restartHistoryBuckets-7))).String(),
+		aurora.Yellow("Pattern").String(),
+	})
+
+	rows := 0
+	for _, cs := range restarting {
+		// the container's first start isn't a restart; unless its events have expired, it's the
+		// earliest one we know of
+		starts := getContainerStarts(containerEvents[cs.Name], cs)
+		if extra := len(starts) - int(cs.RestartCount); extra > 0 {
+			starts = starts[extra:]
+		}
+
+		buckets := bucketRestarts(starts, now)
+		total := 0
+		for _, n := range buckets {
+			total += n
+		}
+		if total == 0 {
+			continue
+		}
+		rows++
+
+		tw.Append([]string{
+			cs.Name,
+			fmt.Sprintf("%d", total),
+			"│" + aurora.Red(renderSparkline(buckets)).String() + "│",
+			describeRestartPattern(buckets, markers, now),
+		})
+	}
+	if rows == 0 {
+		return "", nil
+	}
+
+	for _, m := range markers {
+		b, ok := getRestartBucket(m.Time, now)
+		if !ok {
+			continue
+		}
+		marker := " " + strings.Repeat(" ", b) + "^" + strings.Repeat(" ", restartHistoryBuckets-b-1) + " "
+		tw.Append([]string{"", "", marker, fmt.Sprintf("%s %s ago", m.Label, formatAge(metav1.NewTime(m.Time)))})
+	}
+	tw.Render()
+
+	retval := aurora.Cyan(fmt.Sprintf("Restart History (last %s):\n\n", duration.HumanDuration(restartHistoryWindow))).String()
+	retval += sb.String()
+
+	return retval, nil
+}
+
+// the kubelet's crash loop backoff: it doubles from the initial delay with each restart, up to
+// the cap
+const (
+	crashLoopInitialBackoff = 10 * time.Second
+	crashLoopMaxBackoff     = 5 * time.Minute
+)
+
+// restarts shown per container in the restart timeline; earlier ones are only counted
+const maxRestartTimelineRows = 10
+
+// the backoff in a CrashLoopBackOff waiting message, e.g. "back-off 5m0s restarting failed container=..."
+var crashLoopBackoffRegexp = regexp.MustCompile(`back-off (\S+) restarting`)
+
+// getCrashLoopBackoff returns the backoff the kubelet waits before the nth restart.
+func getCrashLoopBackoff(n int) time.Duration {
+	backoff := crashLoopInitialBackoff
+	for i := 1; i < n && backoff < crashLoopMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > crashLoopMaxBackoff {
+		backoff = crashLoopMaxBackoff
+	}
+	return backoff
+}
+
+// describeTerminationOutcome renders how a container instance ended: exit code and reason, and
+// how long it had run.
+func describeTerminationOutcome(t *v1.ContainerStateTerminated) string {
+	outcome := fmt.Sprintf("exit %d", t.ExitCode)
+	if t.Reason != "" {
+		outcome += fmt.Sprintf(" (%s)", t.Reason)
+	}
+	outcome += fmt.Sprintf(" after %s", formatDuration(t.StartedAt, t.FinishedAt))
+	if t.ExitCode != 0 {
+		outcome = aurora.Red(outcome).String()
+	}
+	return outcome
+}
+
+// getRestartTimeline lays out the recent restarts of each crashlooping container as a table: when
+// each run started, the time since the one before, the backoff the kubelet waited before it, and,
+// for the runs the kubelet still has the status of, how they ended.  It's summed up in a line
+// like "restarted 14 times, about every 5m, last exit 1".  Start times come from the container's
+// Started events, which the event recorder folds together, so those in between are estimated;
+// the kubelet only keeps the exit code of the last run.
+func (dp *podInspectCommand) getRestartTimeline(pod *v1.Pod) (string, error) {
+	crashing := []v1.ContainerStatus{}
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if getWaitingReason(cs) == "CrashLoopBackOff" && cs.RestartCount > 0 {
+			crashing = append(crashing, cs)
+		}
+	}
+	if len(crashing) == 0 {
+		return "", nil
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+	containerEvents, _ := groupContainerEvents(events, pod)
+
+	retval := aurora.Cyan("Restart Timeline:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Restart").String(),
+		aurora.Yellow("Started").String(),
+		aurora.Yellow("Since Previous").String(),
+		aurora.Yellow("Backoff").String(),
+		aurora.Yellow("Outcome").String(),
+	})
+
+	summaries := []string{}
+	for _, cs := range crashing {
+		starts := getContainerStarts(containerEvents[cs.Name], cs)
+		if extra := len(starts) - int(cs.RestartCount); extra > 0 {
+			starts = starts[extra:]
+		}
+
+		intervals := []time.Duration{}
+		for i := 1; i < len(starts); i++ {
+			intervals = append(intervals, starts[i].Sub(starts[i-1]))
+		}
+
+		first := 0
+		if len(starts) > maxRestartTimelineRows {
+			first = len(starts) - maxRestartTimelineRows
+		}
+		if earliest := int(cs.RestartCount) - (len(starts) - 1 - first); earliest > 1 {
+			note := "(earlier restarts)"
+			if first == 0 {
+				note = "(earlier restarts; their events have expired)"
+			}
+			tw.Append([]string{cs.Name, fmt.Sprintf("1-%d", earliest-1), "", "", "", note})
+		}
+		for i := first; i < len(starts); i++ {
+			n := int(cs.RestartCount) - (len(starts) - 1 - i)
+			since := ""
+			if i > 0 {
+				since = duration.HumanDuration(intervals[i-1])
+			}
+			outcome := ""
+			if i == len(starts)-1 && cs.LastTerminationState.Terminated != nil {
+				outcome = describeTerminationOutcome(cs.LastTerminationState.Terminated)
+			}
+			tw.Append([]string{cs.Name, fmt.Sprintf("%d", n), dp.formatTimestamp(metav1.NewTime(starts[i])), since, getCrashLoopBackoff(n).String(), outcome})
+		}
+
+		waiting := "waiting to restart"
+		if m := crashLoopBackoffRegexp.FindStringSubmatch(cs.State.Waiting.Message); m != nil {
+			waiting = fmt.Sprintf("backing off %s before restart %d", m[1], cs.RestartCount+1)
+		}
+		tw.Append([]string{cs.Name, "now", "", "", "", aurora.Yellow(waiting).String()})
+
+		summary := fmt.Sprintf("container %s restarted %d times", cs.Name, cs.RestartCount)
+		if len(intervals) > 0 {
+			sorted := append([]time.Duration{}, intervals...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			summary += fmt.Sprintf(", about every %s", duration.HumanDuration(sorted[len(sorted)/2]))
+		}
+		if lts := cs.LastTerminationState.Terminated; lts != nil {
+			summary += fmt.Sprintf(", last exit %d", lts.ExitCode)
+			if lts.Reason != "" {
+				summary += fmt.Sprintf(" (%s)", lts.Reason)
+			}
+		}
+		if getCrashLoopBackoff(int(cs.RestartCount)) == crashLoopMaxBackoff {
+			summary += fmt.Sprintf("; the backoff is at its %s cap", duration.HumanDuration(crashLoopMaxBackoff))
+		}
+		summaries = append(summaries, fmt.Sprintf("%s  %s", aurora.Red("✖").String(), summary))
+	}
+	tw.Render()
+	retval += sb.String()
+
+	retval += "\n" + strings.Join(summaries, "\n") + "\n"
+
+	return retval, nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// deploymentRevision is one of a Deployment's rollouts, as recorded on the ReplicaSet that was
+// created for it.
+type deploymentRevision struct {
+	Revision   int
+	ReplicaSet string
+	Created    metav1.Time
+}
+
+// getDeploymentRevisions lists the rollouts of a Deployment that its ReplicaSets still record,
+// oldest first.  A ReplicaSet takes a new revision number when it's rolled back to, but keeps its
+// creation time, so the time of a rollback is that of the original rollout.  Only the ReplicaSets
+// matching the Deployment's selector are listed, and the result is cached per Deployment, since
+// a sweep finds the same Deployment for each of its pods.
+func (dp *podInspectCommand) getDeploymentRevisions(deployment *ownerObject) ([]deploymentRevision, error) {
+	if revisions, ok := dp.deploymentRevisions[deployment.Metadata.UID]; ok {
+		return revisions, nil
+	}
+
+	selector, err := deployment.getSelector()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := dp.clientset.AppsV1().RESTClient().Get().Namespace(dp.namespace).Resource("replicasets").Param("labelSelector", selector).Do(context.Background()).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	list := struct {
+		Items []struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		} `json:"items"`
+	}{}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+
+	revisions := []deploymentRevision{}
+	for _, rs := range list.Items {
+		ref := getControllerOf(rs.Metadata)
+		if ref == nil || ref.Kind != "Deployment" || ref.UID != deployment.Metadata.UID {
+			continue
+		}
+		revision, err := strconv.Atoi(rs.Metadata.Annotations[deploymentRevisionAnnotation])
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, deploymentRevision{Revision: revision, ReplicaSet: rs.Metadata.Name, Created: rs.Metadata.CreationTimestamp})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+
+	if dp.deploymentRevisions == nil {
+		dp.deploymentRevisions = map[types.UID][]deploymentRevision{}
+	}
+	dp.deploymentRevisions[deployment.Metadata.UID] = revisions
+
+	return revisions, nil
+}
+
+// getRolloutMarkers returns a marker for each rollout of the pod's Deployment, for lining its
+// restarts up against releases.
+func (dp *podInspectCommand) getRolloutMarkers(pod *v1.Pod) ([]restartMarker, error) {
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil || len(chain) < 2 || chain[0].Kind != "ReplicaSet" || chain[1].Kind != "Deployment" {
+		return nil, err
+	}
+
+	revisions, err := dp.getDeploymentRevisions(chain[1])
+	if err != nil {
+		return nil, err
+	}
+
+	markers := []restartMarker{}
+	for _, r := range revisions {
+		label := fmt.Sprintf("revision %d rolled out", r.Revision)
+		if r.ReplicaSet == chain[0].Name {
+			label += " (this pod's)"
+		}
+		markers = append(markers, restartMarker{Time: r.Created.Time, Label: label, Rollout: true})
+	}
+	return markers, nil
+}
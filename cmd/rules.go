@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// rule is one named check of a pod.  Rules come in sets (the lint rules, ...), which the user
+// can narrow down by name; each finding is a sentence about what was found and why it matters.
+type rule struct {
+	Name        string
+	Description string
+	Check       func(dp *podInspectCommand, pod *v1.Pod) ([]string, error)
+}
+
+// ruleNames lists the names of the rules in a set, for error messages and help text.
+func ruleNames(set []rule) string {
+	names := make([]string, 0, len(set))
+	for _, r := range set {
+		names = append(names, r.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// selectRules narrows a rule set down by a list of rule names: the rules to run, or, prefixed
+// with "-", the rules to skip.  An empty list selects the whole set.
+func selectRules(set []rule, names []string) ([]rule, error) {
+	known := map[string]bool{}
+	for _, r := range set {
+		known[r.Name] = true
+	}
+
+	only := map[string]bool{}
+	skip := map[string]bool{}
+	for _, name := range names {
+		trimmed := strings.TrimPrefix(name, "-")
+		if !known[trimmed] {
+			return nil, fmt.Errorf("unknown rule '%s'; must be one of: %s", trimmed, ruleNames(set))
+		}
+		if trimmed != name {
+			skip[trimmed] = true
+		} else {
+			only[name] = true
+		}
+	}
+	if len(only) > 0 && len(skip) > 0 {
+		return nil, fmt.Errorf("rules can be named to run or to skip (with a leading -), but not both")
+	}
+
+	selected := []rule{}
+	for _, r := range set {
+		if skip[r.Name] || (len(only) > 0 && !only[r.Name]) {
+			continue
+		}
+		selected = append(selected, r)
+	}
+	return selected, nil
+}
+
+// runRules runs the rules against the pod, returning the findings as rows of rule name and
+// finding.
+func (dp *podInspectCommand) runRules(rules []rule, pod *v1.Pod) ([][]string, error) {
+	rows := [][]string{}
+	for _, r := range rules {
+		findings, err := r.Check(dp, pod)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range findings {
+			rows = append(rows, []string{r.Name, f})
+		}
+	}
+	return rows, nil
+}
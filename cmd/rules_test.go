@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectRules(t *testing.T) {
+	set := []rule{{Name: "latest-tag"}, {Name: "no-limits"}, {Name: "no-probes"}}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr string
+	}{
+		{name: "whole set", names: nil, want: []string{"latest-tag", "no-limits", "no-probes"}},
+		{name: "only", names: []string{"no-probes", "latest-tag"}, want: []string{"latest-tag", "no-probes"}},
+		{name: "skip", names: []string{"-no-limits"}, want: []string{"latest-tag", "no-probes"}},
+		{name: "skip all", names: []string{"-latest-tag", "-no-limits", "-no-probes"}, want: []string{}},
+		{name: "unknown", names: []string{"no-such-rule"}, wantErr: "unknown rule 'no-such-rule'; must be one of: latest-tag, no-limits, no-probes"},
+		{name: "unknown skip", names: []string{"-no-such-rule"}, wantErr: "unknown rule 'no-such-rule'"},
+		{name: "only and skip", names: []string{"latest-tag", "-no-limits"}, wantErr: "not both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectRules(set, tt.names)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("selectRules(%v) error = %v, want one containing %q", tt.names, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectRules(%v) error = %v", tt.names, err)
+			}
+			got := []string{}
+			for _, r := range selected {
+				got = append(got, r.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectRules(%v) = %v, want %v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
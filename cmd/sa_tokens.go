@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// projected tokens that expire sooner than this are flagged: the kubelet rotates them well before
+// they expire, but an application that reads its token only once at startup will start failing
+// with 401s about this long after it starts
+const shortTokenExpiration = time.Hour
+
+// a projected token's expirationSeconds defaults to an hour
+const defaultTokenExpirationSeconds = 3600
+
+// getServiceAccountTokens shows the projected service account tokens the pod mounts, with their
+// audiences and expirations.
+func (dp *podInspectCommand) getServiceAccountTokens(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	type tokenRow struct {
+		volume     string
+		path       string
+		audience   string
+		expiration time.Duration
+		mountedBy  []string
+	}
+	rows := []tokenRow{}
+
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Projected == nil {
+			continue
+		}
+		for _, source := range vol.Projected.Sources {
+			token := source.ServiceAccountToken
+			if token == nil {
+				continue
+			}
+
+			expirationSeconds := int64(defaultTokenExpirationSeconds)
+			if token.ExpirationSeconds != nil {
+				expirationSeconds = *token.ExpirationSeconds
+			}
+
+			row := tokenRow{
+				volume:     vol.Name,
+				path:       token.Path,
+				audience:   token.Audience,
+				expiration: time.Duration(expirationSeconds) * time.Second,
+			}
+
+			for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+				for _, mount := range c.VolumeMounts {
+					if mount.Name == vol.Name {
+						row.mountedBy = append(row.mountedBy, fmt.Sprintf("%s (%s)", c.Name, path.Join(mount.MountPath, token.Path)))
+					}
+				}
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		return "", nil
+	}
+
+	// the only token most pods have is the default API token; that isn't interesting on its own
+	if len(rows) == 1 && rows[0].audience == "" && rows[0].expiration >= shortTokenExpiration {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Service Account Tokens:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Volume").String(),
+		aurora.Yellow("Audience").String(),
+		aurora.Yellow("Expiration").String(),
+		aurora.Yellow("Mounted By").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	short := false
+	for _, row := range rows {
+		audience := row.audience
+		if audience == "" {
+			audience = "(API server)"
+		}
+
+		status := aurora.Green("✔").String()
+		if row.expiration < shortTokenExpiration {
+			status = fmt.Sprintf("%s  short-lived; must be re-read from disk", aurora.Yellow("…").String())
+			short = true
+		}
+
+		tw.Append([]string{
+			row.volume,
+			audience,
+			duration.HumanDuration(row.expiration),
+			strings.Join(row.mountedBy, ", "),
+			status,
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if short {
+		retval += fmt.Sprintf("\n%s  applications that read their token only at startup fail with 401 Unauthorized once a short-lived token expires; Kubernetes clients reload it from client-go v0.15, Python v12, Java v9, JavaScript v0.10.3 and Ruby v0.10 on\n", aurora.Yellow("…").String())
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+)
+
+// scanSummary tallies a run over many pods, for the footer of a sweep and the summary of a
+// structured report.
+type scanSummary struct {
+	start   time.Time
+	summary report.Summary
+	rows    []historyRow
+}
+
+func newScanSummary() *scanSummary {
+	return &scanSummary{start: time.Now()}
+}
+
+// addPod counts an inspected pod by its verdict, along with its warning events, and keeps its row
+// for --history-db.  The events are already cached by the time a pod has been inspected, so
+// counting them costs nothing.
+func (s *scanSummary) addPod(dp *podInspectCommand, pod *v1.Pod, verdict report.Verdict) {
+	s.rows = append(s.rows, newHistoryRow(pod, verdict, s.start))
+	s.summary.Inspected++
+	switch verdict {
+	case report.VerdictHealthy:
+		s.summary.Healthy++
+	case report.VerdictDegraded:
+		s.summary.Degraded++
+	case report.VerdictFailed:
+		s.summary.Failed++
+	case report.VerdictPending:
+		s.summary.Pending++
+	default:
+		s.summary.Unknown++
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return
+	}
+	for _, event := range events {
+		if event.Type == v1.EventTypeWarning {
+			s.summary.WarningEvents++
+		}
+	}
+}
+
+// addError counts a pod that couldn't be inspected.
+func (s *scanSummary) addError(pod types.NamespacedName, err error) {
+	s.summary.Inspected++
+	s.summary.APIErrors++
+	s.summary.Errors = append(s.summary.Errors, fmt.Sprintf("%s: %v", pod, err))
+}
+
+// finish stops the clock and returns the summary.
+func (s *scanSummary) finish() *report.Summary {
+	s.summary.Elapsed = metav1.Duration{Duration: time.Since(s.start).Round(time.Millisecond)}
+	return &s.summary
+}
+
+// printScanSummary prints the footer of a sweep, e.g. "42 pods inspected in 3.2s: 38 healthy,
+// 2 failed, 2 pending; 17 warning events", followed by the errors of the pods that couldn't be
+// inspected.
+func (dp *podInspectCommand) printScanSummary(summary *report.Summary) {
+	counts := []string{}
+	for _, c := range []struct {
+		n     int
+		label string
+		color colorFunc
+	}{
+		{summary.Healthy, "healthy", aurora.Green},
+		{summary.Degraded, "degraded", aurora.Yellow},
+		{summary.Failed, "failed", aurora.Red},
+		{summary.Pending, "pending", aurora.Yellow},
+		{summary.Unknown, "unknown", aurora.Yellow},
+	} {
+		if c.n > 0 {
+			counts = append(counts, c.color(fmt.Sprintf("%d %s", c.n, c.label)).String())
+		}
+	}
+
+	line := fmt.Sprintf("%d pods inspected in %s", summary.Inspected, summary.Elapsed.Duration)
+	if len(counts) > 0 {
+		line += ": " + strings.Join(counts, ", ")
+	}
+	line += fmt.Sprintf("; %d warning events", summary.WarningEvents)
+	if summary.APIErrors > 0 {
+		line += "; " + aurora.Red(fmt.Sprintf("%d API errors", summary.APIErrors)).String()
+	}
+
+	retval := aurora.Cyan("Summary:\n\n").String() + line + "\n"
+	for _, e := range summary.Errors {
+		retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), e)
+	}
+
+	fmt.Fprintf(dp.out, "\n%s", retval)
+}
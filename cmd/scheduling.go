@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nodes listed by name in the scheduling analysis before the rest are only counted
+const maxSchedulingNodes = 20
+
+// isPodPending reports whether the pod is waiting for a node: it hasn't been scheduled, and so
+// has no container statuses to report.
+func isPodPending(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodPending && pod.Spec.NodeName == ""
+}
+
+// matchNodeSelectorRequirement reports whether a set of labels (or fields) satisfies one
+// requirement of a node selector term.
+func matchNodeSelectorRequirement(req v1.NodeSelectorRequirement, values map[string]string) bool {
+	value, ok := values[req.Key]
+	switch req.Operator {
+	case v1.NodeSelectorOpIn, v1.NodeSelectorOpNotIn:
+		in := false
+		for _, v := range req.Values {
+			if ok && v == value {
+				in = true
+			}
+		}
+		return in == (req.Operator == v1.NodeSelectorOpIn)
+	case v1.NodeSelectorOpExists:
+		return ok
+	case v1.NodeSelectorOpDoesNotExist:
+		return !ok
+	case v1.NodeSelectorOpGt, v1.NodeSelectorOpLt:
+		if !ok || len(req.Values) != 1 {
+			return false
+		}
+		n, err1 := strconv.ParseInt(value, 10, 64)
+		bound, err2 := strconv.ParseInt(req.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		return (req.Operator == v1.NodeSelectorOpGt && n > bound) || (req.Operator == v1.NodeSelectorOpLt && n < bound)
+	}
+	return false
+}
+
+// matchNodeSelectorTerms reports whether the node matches any of the terms of a required node
+// affinity.  Like the scheduler, a term with no requirements matches no node.
+func matchNodeSelectorTerms(terms []v1.NodeSelectorTerm, node *v1.Node) bool {
+	fields := map[string]string{"metadata.name": node.Name}
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		matched := true
+		for _, req := range term.MatchExpressions {
+			matched = matched && matchNodeSelectorRequirement(req, node.Labels)
+		}
+		for _, req := range term.MatchFields {
+			matched = matched && matchNodeSelectorRequirement(req, fields)
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getNodeFitFailures returns the reasons the pod doesn't fit on the node, in the scheduler's
+// terms, or none if it does: the node isn't ready or is cordoned, has a taint the pod doesn't
+// tolerate, doesn't match the pod's nodeSelector or required node affinity, or hasn't the free
+// allocatable resources for the pod's requests.
+func getNodeFitFailures(pod *v1.Pod, node *v1.Node, requests v1.ResourceList, nodePods []v1.Pod) []string {
+	failures := []string{}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == v1.NodeReady && condition.Status != v1.ConditionTrue {
+			failures = append(failures, "node isn't ready")
+		}
+	}
+	if node.Spec.Unschedulable {
+		failures = append(failures, "node is cordoned")
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if getTolerationFor(pod, taint) == nil {
+			failures = append(failures, fmt.Sprintf("untolerated taint %s", taint.ToString()))
+		}
+	}
+
+	keys := []string{}
+	for key := range pod.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := pod.Spec.NodeSelector[key]; node.Labels[key] != value {
+			failures = append(failures, fmt.Sprintf("doesn't match nodeSelector %s=%s", key, value))
+		}
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchNodeSelectorTerms(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+			failures = append(failures, "doesn't match the required node affinity")
+		}
+	}
+
+	used := v1.ResourceList{}
+	for i := range nodePods {
+		for name, q := range getPodEffectiveRequests(&nodePods[i]) {
+			total := used[name]
+			total.Add(q)
+			used[name] = total
+		}
+	}
+	names := []string{}
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		name := v1.ResourceName(n)
+		request := requests[name]
+		if request.IsZero() {
+			continue
+		}
+		allocatable := node.Status.Allocatable[name]
+		free := allocatable.DeepCopy()
+		free.Sub(used[name])
+		if request.Cmp(free) > 0 {
+			if free.Sign() < 0 {
+				free.Set(0)
+			}
+			failures = append(failures, fmt.Sprintf("insufficient %s (requests %s, %s free)", name, formatHeadroomQuantity(name, request), formatHeadroomQuantity(name, free)))
+		}
+	}
+	if maxPods, ok := node.Status.Allocatable[v1.ResourcePods]; ok && int64(len(nodePods)) >= maxPods.Value() {
+		failures = append(failures, fmt.Sprintf("too many pods (%d of %d)", len(nodePods), maxPods.Value()))
+	}
+
+	return failures
+}
+
+// getSchedulingAnalysis works out, for a pod stuck in Pending without a node, why no node fits
+// it, by checking each node against the pod's tolerations, nodeSelector, required node affinity
+// and resource requests.  The scheduler's own message says only how many nodes failed each
+// check, and only once it has given up on the pod; until then the pod's status is empty.
+func (dp *podInspectCommand) getSchedulingAnalysis(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if !isPodPending(pod) {
+		return "", nil
+	}
+
+	nodeList, err := dp.clientset.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	// one list of the pods holding resources across the cluster, rather than one per node
+	field := fmt.Sprintf("spec.nodeName!=,status.phase!=%s,status.phase!=%s", v1.PodSucceeded, v1.PodFailed)
+	podList, err := dp.clientset.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{FieldSelector: field})
+	if err != nil {
+		return "", err
+	}
+	nodePods := map[string][]v1.Pod{}
+	for _, p := range podList.Items {
+		nodePods[p.Spec.NodeName] = append(nodePods[p.Spec.NodeName], p)
+	}
+
+	retval += aurora.Cyan(fmt.Sprintf("Scheduling Analysis (%d nodes):\n\n", len(nodeList.Items))).String()
+
+	if len(nodeList.Items) == 0 {
+		retval += fmt.Sprintf("%s  the cluster has no nodes\n", aurora.Red("✖").String())
+		return retval, nil
+	}
+
+	requests := getPodEffectiveRequests(pod)
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Node").String(),
+		aurora.Yellow("Fits").String(),
+		aurora.Yellow("Reasons").String(),
+	})
+
+	fitting := 0
+	listed := 0
+	reasonCounts := map[string]int{}
+	reasons := []string{}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		failures := getNodeFitFailures(pod, node, requests, nodePods[node.Name])
+
+		for _, f := range failures {
+			// resource shortfalls differ in their numbers from node to node, so count them by resource
+			reason := f
+			if j := strings.Index(f, " ("); j >= 0 {
+				reason = f[:j]
+			}
+			if reasonCounts[reason] == 0 {
+				reasons = append(reasons, reason)
+			}
+			reasonCounts[reason]++
+		}
+
+		icon := aurora.Red("✖").String()
+		if len(failures) == 0 {
+			fitting++
+			icon = aurora.Green("✔").String()
+		}
+		if listed < maxSchedulingNodes {
+			listed++
+			tw.Append([]string{node.Name, icon, strings.Join(failures, "; ")})
+		}
+	}
+	tw.Render()
+	retval += sb.String()
+
+	if more := len(nodeList.Items) - listed; more > 0 {
+		retval += fmt.Sprintf("... and %d more nodes\n", more)
+	}
+
+	retval += "\n"
+	for _, reason := range reasons {
+		retval += fmt.Sprintf("%s  %d of %d nodes: %s\n", aurora.Red("✖").String(), reasonCounts[reason], len(nodeList.Items), reason)
+	}
+	if fitting > 0 {
+		retval += fmt.Sprintf("%s  %d nodes pass these checks; the scheduler may be rejecting them for inter-pod (anti-)affinity, topology spread constraints, or volume topology, or hasn't got to the pod yet\n", aurora.Yellow("…").String(), fitting)
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// sectionRenderer renders a section of the report on a pod, or "" if it has nothing to show.
+type sectionRenderer func(dp *podInspectCommand, pod *v1.Pod, rawPod []byte) (string, error)
+
+// reportSection is one part of the report on a pod.  Several sections can share a name, which
+// is what --sections selects them by.
+type reportSection struct {
+	Name   string
+	Render sectionRenderer
+}
+
+// podSection adapts a section that only needs the decoded pod.
+func podSection(render func(dp *podInspectCommand, pod *v1.Pod) (string, error)) sectionRenderer {
+	return func(dp *podInspectCommand, pod *v1.Pod, rawPod []byte) (string, error) {
+		return render(dp, pod)
+	}
+}
+
+// the sections of the report that displayPod renders itself: the header and the container table
+// come before reportSections, and the logs, which are fetched while everything else renders,
+// come after them
+const (
+	headerSection     = "header"
+	containersSection = "containers"
+	logsSection       = "logs"
+)
+
+// reportSections are the sections between the container table and the logs, in the order they
+// are displayed.  A new section goes here, under the name of the part of the report it belongs
+// to.
+var reportSections = []reportSection{
+	{Name: "status", Render: podSection((*podInspectCommand).getStaticPodNotice)},
+	{Name: "status", Render: podSection((*podInspectCommand).getStaleTemplateWarning)},
+	{Name: "status", Render: podSection((*podInspectCommand).getDeletionStatus)},
+	{Name: "status", Render: podSection((*podInspectCommand).getQOSImplications)},
+	{Name: "diagnosis", Render: podSection((*podInspectCommand).getDiagnosis)},
+	{Name: containersSection, Render: podSection((*podInspectCommand).getInitContainerTimeline)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getOOMKills)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getLastTerminations)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getJobFailurePolicy)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getRestartHistory)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getRestartTimeline)},
+	{Name: "terminations", Render: podSection((*podInspectCommand).getStartupProbeAnalysis)},
+	{Name: "probes", Render: podSection((*podInspectCommand).getProbes)},
+	{Name: "conditions", Render: podSection((*podInspectCommand).getPodConditionHistory)},
+	{Name: "conditions", Render: podSection((*podInspectCommand).getCustomPodConditions)},
+	{Name: "conditions", Render: podSection((*podInspectCommand).getStartupTimeline)},
+	{Name: "events", Render: podSection((*podInspectCommand).getPodEvents)},
+	{Name: "node", Render: podSection((*podInspectCommand).getSchedulingAnalysis)},
+	{Name: "events", Render: podSection((*podInspectCommand).getAutoscalerStatus)},
+	{Name: "events", Render: podSection((*podInspectCommand).getKarpenterStatus)},
+	{Name: "images", Render: podSection((*podInspectCommand).getPullSecretValidation)},
+	{Name: "images", Render: podSection((*podInspectCommand).getArchitectureMismatch)},
+	{Name: "images", Render: podSection((*podInspectCommand).getImageSignatures)},
+	{Name: "volumes", Render: podSection((*podInspectCommand).getVolumes)},
+	{Name: "volumes", Render: podSection((*podInspectCommand).getHostPathMounts)},
+	{Name: "volumes", Render: podSection((*podInspectCommand).getFSUsage)},
+	{Name: "network", Render: podSection((*podInspectCommand).getPodDNS)},
+	{Name: "network", Render: podSection((*podInspectCommand).getConnectivityChecks)},
+	{Name: "node", Render: podSection((*podInspectCommand).getNodeTaintRisk)},
+	{Name: "spec", Render: podSection((*podInspectCommand).getServiceAccountTokens)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getContainerResources)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getPodOverhead)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getNodeHeadroom)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getNodeAllocation)},
+	{Name: "node", Render: podSection((*podInspectCommand).getSpotNodeInfo)},
+	{Name: "node", Render: podSection((*podInspectCommand).getGPUHealth)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getHugePagesValidation)},
+	{Name: "resources", Render: podSection((*podInspectCommand).getCostEstimate)},
+	{Name: "resources", Render: (*podInspectCommand).getResizeStatus},
+	{Name: "spec", Render: podSection((*podInspectCommand).getInjectedComponents)},
+	{Name: "spec", Render: podSection((*podInspectCommand).getInjectionConflicts)},
+	{Name: "spec", Render: podSection((*podInspectCommand).getPodSecurityDryRun)},
+	{Name: "spec", Render: (*podInspectCommand).getLastAppliedDrift},
+	{Name: "spec", Render: podSection(func(dp *podInspectCommand, pod *v1.Pod) (string, error) {
+		if !dp.showFieldManagers {
+			return "", nil
+		}
+		return dp.getFieldManagers(pod)
+	})},
+	{Name: "spec", Render: podSection((*podInspectCommand).getSettingComparison)},
+	{Name: "spec", Render: podSection(func(dp *podInspectCommand, pod *v1.Pod) (string, error) {
+		if !dp.showCommand {
+			return "", nil
+		}
+		return dp.getContainerCommands(pod)
+	})},
+	{Name: "spec", Render: podSection((*podInspectCommand).getRecommendations)},
+	{Name: "spec", Render: podSection((*podInspectCommand).getSpecExcerpt)},
+}
+
+// getSectionNames returns the names --sections accepts, in the order the sections are displayed.
+func getSectionNames() []string {
+	names := []string{headerSection, containersSection}
+	seen := map[string]bool{headerSection: true, containersSection: true}
+	for _, s := range reportSections {
+		if !seen[s.Name] {
+			seen[s.Name] = true
+			names = append(names, s.Name)
+		}
+	}
+	return append(names, logsSection)
+}
+
+func validateSections(sections []string) error {
+	known := map[string]bool{}
+	for _, name := range getSectionNames() {
+		known[name] = true
+	}
+	for _, name := range sections {
+		if !known[name] {
+			return fmt.Errorf("unknown section '%s'; must be one of: %s", name, strings.Join(getSectionNames(), ", "))
+		}
+	}
+	return nil
+}
+
+// includeSection reports whether the report includes the named section: all of them do, unless
+// --sections picks some out.
+func (dp *podInspectCommand) includeSection(name string) bool {
+	if len(dp.sections) == 0 {
+		return true
+	}
+	for _, s := range dp.sections {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printReportSections renders the sections between the container table and the logs.
+func (dp *podInspectCommand) printReportSections(pod *v1.Pod, rawPod []byte) error {
+	for _, s := range reportSections {
+		if !dp.includeSection(s.Name) {
+			continue
+		}
+		if err := dp.printSection(s.Render(dp, pod, rawPod)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the Linux signal numbers a container is likely to die of
+var signalNames = map[int32]string{
+	1:  "SIGHUP",
+	2:  "SIGINT",
+	3:  "SIGQUIT",
+	4:  "SIGILL",
+	5:  "SIGTRAP",
+	6:  "SIGABRT",
+	7:  "SIGBUS",
+	8:  "SIGFPE",
+	9:  "SIGKILL",
+	10: "SIGUSR1",
+	11: "SIGSEGV",
+	12: "SIGUSR2",
+	13: "SIGPIPE",
+	14: "SIGALRM",
+	15: "SIGTERM",
+}
+
+// what a death by each signal usually means for a container
+var signalHints = map[string]string{
+	"SIGHUP":  "the process got a hangup and didn't handle it",
+	"SIGINT":  "the process was interrupted",
+	"SIGQUIT": "the process was told to quit and dump core",
+	"SIGILL":  "illegal instruction; a binary built for a newer CPU, or a corrupted binary",
+	"SIGTRAP": "a breakpoint or trap; usually a debugger, or a runtime crashing on purpose",
+	"SIGABRT": "the process aborted itself: a failed assertion, or a crash in native code",
+	"SIGBUS":  "bus error; often a memory-mapped file that was truncated, or a full /dev/shm",
+	"SIGFPE":  "arithmetic error, such as an integer division by zero",
+	"SIGKILL": "killed outright; usually the OOM killer, or the kubelet once the termination grace period ran out",
+	"SIGSEGV": "segmentation fault; a crash in the application or in a native library",
+	"SIGPIPE": "the process wrote to a pipe or socket that had been closed",
+	"SIGTERM": "the process was asked to stop (pod deletion, eviction, or a failed liveness probe) and exited on it",
+}
+
+// getSignalName returns the name of a signal, e.g. SIGKILL, or "signal N" for one we don't know.
+func getSignalName(signal int32) string {
+	if name, ok := signalNames[signal]; ok {
+		return name
+	}
+	return fmt.Sprintf("signal %d", signal)
+}
+
+// getTerminationSignal returns the signal a container instance died of, and whether it was
+// worked out from the exit code.  The kubelet's Signal field is left unset by most runtimes, but
+// a shell (or the runtime) reports a death by signal N as exit code 128+N.  Returns 0 if the
+// container didn't die of a signal.
+func getTerminationSignal(t *v1.ContainerStateTerminated) (int32, bool) {
+	if t == nil {
+		return 0, false
+	}
+	if t.Signal != 0 {
+		return t.Signal, false
+	}
+	if _, ok := signalNames[t.ExitCode-128]; ok && t.ExitCode > 128 {
+		return t.ExitCode - 128, true
+	}
+	return 0, false
+}
+
+// describeTerminationSignal renders the signal a container instance died of, e.g. "SIGKILL (9)",
+// noting when it was worked out from the exit code.  Returns "" if it didn't die of a signal.
+func describeTerminationSignal(t *v1.ContainerStateTerminated) string {
+	signal, inferred := getTerminationSignal(t)
+	if signal == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("%s (%d)", getSignalName(signal), signal)
+	if inferred {
+		s += fmt.Sprintf(", from exit code %d", t.ExitCode)
+	}
+	return s
+}
+
+// getSignalHint explains what a container instance dying of its signal usually means, e.g.
+// "died of SIGSEGV: segmentation fault; ...".  Returns "" if it didn't die of a signal, or of
+// one we have nothing to say about.
+func getSignalHint(t *v1.ContainerStateTerminated) string {
+	signal, _ := getTerminationSignal(t)
+	if signal == 0 {
+		return ""
+	}
+	name := getSignalName(signal)
+	if hint, ok := signalHints[name]; ok {
+		return fmt.Sprintf("died of %s: %s", name, hint)
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jpriebe/kubectl-pod-inspect/pkg/report"
+)
+
+// how long a POST of the report to an --also-output endpoint may take
+const sinkTimeout = 30 * time.Second
+
+// outputSink is a destination, besides the terminal, that a run writes its structured report
+// to: a file, or an HTTP endpoint the report is POSTed to.
+type outputSink struct {
+	format      string
+	destination string
+}
+
+func (s outputSink) isHTTP() bool {
+	return strings.HasPrefix(s.destination, "http://") || strings.HasPrefix(s.destination, "https://")
+}
+
+// parseOutputSink parses an --also-output value, e.g. json=report.json or
+// yaml=https://collector.example.com/reports.
+func parseOutputSink(spec string) (outputSink, error) {
+	format, destination := parseOutputFormat(spec)
+	valid := false
+	for _, f := range reportOutputFormats {
+		valid = valid || format == f
+	}
+	if !valid || destination == "" {
+		return outputSink{}, fmt.Errorf("invalid --also-output '%s'; must be <format>=<file or http(s) URL>, with format one of: %s", spec, strings.Join(reportOutputFormats, ", "))
+	}
+	return outputSink{format: format, destination: destination}, nil
+}
+
+// write writes the encoded report to the sink.
+func (s outputSink) write(data []byte) error {
+	if !s.isHTTP() {
+		return ioutil.WriteFile(s.destination, data, 0644)
+	}
+
+	client := &http.Client{Timeout: sinkTimeout}
+	resp, err := client.Post(s.destination, fmt.Sprintf("application/%s", s.format), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("posting the report to %s: %s", s.destination, resp.Status)
+	}
+	return nil
+}
+
+// writeSinks writes the report to every --also-output destination.  Each is tried, so that one
+// that's down doesn't keep the report from the others; the first error is returned.
+func (dp *podInspectCommand) writeSinks(r *report.Report) error {
+	var firstErr error
+	for _, sink := range dp.outputSinks {
+		data, err := encodeReport(r, sink.format)
+		if err == nil {
+			err = sink.write(data)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("--also-output %s=%s: %v", sink.format, sink.destination, err)
+		}
+	}
+	return firstErr
+}
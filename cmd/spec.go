@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// the parts of the pod spec that --show-spec can print
+var specExcerpts = []string{"containers", "volumes", "full"}
+
+func validateSpecExcerpt(excerpt string) error {
+	if excerpt == "" {
+		return nil
+	}
+	for _, e := range specExcerpts {
+		if excerpt == e {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported spec excerpt '%s'; must be one of: %s", excerpt, strings.Join(specExcerpts, ", "))
+}
+
+// checkOptionalValueArgs catches the value of a flag with an optional value that was given as a
+// separate argument: pflag only takes an optional value after an =, so `--show-spec containers`
+// is --show-spec with its default and a pod named "containers".  An argument that's one of the
+// flag's values is rejected with a hint to use the = form.
+func checkOptionalValueArgs(flags *pflag.FlagSet, args []string, values map[string][]string) error {
+	for name, choices := range values {
+		flag := flags.Lookup(name)
+		if flag == nil || !flag.Changed || flag.Value.String() != flag.NoOptDefVal {
+			continue
+		}
+		for _, arg := range args {
+			for _, choice := range choices {
+				if arg == choice {
+					return fmt.Errorf("'%s' was taken as a pod name; give the value of --%s after an =, as in --%s=%s", arg, name, name, arg)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// podSpecExcerpt holds the pieces of the pod spec shown for the "containers" and "volumes"
+// excerpts; empty fields are left out of the YAML.
+type podSpecExcerpt struct {
+	InitContainers []v1.Container `json:"initContainers,omitempty"`
+	Containers     []v1.Container `json:"containers,omitempty"`
+	Volumes        []v1.Volume    `json:"volumes,omitempty"`
+}
+
+// getSpecExcerpt renders the part of the pod spec selected with --show-spec as YAML, so it can be
+// read alongside the rest of the report instead of in a separate `kubectl get -o yaml`.
+func (dp *podInspectCommand) getSpecExcerpt(pod *v1.Pod) (string, error) {
+	var excerpt interface{}
+	switch dp.showSpec {
+	case "":
+		return "", nil
+	case "containers":
+		excerpt = podSpecExcerpt{InitContainers: pod.Spec.InitContainers, Containers: pod.Spec.Containers}
+	case "volumes":
+		excerpt = podSpecExcerpt{Volumes: pod.Spec.Volumes}
+	default:
+		excerpt = pod.Spec
+	}
+
+	out, err := yaml.Marshal(excerpt)
+	if err != nil {
+		return "", err
+	}
+
+	retval := aurora.Cyan(fmt.Sprintf("Spec (%s):\n\n", dp.showSpec)).String()
+	for _, line := range strings.Split(strings.TrimSuffix(string(out), "\n"), "\n") {
+		retval += fmt.Sprintf("  %s\n", line)
+	}
+
+	return retval, nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// node labels (and their values) that the cloud providers and node provisioners use to mark spot
+// or preemptible capacity
+var spotNodeLabels = []struct {
+	label string
+	value string
+}{
+	{"eks.amazonaws.com/capacityType", "SPOT"},
+	{"karpenter.sh/capacity-type", "spot"},
+	{"cloud.google.com/gke-spot", "true"},
+	{"cloud.google.com/gke-preemptible", "true"},
+	{"kubernetes.azure.com/scalesetpriority", "spot"},
+	{"node.kubernetes.io/lifecycle", "spot"},
+	{"node-lifecycle", "spot"},
+}
+
+// node events that announce a reclaim: spot interruption notices (aws-node-termination-handler,
+// Karpenter), GCE preemption, and the graceful node shutdown that follows them
+var preemptionEventRegexp = regexp.MustCompile(`(?i)spot|preempt|interrupt|rebalance|shutdown|terminat`)
+
+// a container that stopped within this long of a preemption event is taken to have been killed
+// by it
+const preemptionCorrelationWindow = 3 * time.Minute
+
+func getSpotNodeLabel(node *v1.Node) string {
+	for _, l := range spotNodeLabels {
+		if value, ok := node.Labels[l.label]; ok && strings.EqualFold(value, l.value) {
+			return fmt.Sprintf("%s=%s", l.label, value)
+		}
+	}
+	return ""
+}
+
+// getSpotNodeInfo flags pods running on spot/preemptible nodes and lines up container
+// terminations with the node's preemption events; a spot reclaim otherwise looks just like the
+// application crashing.
+func (dp *podInspectCommand) getSpotNodeInfo(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil || node == nil {
+		return "", err
+	}
+
+	spotLabel := getSpotNodeLabel(node)
+	if spotLabel == "" {
+		return "", nil
+	}
+
+	nodeEvents, err := dp.getNodeEvents(node.Name)
+	if err != nil {
+		return "", err
+	}
+
+	preemptions := []v1.Event{}
+	for _, event := range nodeEvents {
+		if preemptionEventRegexp.MatchString(event.Reason) || preemptionEventRegexp.MatchString(event.Message) {
+			preemptions = append(preemptions, event)
+		}
+	}
+	sort.Slice(preemptions, func(i, j int) bool {
+		a, b := getEventTimestamp(preemptions[i]), getEventTimestamp(preemptions[j])
+		return a.Before(&b)
+	})
+
+	retval += aurora.Cyan("Spot Node:\n\n").String()
+	retval += fmt.Sprintf("%s  node %s is spot/preemptible capacity (%s); it can be reclaimed at any time\n", aurora.Yellow("…").String(), node.Name, spotLabel)
+
+	if pod.Status.Reason == "Terminated" || pod.Status.Reason == "NodeShutdown" {
+		retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("pod was stopped by a node shutdown: %s", pod.Status.Message)))
+	}
+
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		terminated := cs.State.Terminated
+		if terminated == nil {
+			terminated = cs.LastTerminationState.Terminated
+		}
+		if terminated == nil || terminated.Reason == "Completed" {
+			continue
+		}
+
+		for _, event := range preemptions {
+			timestamp := getEventTimestamp(event)
+			gap := terminated.FinishedAt.Sub(timestamp.Time)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap <= preemptionCorrelationWindow {
+				retval += fmt.Sprintf("%s  %s\n", aurora.Red("✖").String(), aurora.Red(fmt.Sprintf("container '%s' terminated (%s) within %s of node event %s; likely a spot reclaim, not an application crash", cs.Name, terminated.Reason, preemptionCorrelationWindow, event.Reason)))
+				break
+			}
+		}
+	}
+
+	if len(preemptions) == 0 {
+		return retval, nil
+	}
+
+	if dp.numEvents > 0 && len(preemptions) > dp.numEvents {
+		preemptions = preemptions[len(preemptions)-dp.numEvents:]
+	}
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Last Seen").String(),
+		aurora.Yellow("Type").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Message").String(),
+	})
+
+	for _, event := range preemptions {
+		timestamp := getEventTimestamp(event)
+		tw.Append([]string{
+			dp.formatTimestamp(timestamp),
+			event.Type,
+			event.Reason,
+			event.Message,
+		})
+	}
+	tw.Render()
+
+	retval += "\n" + sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetSpotNodeLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{"on-demand", map[string]string{"eks.amazonaws.com/capacityType": "ON_DEMAND"}, ""},
+		{"no labels", nil, ""},
+		{"EKS", map[string]string{"eks.amazonaws.com/capacityType": "SPOT"}, "eks.amazonaws.com/capacityType=SPOT"},
+		{"Karpenter", map[string]string{"karpenter.sh/capacity-type": "spot"}, "karpenter.sh/capacity-type=spot"},
+		{"GKE preemptible", map[string]string{"cloud.google.com/gke-preemptible": "true"}, "cloud.google.com/gke-preemptible=true"},
+		{"case insensitive", map[string]string{"kubernetes.azure.com/scalesetpriority": "Spot"}, "kubernetes.azure.com/scalesetpriority=Spot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}
+			if got := getSpotNodeLabel(node); got != tt.want {
+				t.Errorf("getSpotNodeLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreemptionEventRegexp(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"SpotInterruption", true},
+		{"Preempted", true},
+		{"RebalanceRecommendation", true},
+		{"node is shutting down", false},
+		{"NodeShutdown", true},
+		{"TerminatingEvictedPod", true},
+		{"NodeReady", false},
+		{"Starting kubelet.", false},
+	}
+	for _, tt := range tests {
+		if got := preemptionEventRegexp.MatchString(tt.text); got != tt.want {
+			t.Errorf("preemptionEventRegexp.MatchString(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the annotation the deployment controller numbers a Deployment's templates with; each
+// ReplicaSet carries the revision of the template it was created from
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// the label the StatefulSet controller puts on a pod, naming the revision of the template it was
+// created from
+const controllerRevisionHashLabel = "controller-revision-hash"
+
+// getDeploymentRolloutState returns the reason of a Deployment's Progressing condition, which
+// says whether its latest rollout finished, is still going, or has stalled.
+func getDeploymentRolloutState(deployment *ownerObject) (string, error) {
+	obj := struct {
+		Status struct {
+			Conditions []struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"conditions"`
+		} `json:"status"`
+	}{}
+	if err := json.Unmarshal(deployment.Raw, &obj); err != nil {
+		return "", err
+	}
+
+	for _, c := range obj.Status.Conditions {
+		if c.Type == "Progressing" {
+			return c.Reason, nil
+		}
+	}
+	return "", nil
+}
+
+// getStaleTemplateWarning warns about a pod that was created from an older template than its
+// controller's current one: a survivor of a rollout that stalled, or that never finished
+// replacing it.  These tend to be the oldest pods in a workload, still running the old image or
+// config long after everyone assumes the rollout is done.
+func (dp *podInspectCommand) getStaleTemplateWarning(pod *v1.Pod) (string, error) {
+	if !dp.warnStale {
+		return "", nil
+	}
+
+	chain, err := dp.getControllerChain(pod.ObjectMeta)
+	if err != nil || len(chain) == 0 {
+		return "", err
+	}
+
+	message := ""
+	inProgress := false
+
+	switch chain[0].Kind {
+	case "ReplicaSet":
+		if len(chain) < 2 || chain[1].Kind != "Deployment" {
+			return "", nil
+		}
+		podRevision, err1 := strconv.Atoi(chain[0].Metadata.Annotations[deploymentRevisionAnnotation])
+		currentRevision, err2 := strconv.Atoi(chain[1].Metadata.Annotations[deploymentRevisionAnnotation])
+		if err1 != nil || err2 != nil || podRevision >= currentRevision {
+			return "", nil
+		}
+
+		state, err := getDeploymentRolloutState(chain[1])
+		if err != nil {
+			return "", err
+		}
+
+		message = fmt.Sprintf("pod is from revision %d of Deployment %s (ReplicaSet %s), but the Deployment is at revision %d", podRevision, chain[1].Name, chain[0].Name, currentRevision)
+		switch state {
+		case "ProgressDeadlineExceeded":
+			message += "; the rollout has stalled"
+		case "NewReplicaSetAvailable":
+			message += "; the rollout finished without replacing it"
+		default:
+			inProgress = true
+		}
+	case "StatefulSet":
+		obj := struct {
+			Status struct {
+				CurrentRevision string `json:"currentRevision"`
+				UpdateRevision  string `json:"updateRevision"`
+			} `json:"status"`
+		}{}
+		if err := json.Unmarshal(chain[0].Raw, &obj); err != nil {
+			return "", err
+		}
+
+		podRevision := pod.Labels[controllerRevisionHashLabel]
+		if podRevision == "" || obj.Status.UpdateRevision == "" || podRevision == obj.Status.UpdateRevision {
+			return "", nil
+		}
+
+		message = fmt.Sprintf("pod is from revision %s of StatefulSet %s, but the StatefulSet is updating to %s", podRevision, chain[0].Name, obj.Status.UpdateRevision)
+		// with the default OrderedReady policy, one broken replica holds up the rest of the rollout
+		message += "; a replica that isn't ready, or a partition, may be holding the rollout up"
+	default:
+		return "", nil
+	}
+
+	icon := aurora.Red("✖").String()
+	if inProgress {
+		icon = aurora.Yellow("…").String()
+		message += "; the rollout is still in progress"
+	}
+
+	retval := aurora.Cyan("Stale Template:\n\n").String()
+	retval += fmt.Sprintf("%s  %s (pod age %s)\n", icon, message, formatAge(pod.CreationTimestamp))
+
+	return retval, nil
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// the kubelet's defaults for a probe's periodSeconds and failureThreshold, and for the pod's
+// terminationGracePeriodSeconds
+const (
+	defaultProbePeriodSeconds     = 10
+	defaultProbeFailureThreshold  = 3
+	defaultTerminationGracePeriod = 30 * time.Second
+)
+
+// getProbePeriod returns how often a probe runs.
+func getProbePeriod(p *v1.Probe) time.Duration {
+	if p.PeriodSeconds > 0 {
+		return time.Duration(p.PeriodSeconds) * time.Second
+	}
+	return defaultProbePeriodSeconds * time.Second
+}
+
+// getProbeBudget returns how long a probe gives a container before failing it for good:
+// initialDelaySeconds, plus failureThreshold periods of failed checks.
+func getProbeBudget(p *v1.Probe) time.Duration {
+	failureThreshold := p.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultProbeFailureThreshold
+	}
+	return time.Duration(p.InitialDelaySeconds)*time.Second + time.Duration(failureThreshold)*getProbePeriod(p)
+}
+
+// describeProbeBudget spells out how a probe's budget adds up, e.g. "40s (initialDelaySeconds
+// 10 + failureThreshold 3 × periodSeconds 10)".
+func describeProbeBudget(p *v1.Probe) string {
+	failureThreshold := p.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultProbeFailureThreshold
+	}
+	return fmt.Sprintf("%s (initialDelaySeconds %d + failureThreshold %d × periodSeconds %d)",
+		duration.HumanDuration(getProbeBudget(p)), p.InitialDelaySeconds, failureThreshold, int32(getProbePeriod(p)/time.Second))
+}
+
+// countProbeKills counts the restarts of a container the kubelet put down to a failed probe,
+// from its "Container app failed liveness probe, will be restarted" events.
+func countProbeKills(events []v1.Event, probe string) int32 {
+	kills := int32(0)
+	for _, event := range events {
+		if event.Reason == "Killing" && strings.Contains(event.Message, fmt.Sprintf("failed %s probe", probe)) {
+			if event.Count > 1 {
+				kills += event.Count
+			} else {
+				kills++
+			}
+		}
+	}
+	return kills
+}
+
+// getTerminationGracePeriod returns how long the kubelet waits for a container to stop before
+// killing it.
+func getTerminationGracePeriod(pod *v1.Pod) time.Duration {
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		return time.Duration(*pod.Spec.TerminationGracePeriodSeconds) * time.Second
+	}
+	return defaultTerminationGracePeriod
+}
+
+// diedAtProbeDeadline reports whether a container instance ran for about as long as a probe
+// allows before it's failed for good: killed at the first moment the probe could kill it, as a
+// container that is still starting up is.  The kubelet's timing is only accurate to a probe
+// period, and a container that handles SIGTERM can take up to the grace period to stop.
+func diedAtProbeDeadline(t *v1.ContainerStateTerminated, p *v1.Probe, grace time.Duration) bool {
+	if t == nil || t.StartedAt.IsZero() || t.FinishedAt.IsZero() {
+		return false
+	}
+	ran := t.FinishedAt.Sub(t.StartedAt.Time)
+	budget := getProbeBudget(p)
+	period := getProbePeriod(p)
+	return ran >= budget-period && ran <= budget+2*period+grace
+}
+
+// getStartupProbeAnalysis works out, for containers that have been restarted, whether their
+// probes killed them before they had finished starting: a liveness probe without a startupProbe
+// starts checking at initialDelaySeconds, and restarts an app that takes longer than its budget
+// to start over and over; a startupProbe with too small a budget does the same.  The kubelet's
+// Killing events say which probe restarted a container; when they have expired, a container
+// that keeps dying just as its liveness budget runs out is flagged as a likely case.
+func (dp *podInspectCommand) getStartupProbeAnalysis(pod *v1.Pod) (string, error) {
+	statuses := map[string]v1.ContainerStatus{}
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+
+	events, err := dp.listPodEvents(pod)
+	if err != nil {
+		return "", err
+	}
+	byContainer, _ := groupContainerEvents(events, pod)
+	grace := getTerminationGracePeriod(pod)
+
+	findings := []string{}
+	for _, c := range pod.Spec.Containers {
+		cs, ok := statuses[c.Name]
+		if !ok || cs.RestartCount == 0 || (c.LivenessProbe == nil && c.StartupProbe == nil) {
+			continue
+		}
+		lts := cs.LastTerminationState.Terminated
+		ran := ""
+		if lts != nil {
+			ran = formatDuration(lts.StartedAt, lts.FinishedAt)
+		}
+
+		livenessKills := countProbeKills(byContainer[c.Name], "liveness")
+		startupKills := countProbeKills(byContainer[c.Name], "startup")
+
+		switch {
+		case c.StartupProbe != nil && startupKills > 0:
+			findings = append(findings, fmt.Sprintf("%s  container %s was restarted %d times by its startup probe before it finished starting; the startup probe allows %s.  If the app is only slow to start, raise startupProbe.failureThreshold",
+				aurora.Red("✖").String(), c.Name, startupKills, describeProbeBudget(c.StartupProbe)))
+		case c.StartupProbe == nil && c.LivenessProbe != nil && livenessKills > 0 && (lts == nil || diedAtProbeDeadline(lts, c.LivenessProbe, grace)):
+			msg := fmt.Sprintf("%s  container %s was killed %d times by its liveness probe while it was still starting up", aurora.Red("✖").String(), c.Name, livenessKills)
+			if ran != "" {
+				msg += fmt.Sprintf(" (the last instance ran for %s)", ran)
+			}
+			msg += fmt.Sprintf("; without a startupProbe, liveness checks begin after initialDelaySeconds, and the budget of %s is shorter than the app takes to start.  Add a startupProbe whose failureThreshold × periodSeconds covers the startup time, rather than raising initialDelaySeconds", describeProbeBudget(c.LivenessProbe))
+			findings = append(findings, msg)
+		case livenessKills > 0:
+			msg := fmt.Sprintf("%s  container %s was killed %d times by its liveness probe", aurora.Yellow("…").String(), c.Name, livenessKills)
+			if c.StartupProbe != nil {
+				msg += " after its startup probe had passed"
+			} else if ran != "" {
+				msg += fmt.Sprintf(", well after startup (the last instance ran for %s)", ran)
+			}
+			msg += "; the app stopped responding while running, rather than being slow to start"
+			findings = append(findings, msg)
+		case c.StartupProbe == nil && c.LivenessProbe != nil && lts != nil && lts.ExitCode != 0 && diedAtProbeDeadline(lts, c.LivenessProbe, grace):
+			if signal, _ := getTerminationSignal(lts); signal != 9 && signal != 15 {
+				continue
+			}
+			findings = append(findings, fmt.Sprintf("%s  container %s was killed after running for %s, about as long as its liveness probe allows, %s, and it has no startupProbe; the liveness probe likely killed it before it had finished starting (the events that would say so have expired)",
+				aurora.Yellow("…").String(), c.Name, ran, describeProbeBudget(c.LivenessProbe)))
+		}
+	}
+
+	if len(findings) == 0 {
+		return "", nil
+	}
+
+	retval := aurora.Cyan("Startup Probes:\n\n").String()
+	retval += strings.Join(findings, "\n") + "\n"
+
+	return retval, nil
+}
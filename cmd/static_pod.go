@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the kubelet marks the API server's copy of a static pod (the "mirror pod") with this
+// annotation, and records where it read the pod from in the config source annotation
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+const configSourceAnnotation = "kubernetes.io/config.source"
+
+// where kubeadm has the kubelet look for static pod manifests
+const defaultStaticPodPath = "/etc/kubernetes/manifests"
+
+// isStaticPod reports whether the object is the mirror of a static pod: one run by the kubelet
+// straight from a manifest on its node, rather than created through the API server.
+func isStaticPod(meta metav1.ObjectMeta) bool {
+	_, ok := meta.Annotations[mirrorPodAnnotation]
+	return ok
+}
+
+// getStaticPodNotice explains what's different about a static pod, which is likely to be one of
+// the control plane's: no controller stands behind it (its owner reference is to the node), and
+// it is changed or removed by editing its manifest on the node, not through the API.
+func (dp *podInspectCommand) getStaticPodNotice(pod *v1.Pod) (string, error) {
+	if !isStaticPod(pod.ObjectMeta) {
+		return "", nil
+	}
+
+	retval := aurora.Cyan("Static Pod:\n\n").String()
+
+	source := pod.Annotations[configSourceAnnotation]
+	switch source {
+	case "file", "":
+		// the kubelet names the mirror pod after the pod in the manifest, suffixed with the node name
+		name := strings.TrimSuffix(pod.Name, "-"+pod.Spec.NodeName)
+		retval += fmt.Sprintf("%s  static pod, run by the kubelet on node %s from a manifest file; usually %s/%s.yaml (see staticPodPath in the kubelet config)\n", aurora.Yellow("…").String(), pod.Spec.NodeName, defaultStaticPodPath, name)
+	default:
+		retval += fmt.Sprintf("%s  static pod, run by the kubelet on node %s from a manifest it reads over %s (see staticPodURL in the kubelet config)\n", aurora.Yellow("…").String(), pod.Spec.NodeName, source)
+	}
+	retval += fmt.Sprintf("%s  no controller will recreate it elsewhere, and deleting it through the API only deletes the mirror; edit or remove the manifest on the node instead\n", aurora.Yellow("…").String())
+
+	return retval, nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// getScheduledTime returns when the pod was bound to its node, falling back to when the kubelet
+// started it.
+func getScheduledTime(pod *v1.Pod) metav1.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodScheduled && condition.Status == v1.ConditionTrue {
+			return condition.LastTransitionTime
+		}
+	}
+	if pod.Status.StartTime != nil {
+		return *pod.Status.StartTime
+	}
+	return metav1.Time{}
+}
+
+// getTolerationFor returns the pod's toleration that matches the taint, if any.
+func getTolerationFor(pod *v1.Pod, taint *v1.Taint) *v1.Toleration {
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].ToleratesTaint(taint) {
+			return &pod.Spec.Tolerations[i]
+		}
+	}
+	return nil
+}
+
+// getNodeTaintRisk warns about taints on the pod's node that the pod doesn't (or only for a while)
+// tolerate.  Taints are only checked at scheduling time, so one added afterwards, e.g. by a node
+// problem detector or a drain, leaves a running pod that is about to be evicted (NoExecute) or
+// that couldn't be replaced on the same node (NoSchedule).
+func (dp *podInspectCommand) getNodeTaintRisk(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return "", nil
+	}
+
+	node, err := dp.getScheduledNode(pod)
+	if err != nil || node == nil {
+		return "", err
+	}
+
+	scheduled := getScheduledTime(pod)
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Taint").String(),
+		aurora.Yellow("Effect").String(),
+		aurora.Yellow("Added").String(),
+		aurora.Yellow("Status").String(),
+	})
+
+	found := false
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != v1.TaintEffectNoExecute && taint.Effect != v1.TaintEffectNoSchedule {
+			continue
+		}
+
+		toleration := getTolerationFor(pod, taint)
+
+		added := "n/a"
+		afterScheduling := ""
+		if taint.TimeAdded != nil && !taint.TimeAdded.IsZero() {
+			added = formatAge(*taint.TimeAdded) + " ago"
+			if !scheduled.IsZero() && taint.TimeAdded.After(scheduled.Time) {
+				afterScheduling = "; added after the pod was scheduled"
+			}
+		}
+
+		status := ""
+		switch {
+		case taint.Effect == v1.TaintEffectNoExecute && toleration == nil:
+			status = fmt.Sprintf("%s  not tolerated; the pod is about to be evicted%s", aurora.Red("✖").String(), afterScheduling)
+		case taint.Effect == v1.TaintEffectNoExecute && toleration.TolerationSeconds != nil:
+			tolerated := time.Duration(*toleration.TolerationSeconds) * time.Second
+			if taint.TimeAdded != nil && !taint.TimeAdded.IsZero() {
+				remaining := time.Until(taint.TimeAdded.Add(tolerated))
+				if remaining > 0 {
+					status = fmt.Sprintf("%s  tolerated for %s; evicted in %s%s", aurora.Yellow("…").String(), duration.HumanDuration(tolerated), duration.HumanDuration(remaining), afterScheduling)
+				} else {
+					status = fmt.Sprintf("%s  tolerated for %s, which has run out; eviction is overdue%s", aurora.Red("✖").String(), duration.HumanDuration(tolerated), afterScheduling)
+				}
+			} else {
+				status = fmt.Sprintf("%s  tolerated for %s only; the pod will be evicted", aurora.Yellow("…").String(), duration.HumanDuration(tolerated))
+			}
+		case taint.Effect == v1.TaintEffectNoSchedule && toleration == nil:
+			status = fmt.Sprintf("%s  not tolerated; the pod keeps running, but a replacement couldn't schedule on this node%s", aurora.Yellow("…").String(), afterScheduling)
+		default:
+			continue
+		}
+
+		name := taint.Key
+		if taint.Value != "" {
+			name = fmt.Sprintf("%s=%s", taint.Key, taint.Value)
+		}
+
+		tw.Append([]string{
+			name,
+			string(taint.Effect),
+			added,
+			status,
+		})
+		found = true
+	}
+
+	if !found {
+		return "", nil
+	}
+
+	tw.Render()
+
+	retval += aurora.Cyan(fmt.Sprintf("Node Taints (%s):\n\n", node.Name)).String()
+	retval += sb.String()
+
+	return retval, nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetTolerationFor(t *testing.T) {
+	pod := &v1.Pod{Spec: v1.PodSpec{Tolerations: []v1.Toleration{
+		{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	}}}
+
+	tests := []struct {
+		name  string
+		taint v1.Taint
+		want  string
+	}{
+		{"equal", v1.Taint{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}, "dedicated"},
+		{"other value", v1.Taint{Key: "dedicated", Value: "batch", Effect: v1.TaintEffectNoSchedule}, ""},
+		{"exists", v1.Taint{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute}, "node.kubernetes.io/unreachable"},
+		{"other effect", v1.Taint{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoSchedule}, ""},
+		{"unknown key", v1.Taint{Key: "node.kubernetes.io/disk-pressure", Effect: v1.TaintEffectNoSchedule}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if toleration := getTolerationFor(pod, &tt.taint); toleration != nil {
+				got = toleration.Key
+			}
+			if got != tt.want {
+				t.Errorf("getTolerationFor(%v) = %q, want %q", tt.taint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNodeTaintRisk(t *testing.T) {
+	scheduled := metav1.NewTime(time.Now().Add(-time.Hour))
+	recently := metav1.NewTime(time.Now().Add(-time.Minute))
+	longAgo := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	seconds := func(s int64) *int64 { return &s }
+
+	tests := []struct {
+		name        string
+		phase       v1.PodPhase
+		taints      []v1.Taint
+		tolerations []v1.Toleration
+		want        []string
+	}{
+		{
+			name: "no taints",
+		},
+		{
+			name:   "PreferNoSchedule is ignored",
+			taints: []v1.Taint{{Key: "soft", Effect: v1.TaintEffectPreferNoSchedule}},
+		},
+		{
+			name:   "NoExecute added after scheduling",
+			taints: []v1.Taint{{Key: "node.kubernetes.io/not-ready", Effect: v1.TaintEffectNoExecute, TimeAdded: &recently}},
+			want:   []string{"Node Taints (node-1):", "not tolerated; the pod is about to be evicted; added after the pod was scheduled"},
+		},
+		{
+			name:        "NoExecute tolerated for a while",
+			taints:      []v1.Taint{{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute, TimeAdded: &recently}},
+			tolerations: []v1.Toleration{{Key: "node.kubernetes.io/unreachable", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: seconds(300)}},
+			want:        []string{"tolerated for 5m; evicted in"},
+		},
+		{
+			name:        "NoExecute toleration run out",
+			taints:      []v1.Taint{{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute, TimeAdded: &longAgo}},
+			tolerations: []v1.Toleration{{Key: "node.kubernetes.io/unreachable", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: seconds(300)}},
+			want:        []string{"which has run out; eviction is overdue"},
+		},
+		{
+			name:        "tolerated forever",
+			taints:      []v1.Taint{{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoExecute}},
+			tolerations: []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+		},
+		{
+			name:   "NoSchedule",
+			taints: []v1.Taint{{Key: "node.kubernetes.io/unschedulable", Effect: v1.TaintEffectNoSchedule}},
+			want:   []string{"node.kubernetes.io/unschedulable", "a replacement couldn't schedule on this node"},
+		},
+		{
+			name:   "finished pods are left out",
+			phase:  v1.PodSucceeded,
+			taints: []v1.Taint{{Key: "node.kubernetes.io/not-ready", Effect: v1.TaintEffectNoExecute}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := &podInspectCommand{nodes: map[string]*v1.Node{
+				"node-1": {ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: v1.NodeSpec{Taints: tt.taints}},
+			}}
+			pod := &v1.Pod{
+				Spec: v1.PodSpec{NodeName: "node-1", Tolerations: tt.tolerations},
+				Status: v1.PodStatus{
+					Phase:      tt.phase,
+					Conditions: []v1.PodCondition{{Type: v1.PodScheduled, Status: v1.ConditionTrue, LastTransitionTime: scheduled}},
+				},
+			}
+
+			got, err := dp.getNodeTaintRisk(pod)
+			if err != nil {
+				t.Fatalf("getNodeTaintRisk() error = %v", err)
+			}
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("getNodeTaintRisk() = %q, want no section", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("getNodeTaintRisk() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
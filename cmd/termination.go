@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// getLastTerminations renders the details of the last termination of every container that has
+// been restarted: reason, exit code, signal, when it ran, and the termination message.
+func (dp *podInspectCommand) getLastTerminations(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+
+	terminated := []v1.ContainerStatus{}
+	for _, cs := range statuses {
+		if cs.LastTerminationState.Terminated != nil {
+			terminated = append(terminated, cs)
+		}
+	}
+
+	if len(terminated) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Last Termination:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Container").String(),
+		aurora.Yellow("Reason").String(),
+		aurora.Yellow("Exit Code").String(),
+		aurora.Yellow("Signal").String(),
+		aurora.Yellow("Started").String(),
+		aurora.Yellow("Finished").String(),
+		aurora.Yellow("Ran For").String(),
+	})
+
+	for _, cs := range terminated {
+		lts := cs.LastTerminationState.Terminated
+
+		exitCode := fmt.Sprintf("%d", lts.ExitCode)
+		if lts.ExitCode != 0 {
+			exitCode = aurora.Red(exitCode).String()
+		}
+
+		tw.Append([]string{
+			cs.Name,
+			lts.Reason,
+			exitCode,
+			describeTerminationSignal(lts),
+			lts.StartedAt.String(),
+			lts.FinishedAt.String(),
+			formatDuration(lts.StartedAt, lts.FinishedAt),
+		})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	for _, cs := range terminated {
+		lts := cs.LastTerminationState.Terminated
+		if hint := getSignalHint(lts); hint != "" {
+			retval += fmt.Sprintf("\n%s  %s %s\n", aurora.Yellow("…").String(), cs.Name, hint)
+		} else if hint := describeExitCode(lts); hint != "" {
+			retval += fmt.Sprintf("\n%s  %s: %s\n", aurora.Yellow("…").String(), cs.Name, hint)
+		}
+	}
+
+	// termination messages are free-form and often multi-line, so they go below the table rather
+	// than blowing out its column widths
+	for _, cs := range terminated {
+		lts := cs.LastTerminationState.Terminated
+		if lts.Message != "" {
+			retval += fmt.Sprintf("\n%s %s %s\n%s\n", aurora.Cyan("Container"), cs.Name, aurora.Cyan("termination message:"), strings.TrimRight(sanitizeText(lts.Message), "\n"))
+		}
+	}
+
+	return retval, nil
+}
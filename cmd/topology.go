@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// the well-known topology labels, followed by the deprecated ones that older clusters still set
+var (
+	zoneLabels   = []string{v1.LabelZoneFailureDomainStable, v1.LabelZoneFailureDomain}
+	regionLabels = []string{v1.LabelZoneRegionStable, v1.LabelZoneRegion}
+)
+
+func getFirstLabel(node *v1.Node, labels []string) string {
+	for _, label := range labels {
+		if value, ok := node.Labels[label]; ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getNodeTopology returns the zone and region of a node, either of which may be empty.
+func getNodeTopology(node *v1.Node) (string, string) {
+	return getFirstLabel(node, zoneLabels), getFirstLabel(node, regionLabels)
+}
+
+func formatTopology(zone, region string) string {
+	switch {
+	case zone == "":
+		return fmt.Sprintf("n/a (region %s)", region)
+	case region == "":
+		return zone
+	default:
+		return fmt.Sprintf("%s (region %s)", zone, region)
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// describeVolumeSource renders where a volume comes from, e.g. "configMap app-config" or
+// "persistentVolumeClaim data-db-0", for the common volume types, and the type alone otherwise.
+func describeVolumeSource(vol v1.Volume) string {
+	switch {
+	case vol.ConfigMap != nil:
+		return fmt.Sprintf("configMap %s", vol.ConfigMap.Name)
+	case vol.Secret != nil:
+		return fmt.Sprintf("secret %s", vol.Secret.SecretName)
+	case vol.PersistentVolumeClaim != nil:
+		return fmt.Sprintf("persistentVolumeClaim %s", vol.PersistentVolumeClaim.ClaimName)
+	case vol.HostPath != nil:
+		return fmt.Sprintf("hostPath %s", vol.HostPath.Path)
+	case vol.EmptyDir != nil:
+		details := []string{}
+		if vol.EmptyDir.Medium != v1.StorageMediumDefault {
+			details = append(details, string(vol.EmptyDir.Medium))
+		}
+		if vol.EmptyDir.SizeLimit != nil {
+			details = append(details, fmt.Sprintf("limit %s", vol.EmptyDir.SizeLimit.String()))
+		}
+		if len(details) == 0 {
+			return "emptyDir"
+		}
+		return fmt.Sprintf("emptyDir (%s)", strings.Join(details, ", "))
+	case vol.NFS != nil:
+		return fmt.Sprintf("nfs %s:%s", vol.NFS.Server, vol.NFS.Path)
+	case vol.CSI != nil:
+		return fmt.Sprintf("csi %s", vol.CSI.Driver)
+	case vol.Projected != nil:
+		sources := []string{}
+		for _, p := range vol.Projected.Sources {
+			switch {
+			case p.ConfigMap != nil:
+				sources = append(sources, fmt.Sprintf("configMap %s", p.ConfigMap.Name))
+			case p.Secret != nil:
+				sources = append(sources, fmt.Sprintf("secret %s", p.Secret.Name))
+			case p.ServiceAccountToken != nil:
+				sources = append(sources, "serviceAccountToken")
+			case p.DownwardAPI != nil:
+				sources = append(sources, "downwardAPI")
+			}
+		}
+		return fmt.Sprintf("projected (%s)", strings.Join(sources, ", "))
+	}
+	return getVolumeSourceType(vol)
+}
+
+// getVolumeMounts lists where each container mounts a volume, e.g. "app:/data (ro)".
+func getVolumeMounts(pod *v1.Pod, volume string) []string {
+	mounts := []string{}
+	for _, c := range append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...) {
+		for _, m := range c.VolumeMounts {
+			if m.Name != volume {
+				continue
+			}
+			mount := fmt.Sprintf("%s:%s", c.Name, m.MountPath)
+			if m.SubPath != "" {
+				mount += fmt.Sprintf(" (subPath %s)", m.SubPath)
+			}
+			if m.ReadOnly {
+				mount += " (ro)"
+			}
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts
+}
+
+// describeClaim renders a PVC's status: its phase, and for a bound claim its volume, capacity,
+// access modes and StorageClass.  The boolean result is false if the claim isn't bound.
+func describeClaim(pvc *v1.PersistentVolumeClaim) (string, bool) {
+	storageClass := "(default)"
+	if pvc.Spec.StorageClassName != nil {
+		storageClass = *pvc.Spec.StorageClassName
+	}
+	if pvc.Status.Phase != v1.ClaimBound {
+		return fmt.Sprintf("%s, StorageClass %s", pvc.Status.Phase, storageClass), false
+	}
+
+	modes := []string{}
+	for _, m := range pvc.Status.AccessModes {
+		modes = append(modes, string(m))
+	}
+	capacity := pvc.Status.Capacity[v1.ResourceStorage]
+	return fmt.Sprintf("Bound to %s, %s %s, StorageClass %s", pvc.Spec.VolumeName, capacity.String(), strings.Join(modes, ","), storageClass), true
+}
+
+// getVolumes renders, with --show-volumes, each of the pod's volumes, its source and the
+// containers that mount it, along with the status of its PersistentVolumeClaims.  A pod stuck in
+// ContainerCreating or Pending on a claim that isn't bound shows little more than that it's
+// waiting, so for such a pod the section is shown without the flag too, with the events of the
+// unbound claims, which say why no volume has been provisioned for them.
+func (dp *podInspectCommand) getVolumes(pod *v1.Pod) (string, error) {
+	retval := ""
+
+	claims := map[string]*v1.PersistentVolumeClaim{}
+	unbound := []*v1.PersistentVolumeClaim{}
+	missing := []string{}
+	if dp.showVolumes || pod.Status.Phase == v1.PodPending {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim == nil {
+				continue
+			}
+			name := vol.PersistentVolumeClaim.ClaimName
+			pvc, err := dp.clientset.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			claims[name] = pvc
+			if pvc.Status.Phase != v1.ClaimBound {
+				unbound = append(unbound, pvc)
+			}
+		}
+	}
+	if !dp.showVolumes && len(unbound) == 0 && len(missing) == 0 {
+		return "", nil
+	}
+
+	retval += aurora.Cyan("Volumes:\n\n").String()
+
+	sb := &strings.Builder{}
+	tw := dp.newTablewriter(sb)
+
+	tw.Append([]string{
+		aurora.Yellow("Volume").String(),
+		aurora.Yellow("Source").String(),
+		aurora.Yellow("Mounts").String(),
+		aurora.Yellow("Claim Status").String(),
+	})
+
+	for _, vol := range pod.Spec.Volumes {
+		status := ""
+		if vol.PersistentVolumeClaim != nil {
+			if pvc, ok := claims[vol.PersistentVolumeClaim.ClaimName]; ok {
+				description, bound := describeClaim(pvc)
+				status = aurora.Green(description).String()
+				if !bound {
+					status = aurora.Red(description).String()
+				}
+			} else {
+				status = aurora.Red("not found").String()
+			}
+		}
+		mounts := getVolumeMounts(pod, vol.Name)
+		if len(mounts) == 0 {
+			mounts = []string{"(not mounted)"}
+		}
+		tw.Append([]string{vol.Name, describeVolumeSource(vol), strings.Join(mounts, ", "), status})
+	}
+	tw.Render()
+	retval += sb.String()
+
+	notes := []string{}
+	for _, name := range missing {
+		notes = append(notes, fmt.Sprintf("%s  PersistentVolumeClaim %s doesn't exist; the pod can't start until it's created", aurora.Red("✖").String(), name))
+	}
+	for _, pvc := range unbound {
+		note := fmt.Sprintf("%s  PersistentVolumeClaim %s is %s", aurora.Red("✖").String(), pvc.Name, pvc.Status.Phase)
+		field := fmt.Sprintf("involvedObject.kind=PersistentVolumeClaim,involvedObject.name=%s", pvc.Name)
+		eventList, err := dp.clientset.CoreV1().Events(pod.Namespace).List(context.Background(), metav1.ListOptions{FieldSelector: field})
+		if denied := describeForbidden(err); denied != "" {
+			note += fmt.Sprintf(" (its events can't be listed: %s)", denied)
+		} else if err != nil {
+			return "", err
+		} else if len(eventList.Items) > 0 {
+			last := eventList.Items[0]
+			for _, event := range eventList.Items {
+				if getEventTimestamp(event).After(getEventTimestamp(last).Time) {
+					last = event
+				}
+			}
+			note += fmt.Sprintf(": %s, %s", last.Reason, last.Message)
+		}
+		notes = append(notes, note)
+	}
+	if len(notes) > 0 {
+		retval += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+
+	return retval, nil
+}
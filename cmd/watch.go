@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// clears the terminal and moves the cursor to the top left
+const clearScreen = "\033[H\033[2J"
+
+// resetCaches forgets what was fetched for the last report, so that the next one shows the
+// cluster as it is now.  Image platforms are kept: they don't change, and looking them up means
+// a round trip to the registry.
+func (dp *podInspectCommand) resetCaches() {
+	dp.nodes = nil
+	dp.nodeList = nil
+	dp.nodeEvents = nil
+	dp.nodePods = nil
+	dp.owners = nil
+	dp.deploymentRevisions = nil
+	dp.workloadPods = nil
+	dp.podEvents = nil
+	dp.podEventIndexes = nil
+	dp.podMetrics = nil
+	dp.podMetricsListed = nil
+}
+
+// runWatch re-runs the inspection every --watch-interval until interrupted, redrawing the
+// screen each time, so that container states, restart counts, and events can be followed live
+// during a rollout.  Polling keeps every section of the report up to date, not just the parts a
+// pod watch would report changes to; the pods and their events, which every poll needs, come
+// from informers rather than being listed again each time.  Errors (such as the pod having been
+// replaced) are shown in place of the report rather than ending the watch.
+func (dp *podInspectCommand) runWatch(args []string) error {
+	command := "kubectl pod-inspect"
+	if len(args) > 0 {
+		command += " " + strings.Join(args, " ")
+	}
+
+	informers, err := dp.startWatchInformers(args)
+	if err != nil {
+		return err
+	}
+	defer close(informers.stop)
+	dp.informers = informers
+
+	for {
+		if isTerminal() {
+			fmt.Fprint(dp.out, clearScreen)
+		} else {
+			fmt.Fprintf(dp.out, "\n")
+		}
+		fmt.Fprintf(dp.out, "%s%s\n\n", aurora.Cyan(fmt.Sprintf("Every %s: %s", dp.watchInterval, command)), aurora.Cyan(fmt.Sprintf("  (%s)", time.Now().Format(time.RFC3339))))
+
+		dp.resetCaches()
+		if err := dp.inspect(args); err != nil {
+			fmt.Fprintf(dp.out, "%s  %v\n", aurora.Red("✖").String(), err)
+		}
+
+		time.Sleep(dp.watchInterval)
+	}
+}
+
+// containerSnapshot is what --changes-only remembers about a container between polls.
+type containerSnapshot struct {
+	State        string
+	Status       int
+	Ready        bool
+	RestartCount int32
+}
+
+// describeContainerState names a container's state and its reason, e.g. "Waiting
+// (CrashLoopBackOff)", along with our classification of it.
+func describeContainerState(cs v1.ContainerStatus) (string, int) {
+	code, reason, _, status := classifyContainerState(cs)
+	state := map[string]string{"R": "Running", "T": "Terminated", "W": "Waiting"}[code]
+	if state == "" {
+		state = "Unknown"
+	}
+	if reason != "" {
+		state = fmt.Sprintf("%s (%s)", state, reason)
+	}
+	return state, status
+}
+
+// colorForStatus picks the color a container state is shown in.
+func colorForStatus(status int) colorFunc {
+	switch status {
+	case PODINSPECT_STATUS_FAILED:
+		return aurora.Red
+	case PODINSPECT_STATUS_OK:
+		return aurora.Green
+	}
+	return aurora.Yellow
+}
+
+// changeWatcher tracks, across polls, the state of each container and the warning events
+// already seen, so that only what's new is printed.
+type changeWatcher struct {
+	pods       map[string]bool
+	containers map[string]containerSnapshot
+	events     map[string]int32
+	first      bool
+}
+
+func newChangeWatcher() *changeWatcher {
+	return &changeWatcher{
+		pods:       map[string]bool{},
+		containers: map[string]containerSnapshot{},
+		events:     map[string]int32{},
+		first:      true,
+	}
+}
+
+// printChange prints one timestamped change line about a pod.
+func (dp *podInspectCommand) printChange(pod *v1.Pod, text string) {
+	fmt.Fprintf(dp.out, "%s  %s/%s  %s\n", time.Now().Format(time.RFC3339), pod.Namespace, pod.Name, text)
+}
+
+// listWatchedPods fetches the named pod or, without a name, every pod that passes the filters.
+func (dp *podInspectCommand) listWatchedPods(args []string) ([]*v1.Pod, error) {
+	if len(args) == 1 {
+		pod, _, err := dp.getPod(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*v1.Pod{pod}, nil
+	}
+
+	pods := []*v1.Pod{}
+	err := dp.forEachPod(func(pod *v1.Pod) error {
+		p := *pod
+		pods = append(pods, &p)
+		return nil
+	})
+	return pods, err
+}
+
+// poll compares the pods against the last poll, printing a line per container that changed
+// state, readiness, or restarted, per new warning event, and per pod that came or went.  The
+// first poll prints each container's state, as a baseline, but none of the events that are
+// already there.
+func (w *changeWatcher) poll(dp *podInspectCommand, pods []*v1.Pod) error {
+	current := map[string]bool{}
+
+	for _, pod := range pods {
+		podKey := fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, pod.UID)
+		current[podKey] = true
+		if !w.first && !w.pods[podKey] {
+			dp.printChange(pod, aurora.Cyan("pod created").String())
+		}
+
+		for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			state, status := describeContainerState(cs)
+			snapshot := containerSnapshot{State: state, Status: status, Ready: cs.Ready, RestartCount: cs.RestartCount}
+
+			key := podKey + "/" + cs.Name
+			previous, seen := w.containers[key]
+			w.containers[key] = snapshot
+
+			switch {
+			case !seen:
+				dp.printChange(pod, fmt.Sprintf("container %s: %s", cs.Name, colorForStatus(status)(state)))
+			case previous.State != state:
+				dp.printChange(pod, fmt.Sprintf("container %s: %s → %s", cs.Name, colorForStatus(previous.Status)(previous.State), colorForStatus(status)(state)))
+			case previous.RestartCount != cs.RestartCount:
+				dp.printChange(pod, fmt.Sprintf("container %s: %s (%d restarts)", cs.Name, aurora.Red("restarted"), cs.RestartCount))
+			}
+			if seen && previous.Ready != cs.Ready {
+				if cs.Ready {
+					dp.printChange(pod, fmt.Sprintf("container %s: %s", cs.Name, aurora.Green("ready")))
+				} else {
+					dp.printChange(pod, fmt.Sprintf("container %s: %s", cs.Name, aurora.Yellow("not ready")))
+				}
+			}
+		}
+
+		events, err := dp.listPodEvents(pod)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if event.Type != v1.EventTypeWarning {
+				continue
+			}
+			// a repeat of an event bumps its count instead of creating a new one
+			key := string(event.UID)
+			count, seen := w.events[key]
+			w.events[key] = event.Count
+			if (seen && count == event.Count) || (!seen && w.first) {
+				continue
+			}
+			line := fmt.Sprintf("%s: %s", event.Reason, event.Message)
+			if event.Count > 1 {
+				line += fmt.Sprintf(" (x%d)", event.Count)
+			}
+			dp.printChange(pod, aurora.Yellow(line).String())
+		}
+	}
+
+	for key := range w.pods {
+		if !current[key] {
+			parts := strings.SplitN(key, "/", 3)
+			fmt.Fprintf(dp.out, "%s  %s/%s  %s\n", time.Now().Format(time.RFC3339), parts[0], parts[1], aurora.Cyan("pod deleted"))
+		}
+	}
+	w.pods = current
+	w.first = false
+
+	return nil
+}
+
+// runWatchChanges is --watch --changes-only: instead of redrawing the report, it prints a
+// timestamped line for each change, which suits a small terminal left open during a rollout.
+// Like runWatch, it reads the pods and their events from informers.
+func (dp *podInspectCommand) runWatchChanges(args []string) error {
+	w := newChangeWatcher()
+
+	informers, err := dp.startWatchInformers(args)
+	if err != nil {
+		return err
+	}
+	defer close(informers.stop)
+	dp.informers = informers
+
+	for {
+		dp.resetCaches()
+		pods, err := dp.listWatchedPods(args)
+		if err == nil {
+			err = w.poll(dp, pods)
+		}
+		if err == nil && dp.historyDB != "" {
+			summary := newScanSummary()
+			for _, pod := range pods {
+				summary.addPod(dp, pod, getPodStatusVerdict(pod))
+			}
+			err = dp.recordScan(summary)
+		}
+		if err != nil {
+			fmt.Fprintf(dp.out, "%s  %s\n", time.Now().Format(time.RFC3339), aurora.Red(err.Error()))
+		}
+
+		time.Sleep(dp.watchInterval)
+	}
+}
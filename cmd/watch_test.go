@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestChangeWatcherPoll(t *testing.T) {
+	newPod := func(name string, state v1.ContainerState, ready bool, restarts int32) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: types.UID(name + "-uid")},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{
+				{Name: "app", State: state, Ready: ready, RestartCount: restarts},
+			}},
+		}
+	}
+	creating := v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	running := v1.ContainerState{Running: &v1.ContainerStateRunning{}}
+	warning := func(count int32) []v1.Event {
+		return []v1.Event{
+			{ObjectMeta: metav1.ObjectMeta{UID: "event-1"}, Type: v1.EventTypeWarning, Reason: "FailedMount", Message: "volume not ready", Count: count},
+			{ObjectMeta: metav1.ObjectMeta{UID: "event-2"}, Type: v1.EventTypeNormal, Reason: "Pulled", Count: 1},
+		}
+	}
+
+	out := &bytes.Buffer{}
+	dp := &podInspectCommand{out: out}
+	w := newChangeWatcher()
+
+	polls := []struct {
+		name   string
+		pods   []*v1.Pod
+		events []v1.Event
+		want   []string
+	}{
+		{
+			name:   "baseline",
+			pods:   []*v1.Pod{newPod("web", creating, false, 0)},
+			events: warning(1),
+			want:   []string{"default/web  container app: Waiting (ContainerCreating)"},
+		},
+		{
+			name:   "started",
+			pods:   []*v1.Pod{newPod("web", running, true, 0)},
+			events: warning(2),
+			want: []string{
+				"default/web  container app: Waiting (ContainerCreating) → Running",
+				"default/web  container app: ready",
+				"default/web  FailedMount: volume not ready (x2)",
+			},
+		},
+		{
+			name:   "unchanged",
+			pods:   []*v1.Pod{newPod("web", running, true, 0)},
+			events: warning(2),
+			want:   []string{},
+		},
+		{
+			name:   "restarted, and a pod created",
+			pods:   []*v1.Pod{newPod("web", running, true, 1), newPod("web-2", running, true, 0)},
+			events: warning(2),
+			want: []string{
+				"default/web  container app: restarted (1 restarts)",
+				"default/web-2  pod created",
+				"default/web-2  container app: Running",
+			},
+		},
+		{
+			name: "deleted",
+			pods: []*v1.Pod{newPod("web-2", running, true, 0)},
+			want: []string{"default/web  pod deleted"},
+		},
+	}
+	for _, poll := range polls {
+		t.Run(poll.name, func(t *testing.T) {
+			dp.podEvents = map[types.UID][]v1.Event{}
+			for _, pod := range poll.pods {
+				dp.podEvents[pod.UID] = poll.events
+			}
+
+			out.Reset()
+			if err := w.poll(dp, poll.pods); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+
+			got := []string{}
+			for _, line := range strings.Split(strings.TrimSuffix(ansiEscapeRegexp.ReplaceAllString(out.String(), ""), "\n"), "\n") {
+				if line == "" {
+					continue
+				}
+				// drop the timestamp
+				got = append(got, strings.SplitN(line, "  ", 2)[1])
+			}
+			if strings.Join(got, "\n") != strings.Join(poll.want, "\n") {
+				t.Errorf("poll() printed\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(poll.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// narrower than this, the tables can't be made to fit in any useful way
+const minWidth = 40
+
+func validateWidth(width int) error {
+	if width != 0 && width < minWidth {
+		return fmt.Errorf("--width must be at least %d", minWidth)
+	}
+	return nil
+}
+
+// visibleWidth counts the columns a line takes up on screen, leaving out color escapes.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiEscapeRegexp.ReplaceAllString(s, ""))
+}
+
+// foldLine breaks a line that's wider than width into several, at a space where there's one
+// in the second half of the line, and indents the continuations a little past the line's own
+// indentation.  Color escapes don't count towards the width, and are never split.
+func foldLine(line string, width int) []string {
+	if width <= 0 || visibleWidth(line) <= width {
+		return []string{line}
+	}
+
+	indent := line[:len(line)-len(strings.TrimLeft(line, " "))] + "  "
+	if len(indent) >= width/2 {
+		indent = "  "
+	}
+
+	lines := []string{}
+	for visibleWidth(line) > width {
+		// find the byte offset of the column at the width, and of the last space before it
+		col, cut, space := 0, len(line), -1
+		for i := 0; i < len(line); {
+			if loc := ansiEscapeRegexp.FindStringIndex(line[i:]); loc != nil && loc[0] == 0 {
+				i += loc[1]
+				continue
+			}
+			if col == width {
+				cut = i
+				break
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			if r == ' ' && col > width/2 {
+				space = i
+			}
+			col++
+			i += size
+		}
+		if space > 0 {
+			cut = space
+		}
+
+		lines = append(lines, strings.TrimRight(line[:cut], " "))
+		line = indent + strings.TrimLeft(line[cut:], " ")
+	}
+	return append(lines, line)
+}
+
+// fitWidth folds each line of the text to fit in the --width; a width of 0 leaves it alone.
+func fitWidth(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	folded := make([]string, 0, len(lines))
+	for _, line := range lines {
+		folded = append(folded, foldLine(line, width)...)
+	}
+	return strings.Join(folded, "\n")
+}
+
+// widthWriter folds what's written through it to fit in a width, a whole line at a time.  All of
+// the report's output goes through it, so that what's printed directly is folded like the
+// sections are.
+type widthWriter struct {
+	out     io.Writer
+	width   int
+	pending []byte
+}
+
+func newWidthWriter(out io.Writer, width int) *widthWriter {
+	return &widthWriter{out: out, width: width}
+}
+
+func (w *widthWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+
+	i := bytes.LastIndexByte(w.pending, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+
+	if _, err := io.WriteString(w.out, fitWidth(string(w.pending[:i+1]), w.width)); err != nil {
+		return 0, err
+	}
+	w.pending = append([]byte{}, w.pending[i+1:]...)
+
+	return len(p), nil
+}
+
+// Flush writes out the last line, if the output didn't end with a newline.
+func (w *widthWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.out, fitWidth(string(w.pending), w.width))
+	w.pending = nil
+	return err
+}
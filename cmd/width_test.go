@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFoldLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  []string
+	}{
+		{"no width", "a line that is long enough to fold", 0, []string{"a line that is long enough to fold"}},
+		{"fits", "short line", 20, []string{"short line"}},
+		{"exactly fits", "0123456789", 10, []string{"0123456789"}},
+		{"folded at a space", "the quick brown fox jumps", 16, []string{"the quick brown", "  fox jumps"}},
+		{"folded mid-word without a late space", "abcdefghijklmnopqrstuvwxyz", 10, []string{"abcdefghij", "  klmnopqr", "  stuvwxyz"}},
+		{"indentation kept", "    the quick brown fox", 16, []string{"    the quick", "      brown fox"}},
+		{"colors don't count", "\x1b[31mred\x1b[0m text", 8, []string{"\x1b[31mred\x1b[0m text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := foldLine(tt.line, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("foldLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+			}
+			if tt.width > 0 {
+				for _, l := range got {
+					if visibleWidth(l) > tt.width {
+						t.Errorf("foldLine(%q, %d): %q is wider than %d", tt.line, tt.width, l, tt.width)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("escapes aren't split", func(t *testing.T) {
+		line := strings.Repeat("\x1b[1mab\x1b[0m ", 10)
+		for _, l := range foldLine(line, 12) {
+			if strings.Count(l, "\x1b[1m") != strings.Count(l, "\x1b[0m") {
+				t.Errorf("foldLine split an escape: %q", l)
+			}
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/spf13/cobra"
+)
+
+// the names kubectl accepts for the workload kinds, mapped to the kind
+var workloadKindAliases = map[string]string{
+	"deployment":             "Deployment",
+	"deployments":            "Deployment",
+	"deploy":                 "Deployment",
+	"statefulset":            "StatefulSet",
+	"statefulsets":           "StatefulSet",
+	"sts":                    "StatefulSet",
+	"daemonset":              "DaemonSet",
+	"daemonsets":             "DaemonSet",
+	"ds":                     "DaemonSet",
+	"replicaset":             "ReplicaSet",
+	"replicasets":            "ReplicaSet",
+	"rs":                     "ReplicaSet",
+	"job":                    "Job",
+	"jobs":                   "Job",
+	"cronjob":                "CronJob",
+	"cronjobs":               "CronJob",
+	"cj":                     "CronJob",
+	"replicationcontroller":  "ReplicationController",
+	"replicationcontrollers": "ReplicationController",
+	"rc":                     "ReplicationController",
+}
+
+func newWorkloadCmd(dp *podInspectCommand) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "workload <kind>/<name>",
+		Short:        "summarize the pods of a workload",
+		Long:         "Summarizes a workload's replica counts, how its pods are spread across nodes and zones, and the status of each pod, then inspects the pods that have a problem.",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected one argument, <kind>/<name>")
+			}
+			return dp.runWorkload(args[0])
+		},
+	}
+
+	// see newVersionCmd
+	oldLine := `{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+	newLine := `
+  kubectl pod-inspect workload <kind>/<name> [flags]{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}`
+
+	cmd.SetUsageTemplate(strings.Replace(cmd.UsageTemplate(), oldLine, newLine, 1))
+
+	return cmd
+}
+
+// parseWorkloadName splits a "deploy/web" style argument into a kind and a name.
+func parseWorkloadName(arg string) (string, string, error) {
+	parts := strings.SplitN(arg, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("expected <kind>/<name>, e.g. deployment/web; got '%s'", arg)
+	}
+
+	kind, ok := workloadKindAliases[strings.ToLower(parts[0])]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported workload kind '%s'", parts[0])
+	}
+
+	return kind, parts[1], nil
+}
+
+// getReplicaCounts reads a workload's desired, ready, and updated pod counts from its status;
+// the fields differ from kind to kind.  Counts a kind doesn't track are returned as "n/a".
+func getReplicaCounts(workload *ownerObject) ([]string, []string, error) {
+	obj := struct {
+		Spec struct {
+			Replicas    *int32 `json:"replicas"`
+			Completions *int32 `json:"completions"`
+		} `json:"spec"`
+		Status struct {
+			ReadyReplicas          int32 `json:"readyReplicas"`
+			UpdatedReplicas        int32 `json:"updatedReplicas"`
+			AvailableReplicas      int32 `json:"availableReplicas"`
+			DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+			NumberReady            int32 `json:"numberReady"`
+			UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+			NumberAvailable        int32 `json:"numberAvailable"`
+			Active                 int32 `json:"active"`
+			Succeeded              int32 `json:"succeeded"`
+			Failed                 int32 `json:"failed"`
+		} `json:"status"`
+	}{}
+	if err := json.Unmarshal(workload.Raw, &obj); err != nil {
+		return nil, nil, err
+	}
+
+	desired := int32(1)
+	if obj.Spec.Replicas != nil {
+		desired = *obj.Spec.Replicas
+	}
+	count := func(n int32) string { return fmt.Sprintf("%d", n) }
+
+	switch workload.Kind {
+	case "Deployment", "StatefulSet":
+		return []string{"Desired", "Ready", "Updated", "Available"},
+			[]string{count(desired), count(obj.Status.ReadyReplicas), count(obj.Status.UpdatedReplicas), count(obj.Status.AvailableReplicas)}, nil
+	case "ReplicaSet", "ReplicationController":
+		return []string{"Desired", "Ready", "Updated", "Available"},
+			[]string{count(desired), count(obj.Status.ReadyReplicas), "n/a", count(obj.Status.AvailableReplicas)}, nil
+	case "DaemonSet":
+		return []string{"Desired", "Ready", "Updated", "Available"},
+			[]string{count(obj.Status.DesiredNumberScheduled), count(obj.Status.NumberReady), count(obj.Status.UpdatedNumberScheduled), count(obj.Status.NumberAvailable)}, nil
+	case "Job":
+		completions := int32(1)
+		if obj.Spec.Completions != nil {
+			completions = *obj.Spec.Completions
+		}
+		return []string{"Completions", "Succeeded", "Active", "Failed"},
+			[]string{count(completions), count(obj.Status.Succeeded), count(obj.Status.Active), count(obj.Status.Failed)}, nil
+	}
+
+	return nil, nil, nil
+}
+
+// getPodReadiness returns how many of the pod's containers are ready, as "ready/total".
+func getPodReadiness(pod *v1.Pod) string {
+	ready := 0
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers))
+}
+
+func getPodRestarts(pod *v1.Pod) int32 {
+	restarts := int32(0)
+	for _, cs := range append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
+
+// runWorkload prints a rollup of a workload: its replica counts, the spread of its pods across
+// nodes and zones, and a line per pod, followed by the full report for each pod with a problem.
+func (dp *podInspectCommand) runWorkload(arg string) error {
+	kind, name, err := parseWorkloadName(arg)
+	if err != nil {
+		return err
+	}
+
+	if err := dp.complete(); err != nil {
+		return err
+	}
+
+	workload, err := dp.getOwner(kind, name)
+	if err != nil {
+		return err
+	}
+	if workload == nil {
+		return fmt.Errorf("%s %s not found in namespace %s", kind, name, dp.namespace)
+	}
+
+	pods, err := dp.listWorkloadPods(workload)
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	fmt.Fprintf(dp.out, "%s%s / %s / %s\n\n", aurora.Cyan("Workload: "), kind, dp.namespace, name)
+
+	if headers, counts, err := getReplicaCounts(workload); err != nil {
+		return err
+	} else if headers != nil {
+		fmt.Fprintf(dp.out, "%s\n\n", aurora.Cyan("Replicas:"))
+		tw := dp.newTablewriter(dp.out)
+		row := []string{}
+		for _, h := range headers {
+			row = append(row, aurora.Yellow(h).String())
+		}
+		tw.Append(row)
+		tw.Append(counts)
+		tw.Render()
+		fmt.Fprintf(dp.out, "\n")
+	}
+
+	if len(pods) == 0 {
+		fmt.Fprintf(dp.out, "%s  the %s has no pods\n", aurora.Yellow("…").String(), kind)
+		return nil
+	}
+
+	// where the pods are, and which of them need a closer look
+	perNode := map[string]int{}
+	perZone := map[string]int{}
+	zones := map[string]string{}
+	problems := []v1.Pod{}
+
+	tw := dp.newTablewriter(dp.out)
+	tw.Append([]string{
+		aurora.Yellow("Pod").String(),
+		aurora.Yellow("Phase").String(),
+		aurora.Yellow("Ready").String(),
+		aurora.Yellow("Restarts").String(),
+		aurora.Yellow("Node").String(),
+		aurora.Yellow("Zone").String(),
+		aurora.Yellow("Age").String(),
+		aurora.Yellow("Status").String(),
+	})
+	for i := range pods {
+		pod := &pods[i]
+
+		zone := ""
+		if node, err := dp.getScheduledNode(pod); err != nil && describeForbidden(err) == "" {
+			return err
+		} else if node != nil {
+			zone, _ = getNodeTopology(node)
+		}
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" {
+			nodeName = "(unscheduled)"
+		}
+		perNode[nodeName]++
+		if zone != "" {
+			perZone[zone]++
+		}
+		zones[nodeName] = zone
+
+		icon := aurora.Green("✔").String()
+		if isProblemPod(pod) {
+			icon = aurora.Red("✖").String()
+			problems = append(problems, *pod)
+		}
+
+		restarts := fmt.Sprintf("%d", getPodRestarts(pod))
+		if restarts != "0" {
+			restarts = aurora.Yellow(restarts).String()
+		}
+
+		tw.Append([]string{
+			pod.Name,
+			string(pod.Status.Phase),
+			getPodReadiness(pod),
+			restarts,
+			nodeName,
+			zone,
+			formatAge(pod.CreationTimestamp),
+			icon,
+		})
+	}
+
+	fmt.Fprintf(dp.out, "%s\n\n", aurora.Cyan(fmt.Sprintf("Pods (%d):", len(pods))))
+	tw.Render()
+
+	nodeNames := make([]string, 0, len(perNode))
+	for n := range perNode {
+		nodeNames = append(nodeNames, n)
+	}
+	sort.Strings(nodeNames)
+
+	fmt.Fprintf(dp.out, "\n%s\n\n", aurora.Cyan("Distribution:"))
+	tw = dp.newTablewriter(dp.out)
+	tw.Append([]string{
+		aurora.Yellow("Node").String(),
+		aurora.Yellow("Zone").String(),
+		aurora.Yellow("Pods").String(),
+	})
+	for _, n := range nodeNames {
+		tw.Append([]string{n, zones[n], fmt.Sprintf("%d", perNode[n])})
+	}
+	tw.Render()
+
+	if len(pods) > 1 {
+		if len(perNode) == 1 && nodeNames[0] != "(unscheduled)" {
+			fmt.Fprintf(dp.out, "\n%s  every pod is on node %s; losing that node takes the whole workload down\n", aurora.Yellow("…").String(), nodeNames[0])
+		} else if len(perZone) == 1 && len(perNode) > 1 {
+			for z := range perZone {
+				fmt.Fprintf(dp.out, "\n%s  every pod is in zone %s; a zonal outage takes the whole workload down\n", aurora.Yellow("…").String(), z)
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintf(dp.out, "\n%s  all %d pods are healthy\n", aurora.Green("✔").String(), len(pods))
+		return nil
+	}
+
+	fmt.Fprintf(dp.out, "\n%s  %d of %d pods have a problem; details follow\n\n", aurora.Red("✖").String(), len(problems), len(pods))
+	// a pod that can't be inspected (e.g. one deleted since it was listed) is reported in place of
+	// its details, and fails the command once the others have been shown
+	failed := 0
+	for _, pod := range problems {
+		if err := dp.displayPod(pod.Name); err != nil {
+			failed++
+			fmt.Fprintf(dp.out, "%s  %s/%s: %v\n\n", aurora.Red("✖").String(), pod.Namespace, pod.Name, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of the %d pods with a problem couldn't be inspected", failed, len(problems))
+	}
+
+	return nil
+}
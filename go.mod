@@ -12,4 +12,6 @@ require (
 	k8s.io/cli-runtime v0.19.2
 	k8s.io/client-go v0.19.2
 	k8s.io/kubectl v0.19.2
+	modernc.org/sqlite v1.14.8
+	sigs.k8s.io/yaml v1.2.0
 )
@@ -0,0 +1,229 @@
+// Package report defines the machine-readable form of a pod-inspect run, as written by
+// `kubectl pod-inspect -o json` and `kubectl pod-inspect -o yaml`.
+//
+// The schema is versioned in the style of a Kubernetes object: every report carries an
+// apiVersion and a kind.  Within a version, fields may be added but are never renamed, removed,
+// or given a different meaning; any such change comes with a new APIVersion, so tooling that
+// checks the version can rely on the shape of the report across plugin upgrades.
+package report
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// APIVersion is the version of the report schema defined by this package.
+	APIVersion = "podinspect.jpriebe.github.com/v1alpha1"
+
+	// Kind is the kind of the report envelope.
+	Kind = "PodInspectReport"
+)
+
+// Report is the envelope for the results of a single run of the plugin.
+type Report struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Pods holds one entry per inspected pod, in the order in which they were inspected.
+	Pods []Pod `json:"pods"`
+
+	// Summary sums up a run over many pods; it's left out when a single pod is inspected.
+	Summary *Summary `json:"summary,omitempty"`
+}
+
+// Summary is the tally of a run over many pods, so that a sweep doubles as a health report.
+type Summary struct {
+	// Inspected is the number of pods inspected, including those that couldn't be.
+	Inspected int `json:"inspected"`
+
+	// Healthy, Degraded, Failed, Pending and Unknown count the inspected pods by verdict.
+	Healthy  int `json:"healthy"`
+	Degraded int `json:"degraded"`
+	Failed   int `json:"failed"`
+	Pending  int `json:"pending"`
+	Unknown  int `json:"unknown"`
+
+	// WarningEvents is the number of Warning events across the inspected pods.
+	WarningEvents int `json:"warningEvents"`
+
+	// APIErrors is the number of pods that couldn't be inspected, with the errors in Errors.
+	APIErrors int      `json:"apiErrors"`
+	Errors    []string `json:"errors,omitempty"`
+
+	// Elapsed is how long the run took.
+	Elapsed metav1.Duration `json:"elapsed"`
+}
+
+// New returns an empty report with the envelope fields filled in.
+func New() *Report {
+	return &Report{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Pods:       []Pod{},
+	}
+}
+
+// Pod is the inspection result for a single pod.
+type Pod struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	UID       string      `json:"uid,omitempty"`
+	Created   metav1.Time `json:"created"`
+	Node      string      `json:"node,omitempty"`
+	Zone      string      `json:"zone,omitempty"`
+	Region    string      `json:"region,omitempty"`
+	Phase     string      `json:"phase"`
+	Reason    string      `json:"reason,omitempty"`
+	Message   string      `json:"message,omitempty"`
+
+	// Static is set for static pods, which the kubelet runs from a manifest on the node; no
+	// controller manages them.
+	Static bool `json:"static,omitempty"`
+
+	// QOSClass is the pod's QoS class: Guaranteed, Burstable or BestEffort, which decides the
+	// order in which pods are evicted and OOM-killed under node pressure.
+	QOSClass string `json:"qosClass,omitempty"`
+
+	// Verdict sums the pod's health up: what a person would conclude from the container table.
+	Verdict Verdict `json:"verdict"`
+
+	// Containers lists the init containers, in execution order, followed by the regular
+	// containers.
+	Containers []Container `json:"containers"`
+
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Events holds the most recent pod events, subject to --max-num-events.
+	Events []Event `json:"events,omitempty"`
+
+	// Logs holds the log tails captured for containers that are not ok, or for every container
+	// with --all-logs.
+	Logs []ContainerLogs `json:"logs,omitempty"`
+
+	// Warnings notes the parts of the report that couldn't be filled in, e.g. because the user
+	// isn't allowed to list events.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Verdict is pod-inspect's overall assessment of a pod, derived from the statuses of its
+// containers.
+type Verdict string
+
+const (
+	// VerdictHealthy means every container is ok and ready, or the pod ran to completion.
+	VerdictHealthy Verdict = "Healthy"
+	// VerdictDegraded means the pod is serving, but some of its containers have failed or
+	// aren't ready.
+	VerdictDegraded Verdict = "Degraded"
+	// VerdictFailed means the pod has failed, or none of its containers are working.
+	VerdictFailed Verdict = "Failed"
+	// VerdictPending means the pod is still being scheduled or started, and nothing has failed.
+	VerdictPending Verdict = "Pending"
+	// VerdictUnknown means the pod's state can't be determined, e.g. its node is unreachable.
+	VerdictUnknown Verdict = "Unknown"
+)
+
+// ContainerType distinguishes init containers from the pod's regular containers.
+type ContainerType string
+
+const (
+	ContainerTypeInit    ContainerType = "init"
+	ContainerTypeRegular ContainerType = "regular"
+)
+
+// Status is pod-inspect's interpretation of a container's state; it's what drives the ready
+// icon in the human-readable output.
+type Status string
+
+const (
+	// StatusOK means the container is running, or has completed successfully.
+	StatusOK Status = "ok"
+	// StatusWaiting means the container is starting up and hasn't (yet) failed.
+	StatusWaiting Status = "waiting"
+	// StatusFailed means the container has failed: it terminated with an error, is crash
+	// looping, or can't pull its image.
+	StatusFailed Status = "failed"
+	// StatusUnknown means the container has no status we can interpret.
+	StatusUnknown Status = "unknown"
+)
+
+// Container is the state of a single container in the pod.
+type Container struct {
+	Type  ContainerType `json:"type"`
+	Name  string        `json:"name"`
+	Image string        `json:"image"`
+
+	// State is the container's current state as reported by Kubernetes: "running", "waiting",
+	// "terminated", or "unknown".
+	State   string `json:"state"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	Status       Status `json:"status"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+
+	// ExitCode is set when the container's current state is terminated.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// SignalName names the signal the container died of, e.g. SIGKILL, when its current state is
+	// terminated by one.
+	SignalName string `json:"signalName,omitempty"`
+
+	// LastTermination describes the previous instance of a container that has been restarted.
+	LastTermination *Termination `json:"lastTermination,omitempty"`
+}
+
+// Termination describes how a container instance ended.
+type Termination struct {
+	Reason     string      `json:"reason,omitempty"`
+	ExitCode   int32       `json:"exitCode"`
+	Signal     int32       `json:"signal,omitempty"`
+	SignalName string      `json:"signalName,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	StartedAt  metav1.Time `json:"startedAt"`
+	FinishedAt metav1.Time `json:"finishedAt"`
+}
+
+// Condition is a pod condition.
+type Condition struct {
+	Type               string      `json:"type"`
+	Status             string      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// Event is an event involving the pod.
+type Event struct {
+	Type     string      `json:"type"`
+	Reason   string      `json:"reason"`
+	Message  string      `json:"message"`
+	Count    int32       `json:"count,omitempty"`
+	LastSeen metav1.Time `json:"lastSeen"`
+
+	// Container names the container the event is about; it's empty for events about the pod
+	// as a whole.
+	Container string `json:"container,omitempty"`
+}
+
+// ContainerLogs is the tail of a container's log.
+type ContainerLogs struct {
+	Container string `json:"container"`
+
+	// Previous is true if the lines come from the container's previous instance, which is the
+	// case for containers that are waiting to be restarted.
+	Previous bool `json:"previous,omitempty"`
+
+	Lines []string `json:"lines"`
+
+	// OmittedLines counts earlier lines that were dropped to keep the log tail bounded.
+	OmittedLines int `json:"omittedLines,omitempty"`
+
+	// TimedOut is true if fetching the logs timed out, in which case Lines may be incomplete.
+	TimedOut bool `json:"timedOut,omitempty"`
+
+	// Error explains why there are no lines, e.g. a permission denied for pods/log, or why they
+	// may be incomplete, e.g. a stream that broke off part way.
+	Error string `json:"error,omitempty"`
+}